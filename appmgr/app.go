@@ -23,6 +23,7 @@ import (
 	"github.com/frame-go/framego/config"
 	"github.com/frame-go/framego/errors"
 	"github.com/frame-go/framego/log"
+	"github.com/frame-go/framego/metrics"
 	"github.com/frame-go/framego/uniqueid"
 )
 
@@ -31,11 +32,13 @@ type appImpl struct {
 
 	ctx         context.Context
 	cancel      context.CancelFunc
+	info        AppInfo
 	cmd         *cobra.Command
 	initOK      *atomic.Bool
 	config      *AppConfig
 	middlewares *middlewareManager
 	jobs        map[string]func(context.Context) error
+	jobTracker  *jobTracker
 	services    map[string]Service
 	observable  ObservableService
 	clients     ClientManager
@@ -43,6 +46,8 @@ type appImpl struct {
 	caches      cache.ClientManager
 	pulsars     pulsarclient.ClientManager
 	idGenerator uniqueid.Generator
+	metrics     *metrics.Registry
+	profiler    *profiler
 }
 
 // Init initializes application by config
@@ -60,6 +65,7 @@ func (a *appImpl) Init() {
 
 	log.Init(viper.GetString("log_level"), viper.GetBool("debug"), viper.GetBool("beautify_log"))
 	errors.SetGRPCDebugMode(viper.GetBool("debug"))
+	watchLogLevels(a.ctx)
 
 	err = config.GetStructWithValidation("app", a.config)
 	if err != nil {
@@ -75,6 +81,17 @@ func (a *appImpl) Init() {
 		a.config.Services = []ServiceConfig{}
 	}
 
+	a.metrics = newMetricsRegistry(a.config.Name)
+
+	if a.config.Profiling.Enabled {
+		a.profiler, err = newProfiler(&a.config.Profiling)
+		if err != nil {
+			log.Logger.Error().Err(err).Msg("init_profiling_error")
+			exitWithError("Init Profiling Error", err)
+			return
+		}
+	}
+
 	a.clients, err = newClientManager(a.ctx, a.middlewares, &a.config.Clients)
 	if err != nil {
 		log.Logger.Error().Err(err).Msg("init_clients_error")
@@ -82,21 +99,30 @@ func (a *appImpl) Init() {
 		return
 	}
 
-	a.databases, err = database.NewClientManager(a.config.Databases, database.WithLogger(log.Logger))
+	databaseOpts := []database.Option{database.WithLogger(log.Component(log.ComponentGorm))}
+	cacheOpts := []cache.Option{cache.WithLogger(log.Component(log.ComponentRedis))}
+	pulsarOpts := []pulsarclient.Option{pulsarclient.WithLogger(log.Component(log.ComponentPulsar))}
+	if a.config.Observable.hasModule("metrics") {
+		databaseOpts = append(databaseOpts, database.WithMetrics(a.metrics))
+		cacheOpts = append(cacheOpts, cache.WithMetrics(a.metrics))
+		pulsarOpts = append(pulsarOpts, pulsarclient.WithMetrics(a.metrics))
+	}
+
+	a.databases, err = database.NewClientManager(a.config.Databases, databaseOpts...)
 	if err != nil {
 		log.Logger.Error().Err(err).Msg("init_databases_error")
 		exitWithError("Init Databases Error", err)
 		return
 	}
 
-	a.caches, err = cache.NewClientManager(a.config.Caches, cache.WithLogger(log.Logger))
+	a.caches, err = cache.NewClientManager(a.config.Caches, cacheOpts...)
 	if err != nil {
 		log.Logger.Error().Err(err).Msg("init_caches_error")
 		exitWithError("Init Caches Error", err)
 		return
 	}
 
-	a.pulsars, err = pulsarclient.NewClientManager(a.config.Pulsars, pulsarclient.WithLogger(log.Logger))
+	a.pulsars, err = pulsarclient.NewClientManager(a.config.Pulsars, pulsarOpts...)
 	if err != nil {
 		log.Logger.Error().Err(err).Msg("init_pulsars_error")
 		exitWithError("Init Pulsars Error", err)
@@ -120,15 +146,32 @@ func (a *appImpl) Init() {
 
 	initGin(a.config.Name)
 	initGrpc()
+	clientHealthChecks, err := a.clientHealthChecks()
+	if err != nil {
+		log.Logger.Error().Err(err).Msg("init_client_health_checks_error")
+		exitWithError("Init Client Health Checks Error", err)
+		return
+	}
 	for _, serviceConfig := range a.config.Services {
-		a.services[serviceConfig.Name], err = newService(a.ctx, a, a.middlewares, &serviceConfig)
+		service, err := newService(a.ctx, a, a.middlewares, &serviceConfig)
 		if err != nil {
 			log.Logger.Error().Err(err).Str("service", serviceConfig.Name).Msg("init_service_error")
 			exitWithError("Init Service Error", err)
 			return
 		}
+		for _, check := range clientHealthChecks {
+			if check.usedBy(serviceConfig.Name) {
+				service.AddHealthCheck(check.name, check.check, check.options...)
+			}
+		}
+		a.services[serviceConfig.Name] = service
+	}
+	a.observable, err = newObservable(a.ctx, a, a.middlewares, &a.config.Observable, a.services)
+	if err != nil {
+		log.Logger.Error().Err(err).Msg("init_observable_error")
+		exitWithError("Init Observable Error", err)
+		return
 	}
-	a.observable = newObservable(a.ctx, a.middlewares, &a.config.Observable, a.services)
 }
 
 func (a *appImpl) RegisterMiddleware(name string, middleware Middleware) {
@@ -183,6 +226,10 @@ func (a *appImpl) GetIDGenerator() uniqueid.Generator {
 	return a.idGenerator
 }
 
+func (a *appImpl) Metrics() *metrics.Registry {
+	return a.metrics
+}
+
 func (a *appImpl) Run() (err error) {
 	// run all jobs
 	chJobs := make(chan error, len(a.config.Jobs))
@@ -195,7 +242,9 @@ func (a *appImpl) Run() (err error) {
 		wgJobs.Add(1)
 		go func(name string, job func(context.Context) error) {
 			defer wgJobs.Done()
+			a.jobTracker.start(name)
 			err := job(a.ctx)
+			a.jobTracker.finish(name, err)
 			if err != nil {
 				log.Logger.Error().Err(err).Str("name", name).Msg("job_exit_with_error")
 				chJobs <- errors.Wrap(err, fmt.Sprintf("Job <%s> Exit With Error", name))
@@ -226,6 +275,11 @@ func (a *appImpl) Run() (err error) {
 	}
 	log.Logger.Info().Msg("observable_started")
 
+	if a.profiler != nil {
+		go a.profiler.Run(a.ctx)
+		log.Logger.Info().Msg("profiling_started")
+	}
+
 	// Wait for interrupt signal to gracefully shut down the server with a timeout
 	quit := make(chan os.Signal, 1)
 
@@ -258,6 +312,7 @@ func (a *appImpl) Run() (err error) {
 		service.Wait()
 	}
 	a.observable.Wait()
+	a.middlewares.close()
 	log.Logger.Warn().Msg("exit_with_all_services_stopped")
 	fmt.Println("[Exit] All Services Stopped.")
 	return
@@ -272,12 +327,14 @@ func (a *appImpl) RunOrExit() {
 
 func NewApp(info *AppInfo) App {
 	app := &appImpl{
-		initOK:      atomic.NewBool(false),
-		config:      &AppConfig{},
-		middlewares: newDefaultMiddlewareManager(),
-		jobs:        make(map[string]func(context.Context) error),
-		services:    make(map[string]Service),
+		info:       *info,
+		initOK:     atomic.NewBool(false),
+		config:     &AppConfig{},
+		jobs:       make(map[string]func(context.Context) error),
+		jobTracker: newJobTracker(),
+		services:   make(map[string]Service),
 	}
+	app.middlewares = newDefaultMiddlewareManager(app)
 	app.ctx, app.cancel = context.WithCancel(context.Background())
 	cmd := &cobra.Command{
 		Use:     info.Name,
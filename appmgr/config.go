@@ -28,9 +28,74 @@ type ServiceSecurityConfig struct {
 	Grpc GrpcSecurityConfig `json:"grpc" mapstructure:"grpc"`
 }
 
+// ObservableAccountConfig is account of basic auth
+type ObservableAccountConfig struct {
+	User     string `json:"user" mapstructure:"user" validate:"required"`
+	Password string `json:"password" mapstructure:"password" validate:"required"`
+}
+
+// ObservableAuthConfig is auth policy of observable endpoint.
+// Requests must come from allowed IPs if set, and pass any configured credential check.
+// Empty policy allows all requests.
+type ObservableAuthConfig struct {
+	// BasicAuth is accounts of HTTP basic auth
+	BasicAuth []ObservableAccountConfig `json:"basic_auth" mapstructure:"basic_auth" validate:"dive"`
+
+	// Tokens are accepted bearer tokens in Authorization header
+	Tokens []string `json:"tokens" mapstructure:"tokens"`
+
+	// ClientCert accepts client certificate verified by CA in security config
+	ClientCert bool `json:"client_cert" mapstructure:"client_cert"`
+
+	// AllowIPs are IPs or CIDRs allowed, by remote address of connection
+	AllowIPs []string `json:"allow_ips" mapstructure:"allow_ips"`
+}
+
+// ObservableSecurityConfig is TLS config of observable endpoint, client certificates are verified by CA if set
+type ObservableSecurityConfig struct {
+	Cert string `json:"cert" mapstructure:"cert"`
+	Key  string `json:"key" mapstructure:"key"`
+	Ca   string `json:"ca" mapstructure:"ca"`
+}
+
 type ObservableConfig struct {
-	Endpoints EndpointsConfig `json:"endpoints" mapstructure:"endpoints" validate:"required"`
-	Modules   []string        `json:"modules" mapstructure:"modules"`
+	Endpoints EndpointsConfig          `json:"endpoints" mapstructure:"endpoints" validate:"required"`
+	Modules   []string                 `json:"modules" mapstructure:"modules"`
+	Security  ObservableSecurityConfig `json:"security" mapstructure:"security"`
+
+	// Auth is default auth policy of all modules
+	Auth ObservableAuthConfig `json:"auth" mapstructure:"auth"`
+
+	// ModuleAuth is auth policies by module name overriding default policy, probes and health report are module "health"
+	ModuleAuth map[string]ObservableAuthConfig `json:"module_auth" mapstructure:"module_auth" validate:"dive"`
+}
+
+// GatewayJSONConfig is protojson options of grpc-gateway, nil options use defaults
+type GatewayJSONConfig struct {
+	// UseProtoNames uses proto field names instead of lowerCamelCase names in output, default is true
+	UseProtoNames *bool `json:"use_proto_names" mapstructure:"use_proto_names"`
+
+	// UseEnumNumbers emits enum values as numbers instead of names in output, default is true
+	UseEnumNumbers *bool `json:"use_enum_numbers" mapstructure:"use_enum_numbers"`
+
+	// EmitUnpopulated emits unpopulated fields with zero values in output, default is true
+	EmitUnpopulated *bool `json:"emit_unpopulated" mapstructure:"emit_unpopulated"`
+
+	// DiscardUnknown ignores unknown fields in input, default is true
+	DiscardUnknown *bool `json:"discard_unknown" mapstructure:"discard_unknown"`
+}
+
+type GatewayConfig struct {
+	JSON GatewayJSONConfig `json:"json" mapstructure:"json"`
+}
+
+// ServiceHealthConfig is config of service health checks
+type ServiceHealthConfig struct {
+	// StartupTimeout is timeout in seconds to wait for startup health checks on service start, default is 60
+	StartupTimeout int64 `json:"startup_timeout" mapstructure:"startup_timeout" validate:"gte=0"`
+
+	// DisableProbes stops serving probes on HTTP endpoint of service, e.g. if service has its own routes of probe paths
+	DisableProbes bool `json:"disable_probes" mapstructure:"disable_probes"`
 }
 
 type ServiceConfig struct {
@@ -38,6 +103,9 @@ type ServiceConfig struct {
 	Endpoints   EndpointsConfig       `json:"endpoints" mapstructure:"endpoints" validate:"required"`
 	Security    ServiceSecurityConfig `json:"security" mapstructure:"security"`
 	Middlewares []interface{}         `json:"middlewares" mapstructure:"middlewares"`
+	ErrorFormat string                `json:"error_format" mapstructure:"error_format" validate:"omitempty,oneof=default problem"`
+	Gateway     GatewayConfig         `json:"gateway" mapstructure:"gateway"`
+	Health      ServiceHealthConfig   `json:"health" mapstructure:"health"`
 }
 
 type GrpcServerConfig struct {
@@ -60,6 +128,49 @@ type IDGeneratorConfig struct {
 	Key       string `json:"key" mapstructure:"key"`
 }
 
+// ProfilingThresholdsConfig is thresholds of process to capture profiles when any is crossed, zero disables the threshold
+type ProfilingThresholdsConfig struct {
+	// CPUPercent is CPU usage of process in percent of all CPUs
+	CPUPercent float64 `json:"cpu_percent" mapstructure:"cpu_percent" validate:"gte=0"`
+
+	// RSS is resident memory of process in MB
+	RSS int64 `json:"rss" mapstructure:"rss" validate:"gte=0"`
+
+	// Goroutines is number of goroutines
+	Goroutines int `json:"goroutines" mapstructure:"goroutines" validate:"gte=0"`
+}
+
+// ProfilingConfig is config of profiles captured continuously on schedule or when thresholds are crossed
+type ProfilingConfig struct {
+	Enabled bool `json:"enabled" mapstructure:"enabled"`
+
+	// Dir is local directory of profile files, default is "profiles"
+	Dir string `json:"dir" mapstructure:"dir"`
+
+	// MaxCaptures is max number of captures kept in dir, files of the oldest captures are removed, default is 25
+	MaxCaptures int `json:"max_captures" mapstructure:"max_captures" validate:"gte=0"`
+
+	// Profiles are types of captured profiles, default is all types
+	Profiles []string `json:"profiles" mapstructure:"profiles" validate:"dive,oneof=cpu heap goroutine mutex"`
+
+	// CPUDuration is duration in seconds of CPU profile, default is 10
+	CPUDuration int64 `json:"cpu_duration" mapstructure:"cpu_duration" validate:"gte=0"`
+
+	// Interval is interval in seconds of scheduled captures, zero disables scheduled captures
+	Interval int64 `json:"interval" mapstructure:"interval" validate:"gte=0"`
+
+	// CheckInterval is interval in seconds to check thresholds, default is 10
+	CheckInterval int64 `json:"check_interval" mapstructure:"check_interval" validate:"gte=0"`
+
+	// Cooldown is min interval in seconds between captures triggered by thresholds, default is 300
+	Cooldown int64 `json:"cooldown" mapstructure:"cooldown" validate:"gte=0"`
+
+	// MutexProfileFraction is rate of mutex contention events reported if mutex profile is captured, default is 10
+	MutexProfileFraction int `json:"mutex_profile_fraction" mapstructure:"mutex_profile_fraction" validate:"gte=0"`
+
+	Thresholds ProfilingThresholdsConfig `json:"thresholds" mapstructure:"thresholds"`
+}
+
 type AppConfig struct {
 	Name        string            `json:"name" mapstructure:"name"`
 	Observable  ObservableConfig  `json:"observable" mapstructure:"observable"`
@@ -70,6 +181,7 @@ type AppConfig struct {
 	Caches      []cache.Config    `json:"caches" mapstructure:"caches"`
 	Pulsars     []pulsar.Config   `json:"pulsar" mapstructure:"pulsars"`
 	IDGenerator IDGeneratorConfig `json:"id_generator" mapstructure:"id_generator"`
+	Profiling   ProfilingConfig   `json:"profiling" mapstructure:"profiling"`
 }
 
 func (c *GrpcSecurityConfig) Resolve() {
@@ -78,6 +190,12 @@ func (c *GrpcSecurityConfig) Resolve() {
 	c.Ca = resolvePathInConfig(c.Ca)
 }
 
+func (c *ObservableSecurityConfig) Resolve() {
+	c.Cert = resolvePathInConfig(c.Cert)
+	c.Key = resolvePathInConfig(c.Key)
+	c.Ca = resolvePathInConfig(c.Ca)
+}
+
 func parseMiddlewareConfig(middlewareConfig interface{}) (name string, options map[string]interface{}, err error) {
 	ok := false
 	name, ok = middlewareConfig.(string)
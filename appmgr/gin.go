@@ -7,6 +7,8 @@ import (
 	"github.com/spf13/viper"
 	ginprometheus "github.com/zsais/go-gin-prometheus"
 
+	"github.com/frame-go/framego/errors"
+	"github.com/frame-go/framego/ginex"
 	"github.com/frame-go/framego/log"
 )
 
@@ -21,14 +23,14 @@ func regularizeGinMsg(p []byte) string {
 type infoLogger struct{}
 
 func (l *infoLogger) Write(p []byte) (n int, err error) {
-	log.Logger.Info().Str("text", regularizeGinMsg(p)).Msg("gin_debug")
+	log.Component(log.ComponentGin).Info().Str("text", regularizeGinMsg(p)).Msg("gin_debug")
 	return len(p), nil
 }
 
 type errorLogger struct{}
 
 func (l *errorLogger) Write(p []byte) (n int, err error) {
-	log.Logger.Error().Str("text", regularizeGinMsg(p)).Msg("gin_error")
+	log.Component(log.ComponentGin).Error().Str("text", regularizeGinMsg(p)).Msg("gin_error")
 	return len(p), nil
 }
 
@@ -55,8 +57,14 @@ func initGin(app string) {
 	prometheus = ginprometheus.NewPrometheus(app)
 }
 
-func newGinEngin(middlewares *middlewareApplier) *gin.Engine {
+// newGinEngin creates gin engine with middlewares. Routes registered by unguarded are not handled by middlewares,
+// since gin only applies middlewares to routes registered after them.
+func newGinEngin(middlewares *middlewareApplier, errorFormat errors.HTTPErrorFormat, unguarded ...func(gin.IRoutes)) *gin.Engine {
 	e := gin.New()
+	e.Use(ginex.ErrorMiddleware(errorFormat))
+	for _, register := range unguarded {
+		register(e)
+	}
 	middlewares.ApplyGin(e)
 	return e
 }
@@ -97,22 +97,50 @@ func newGrpcClient(ctx context.Context, name string, endpoint string, tlsConfig
 	return conn
 }
 
-func newGrpcHttpMux() *runtime.ServeMux {
+// gatewayMIMETypes are content types supported by grpc-gateway
+var gatewayMIMETypes = []string{
+	"application/json",
+	grpcex.MIMEProtobuf,
+	"application/protobuf",
+	grpcex.MIMEMsgpack,
+	"application/x-msgpack",
+}
+
+func newGatewayJSONMarshaler(config *GatewayConfig) runtime.Marshaler {
+	return &runtime.JSONPb{
+		MarshalOptions: protojson.MarshalOptions{
+			UseProtoNames:   boolOrDefault(config.JSON.UseProtoNames, true),
+			UseEnumNumbers:  boolOrDefault(config.JSON.UseEnumNumbers, true),
+			EmitUnpopulated: boolOrDefault(config.JSON.EmitUnpopulated, true),
+		},
+		UnmarshalOptions: protojson.UnmarshalOptions{
+			DiscardUnknown: boolOrDefault(config.JSON.DiscardUnknown, true),
+		},
+	}
+}
+
+func newGrpcHttpMux(jsonMarshaler runtime.Marshaler, errorFormat errors.HTTPErrorFormat) *runtime.ServeMux {
+	protoMarshaler := grpcex.NewProtoMarshaler()
+	msgpackMarshaler := grpcex.NewMsgpackMarshaler(jsonMarshaler)
 	return runtime.NewServeMux(
-		runtime.WithMarshalerOption(runtime.MIMEWildcard, &runtime.JSONPb{
-			MarshalOptions: protojson.MarshalOptions{
-				UseProtoNames:   true,
-				UseEnumNumbers:  true,
-				EmitUnpopulated: true,
-			},
-			UnmarshalOptions: protojson.UnmarshalOptions{
-				DiscardUnknown: true,
-			},
-		}),
+		runtime.WithMarshalerOption(runtime.MIMEWildcard, jsonMarshaler),
+		runtime.WithMarshalerOption("application/json", jsonMarshaler),
+		runtime.WithMarshalerOption(grpcex.MIMEProtobuf, protoMarshaler),
+		runtime.WithMarshalerOption("application/protobuf", protoMarshaler),
+		runtime.WithMarshalerOption(grpcex.MIMEMsgpack, msgpackMarshaler),
+		runtime.WithMarshalerOption("application/x-msgpack", msgpackMarshaler),
 		runtime.WithIncomingHeaderMatcher(grpcex.DefaultHeaderMatcher),
+		runtime.WithErrorHandler(grpcex.GatewayErrorHandler(errorFormat)),
 	)
 }
 
+func boolOrDefault(v *bool, defaultValue bool) bool {
+	if v == nil {
+		return defaultValue
+	}
+	return *v
+}
+
 func newTLSConfig(certFile string, keyFile string, caFile string) (*tls.Config, error) {
 	if certFile == "" && keyFile == "" && caFile == "" {
 		return nil, nil
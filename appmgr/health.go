@@ -0,0 +1,100 @@
+package appmgr
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"github.com/frame-go/framego/errors"
+	"github.com/frame-go/framego/health"
+)
+
+// defaultStartupTimeout is default timeout in seconds to wait for startup health checks
+const defaultStartupTimeout = 60
+
+// clientHealthCheck is built-in health check of configured client
+type clientHealthCheck struct {
+	name     string
+	check    health.CheckFunc
+	options  []health.CheckOption
+	services map[string]struct{}
+}
+
+// usedBy checks whether the client is used by service
+func (c *clientHealthCheck) usedBy(service string) bool {
+	_, ok := c.services[service]
+	return ok
+}
+
+// clientHealthChecks returns enabled health checks of database, cache and pulsar clients,
+// named as "<type>.<client name>", e.g. "database.main"
+func (a *appImpl) clientHealthChecks() ([]clientHealthCheck, error) {
+	var checks []clientHealthCheck
+	var err error
+	add := func(prefix string, name string, check health.CheckFunc, config *health.CheckConfig) {
+		if !config.Enabled || check == nil || err != nil {
+			return
+		}
+		var options []health.CheckOption
+		options, err = config.Options()
+		if err != nil {
+			err = errors.Wrap(err, "parse_client_health_check_config_error").With("client", prefix+"."+name)
+			return
+		}
+		var services map[string]struct{}
+		services, err = a.clientHealthCheckServices(config.Services)
+		if err != nil {
+			err = errors.Wrap(err, "parse_client_health_check_config_error").With("client", prefix+"."+name)
+			return
+		}
+		checks = append(checks, clientHealthCheck{
+			name:     prefix + "." + name,
+			check:    check,
+			options:  options,
+			services: services,
+		})
+	}
+	for i := range a.config.Databases {
+		config := &a.config.Databases[i]
+		add("database", config.Name, a.databases.HealthCheck(config.Name), &config.HealthCheck)
+	}
+	for i := range a.config.Caches {
+		config := &a.config.Caches[i]
+		add("cache", config.Name, a.caches.HealthCheck(config.Name), &config.HealthCheck)
+	}
+	for i := range a.config.Pulsars {
+		config := &a.config.Pulsars[i]
+		add("pulsar", config.Name, a.pulsars.HealthCheck(config.Name), &config.HealthCheck)
+	}
+	return checks, err
+}
+
+// clientHealthCheckServices returns services using client by names in config, all services if names are empty
+func (a *appImpl) clientHealthCheckServices(names []string) (map[string]struct{}, error) {
+	services := make(map[string]struct{}, len(names))
+	if len(names) == 0 {
+		for _, serviceConfig := range a.config.Services {
+			services[serviceConfig.Name] = struct{}{}
+		}
+		return services, nil
+	}
+	for _, name := range names {
+		found := false
+		for _, serviceConfig := range a.config.Services {
+			if serviceConfig.Name == name {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, errors.New("unknown_service").With("service", name)
+		}
+		services[name] = struct{}{}
+	}
+	return services, nil
+}
+
+// registerProbeHandlers serves liveness, readiness and startup probes of reporters on router
+func registerProbeHandlers(router gin.IRoutes, reporters ...health.Reporter) {
+	router.GET("/livez", gin.WrapH(health.NewProbeHandler(health.ProbeLiveness, reporters...)))
+	router.GET("/readyz", gin.WrapH(health.NewProbeHandler(health.ProbeReadiness, reporters...)))
+	router.GET("/startupz", gin.WrapH(health.NewProbeHandler(health.ProbeStartup, reporters...)))
+}
@@ -0,0 +1,77 @@
+package appmgr
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestClientHealthCheckServices(t *testing.T) {
+	a := &appImpl{config: &AppConfig{Services: []ServiceConfig{{Name: "api"}}}}
+	services, err := a.clientHealthCheckServices(nil)
+	if err != nil || !reflect.DeepEqual(services, map[string]struct{}{"api": {}}) {
+		t.Errorf("services of single service app: %v, %v", services, err)
+	}
+
+	a.config.Services = append(a.config.Services, ServiceConfig{Name: "admin"})
+	services, err = a.clientHealthCheckServices(nil)
+	if err != nil || !reflect.DeepEqual(services, map[string]struct{}{"api": {}, "admin": {}}) {
+		t.Errorf("services of multiple services app: %v, %v", services, err)
+	}
+	if _, err = a.clientHealthCheckServices([]string{"api", "unknown"}); err == nil {
+		t.Errorf("expect unknown service error")
+	}
+	services, err = a.clientHealthCheckServices([]string{"admin"})
+	if err != nil || !reflect.DeepEqual(services, map[string]struct{}{"admin": {}}) {
+		t.Errorf("services of client: %v, %v", services, err)
+	}
+	check := &clientHealthCheck{services: services}
+	if check.usedBy("api") || !check.usedBy("admin") {
+		t.Errorf("unexpected services using client: %v", services)
+	}
+}
+
+func TestServiceProbesWithoutMiddlewares(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mm := newDefaultMiddlewareManager(nil)
+	config := &ServiceConfig{
+		Name:      "api",
+		Endpoints: EndpointsConfig{Http: "127.0.0.1:0"},
+		Middlewares: []interface{}{
+			map[string]interface{}{"name": "jwt_auth", "hs256_key": "secret"},
+		},
+	}
+	service, err := newService(context.Background(), nil, mm, config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	router := service.(*serviceImpl).ginEngine
+	service.GetGinRouter().GET("/v1/users", func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+	for path, code := range map[string]int{"/readyz": http.StatusOK, "/livez": http.StatusOK, "/v1/users": http.StatusUnauthorized} {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		if w.Code != code {
+			t.Errorf("status of %s %v != %v", path, w.Code, code)
+		}
+	}
+
+	config.Health.DisableProbes = true
+	service, err = newService(context.Background(), nil, mm, config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	service.GetGinRouter().GET("/readyz", func(c *gin.Context) {
+		c.Status(http.StatusNoContent)
+	})
+	w := httptest.NewRecorder()
+	service.(*serviceImpl).ginEngine.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("status of own probe route %v != 401", w.Code)
+	}
+}
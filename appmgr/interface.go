@@ -2,6 +2,7 @@ package appmgr
 
 import (
 	"context"
+	"io/fs"
 
 	"github.com/apache/pulsar-client-go/pulsar"
 	"github.com/gin-gonic/gin"
@@ -12,6 +13,7 @@ import (
 
 	"github.com/frame-go/framego/client/cache"
 	"github.com/frame-go/framego/health"
+	"github.com/frame-go/framego/metrics"
 	"github.com/frame-go/framego/uniqueid"
 )
 
@@ -65,6 +67,11 @@ type App interface {
 
 	// GetIDGenerator gets uniqueid Generator
 	GetIDGenerator() uniqueid.Generator
+
+	// Metrics gets registry of application metrics, which are served by "metrics" observable module.
+	// Metrics are namespaced by app name, with constant labels "app" (app name) and "hostname".
+	// It is available after App.Init
+	Metrics() *metrics.Registry
 }
 
 type Service interface {
@@ -77,7 +84,12 @@ type Service interface {
 	GetGrpcChannelClient() grpc.ClientConnInterface
 	GetServeMux() *runtime.ServeMux
 	GetGinRouter() gin.IRoutes
-	AddHealthCheck(name string, check health.CheckFunc)
+	AddHealthCheck(name string, check health.CheckFunc, options ...health.CheckOption)
+	GetHealthReporter() health.Reporter
+
+	// RegisterOpenAPI registers OpenAPI documents in JSON matched by patterns in fsys, e.g. embedded files generated
+	// by protoc-gen-openapiv2, all JSON files if no pattern. Documents are merged and served by "swagger" module.
+	RegisterOpenAPI(fsys fs.FS, patterns ...string) error
 	Run() error
 	Wait()
 }
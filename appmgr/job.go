@@ -0,0 +1,77 @@
+package appmgr
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	jobStateIdle      = "idle"
+	jobStateRunning   = "running"
+	jobStateCompleted = "completed"
+	jobStateFailed    = "failed"
+)
+
+// jobStatus is running status of job
+type jobStatus struct {
+	Name      string     `json:"name"`
+	State     string     `json:"state"`
+	StartTime *time.Time `json:"start_time,omitempty"`
+	EndTime   *time.Time `json:"end_time,omitempty"`
+	Error     string     `json:"error,omitempty"`
+}
+
+// jobTracker records running status of jobs
+type jobTracker struct {
+	lock     sync.RWMutex
+	statuses map[string]*jobStatus
+}
+
+func newJobTracker() *jobTracker {
+	return &jobTracker{
+		statuses: make(map[string]*jobStatus),
+	}
+}
+
+func (t *jobTracker) start(name string) {
+	now := time.Now()
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	t.statuses[name] = &jobStatus{Name: name, State: jobStateRunning, StartTime: &now}
+}
+
+func (t *jobTracker) finish(name string, err error) {
+	now := time.Now()
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	status, ok := t.statuses[name]
+	if !ok {
+		status = &jobStatus{Name: name}
+		t.statuses[name] = status
+	}
+	status.EndTime = &now
+	if err != nil {
+		status.State = jobStateFailed
+		status.Error = err.Error()
+	} else {
+		status.State = jobStateCompleted
+	}
+}
+
+// list returns statuses of registered jobs sorted by name, jobs never started are idle
+func (t *jobTracker) list(names []string) []jobStatus {
+	t.lock.RLock()
+	defer t.lock.RUnlock()
+	statuses := make([]jobStatus, 0, len(names))
+	for _, name := range names {
+		status, ok := t.statuses[name]
+		if ok {
+			statuses = append(statuses, *status)
+		} else {
+			statuses = append(statuses, jobStatus{Name: name, State: jobStateIdle})
+		}
+	}
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].Name < statuses[j].Name })
+	return statuses
+}
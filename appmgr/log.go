@@ -0,0 +1,76 @@
+package appmgr
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/spf13/viper"
+
+	"github.com/frame-go/framego/config"
+	"github.com/frame-go/framego/errors"
+	"github.com/frame-go/framego/log"
+)
+
+// logger is logger of appmgr component, whose level can be changed at runtime.
+// It is used by logs of background work while serving, e.g. profiling and log level watching,
+// while startup and lifecycle logs of app use log.Logger.
+var logger = log.Component(log.ComponentAppmgr)
+
+// logLevelsConfigKey is config key of runtime log levels, watched for changes in Apollo
+const logLevelsConfigKey = "log_levels"
+
+// logLevelsConfig is log levels changed at runtime, with optional ttl in seconds to revert the change
+type logLevelsConfig struct {
+	log.Levels `mapstructure:",squash"`
+	TTL        int64 `json:"ttl" mapstructure:"ttl"`
+}
+
+// watchLogLevels applies log levels in config, and applies again on config changes.
+// Global level falls back to level set by log.Init, and components not in config follow global level.
+func watchLogLevels(ctx context.Context) {
+	initial := log.GetLevels()
+	apply := func() {
+		levelsConfig := &logLevelsConfig{}
+		err := config.GetStruct(logLevelsConfigKey, levelsConfig)
+		if err != nil {
+			logger.Error().Err(err).Msg("parse_log_levels_config_error")
+			return
+		}
+		levels := log.Levels{Global: levelsConfig.Global, Components: make(map[string]string)}
+		if levels.Global == "" {
+			levels.Global = initial.Global
+		}
+		for _, name := range log.GetComponents() {
+			levels.Components[name] = levelsConfig.Components[name]
+		}
+		err = log.SetLevels(levels, time.Duration(levelsConfig.TTL)*time.Second, "config")
+		if err != nil {
+			errors.LogError(logger.Error(), err).Msg("set_log_levels_error")
+		}
+	}
+	if viper.IsSet(logLevelsConfigKey) {
+		apply()
+	}
+	config.Watch(ctx, logLevelsConfigKey, apply)
+}
+
+// loglevelModule shows log levels, and changes log levels by PUT request of logLevelsConfig in JSON
+func loglevelModule(o *observableImpl, router *gin.RouterGroup) {
+	router.GET("/loglevel", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"levels": log.GetLevels(), "components": log.GetComponents()})
+	})
+	router.PUT("/loglevel", func(c *gin.Context) {
+		levelsConfig := &logLevelsConfig{}
+		err := c.ShouldBindJSON(levelsConfig)
+		if err == nil {
+			err = log.SetLevels(levelsConfig.Levels, time.Duration(levelsConfig.TTL)*time.Second, "observable")
+		}
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"levels": log.GetLevels(), "components": log.GetComponents()})
+	})
+}
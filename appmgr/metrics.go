@@ -0,0 +1,25 @@
+package appmgr
+
+import (
+	"os"
+
+	prom "github.com/prometheus/client_golang/prometheus"
+
+	"github.com/frame-go/framego/metrics"
+)
+
+// newMetricsRegistry creates registry of application metrics in default prometheus registry,
+// so that they are served with built-in gRPC and gin metrics by "metrics" observable module.
+// Constant labels avoid "instance" and "job", which are set by prometheus as target labels on scraping.
+func newMetricsRegistry(app string) *metrics.Registry {
+	hostname, _ := os.Hostname()
+	return metrics.NewRegistry(&metrics.Options{
+		Namespace: app,
+		ConstLabels: prom.Labels{
+			"app":      app,
+			"hostname": hostname,
+		},
+		Registerer: prom.DefaultRegisterer,
+		Gatherer:   prom.DefaultGatherer,
+	})
+}
@@ -2,8 +2,17 @@ package appmgr
 
 import (
 	"context"
+	"crypto/tls"
+	"fmt"
+	"os"
+	"path"
+	"runtime/pprof"
 	"strings"
+	"sync"
+	"time"
 
+	"github.com/casbin/casbin/v2/persist"
+	fileadapter "github.com/casbin/casbin/v2/persist/file-adapter"
 	"github.com/gin-gonic/gin"
 	grpc_middleware "github.com/grpc-ecosystem/go-grpc-middleware"
 	grpc_recovery "github.com/grpc-ecosystem/go-grpc-middleware/recovery"
@@ -11,16 +20,28 @@ import (
 	grpc_prometheus "github.com/grpc-ecosystem/go-grpc-prometheus"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
 
+	"github.com/frame-go/framego/audit"
+	"github.com/frame-go/framego/auth"
+	"github.com/frame-go/framego/client/cache"
 	"github.com/frame-go/framego/config"
 	"github.com/frame-go/framego/errors"
 	"github.com/frame-go/framego/ginex"
 	"github.com/frame-go/framego/grpcex"
 	"github.com/frame-go/framego/log"
+	"github.com/frame-go/framego/payloadlog"
+	"github.com/frame-go/framego/respcache"
 )
 
+// middlewareInstanceKey is reserved key in options of configured middleware, identifying the middleware config
+// by service name and index, so that gin handler and grpc interceptor of the config share one instance
+const middlewareInstanceKey = "_instance"
+
 type middlewareManager struct {
 	middlewares map[string]Middleware
+	applyCount  int
 }
 
 func newMiddlewareManager() *middlewareManager {
@@ -29,7 +50,7 @@ func newMiddlewareManager() *middlewareManager {
 	}
 }
 
-func newDefaultMiddlewareManager() *middlewareManager {
+func newDefaultMiddlewareManager(app App) *middlewareManager {
 	m := newMiddlewareManager()
 	m.RegisterMiddleware("context_logger", NewContextLoggerMiddleware())
 	m.RegisterMiddleware("log_request", NewLogRequestMiddleware())
@@ -39,7 +60,12 @@ func newDefaultMiddlewareManager() *middlewareManager {
 	m.RegisterMiddleware("request_validation", NewRequestValidationMiddleware())
 	m.RegisterMiddleware("cors", NewCorsMiddleware())
 	m.RegisterMiddleware("compress", NewCompressMiddleware())
-	m.RegisterMiddleware("access_control", NewAccessControlMiddleware())
+	m.RegisterMiddleware("access_control", NewAccessControlMiddleware(app))
+	m.RegisterMiddleware("response_cache", NewResponseCacheMiddleware(app))
+	m.RegisterMiddleware("jwt_auth", NewJwtAuthMiddleware())
+	m.RegisterMiddleware("api_key", NewApiKeyMiddleware(app))
+	m.RegisterMiddleware("audit", NewAuditMiddleware(app))
+	m.RegisterMiddleware("profile_labels", NewProfileLabelsMiddleware())
 	return m
 }
 
@@ -51,8 +77,27 @@ func (m *middlewareManager) GetMiddleware(name string) Middleware {
 	return m.middlewares[strings.ToLower(name)]
 }
 
+// middlewareCloser is implemented by middlewares holding resources to release on app shutdown
+type middlewareCloser interface {
+	close()
+}
+
+// close releases resources of middlewares, e.g. stops reloading policies of access controllers
+func (m *middlewareManager) close() {
+	for _, middleware := range m.middlewares {
+		if closer, ok := middleware.(middlewareCloser); ok {
+			closer.close()
+		}
+	}
+}
+
 func (m *middlewareManager) Apply(service Service, configs []interface{}) *middlewareApplier {
-	ma := newMiddlewareApplier()
+	serviceName := ""
+	if service != nil {
+		serviceName = service.GetName()
+	}
+	m.applyCount++
+	ma := newMiddlewareApplier(fmt.Sprintf("%s#%d", serviceName, m.applyCount))
 	ma.AddMiddleware("", NewContextTagsMiddleware(), nil)
 	if service != nil {
 		ma.AddMiddleware("", NewServiceContextMiddleware(service), nil)
@@ -60,10 +105,14 @@ func (m *middlewareManager) Apply(service Service, configs []interface{}) *middl
 	for i, middlewareConfig := range configs {
 		err := ma.AddMiddlewareByConfig(m, middlewareConfig)
 		if err != nil {
-			errors.LogError(log.Logger.Error(), err).Str("service", service.GetName()).
+			errors.LogError(log.Logger.Error(), err).Str("service", serviceName).
 				Int("index", i).Msg("create_middleware_error")
 		}
 	}
+	err := ma.checkOrder()
+	if err != nil {
+		errors.LogError(log.Logger.Fatal(), err).Str("service", serviceName).Msg("invalid_middleware_order")
+	}
 	return ma
 }
 
@@ -71,15 +120,108 @@ type middlewareConstructor struct {
 	name    string
 	m       Middleware
 	options map[string]interface{}
+	scope   *middlewareScope
+}
+
+// middlewareScope limits middleware to gRPC full methods or HTTP paths by "include" and "exclude" glob patterns
+type middlewareScope struct {
+	Include []string `json:"include"`
+	Exclude []string `json:"exclude"`
+}
+
+// newMiddlewareScope parses scope in middleware options, returns nil if middleware applies to all methods
+func newMiddlewareScope(options map[string]interface{}) (*middlewareScope, error) {
+	scope := &middlewareScope{}
+	err := config.StringMap(options).ToStruct(scope)
+	if err != nil {
+		return nil, errors.Wrap(err, "parse_middleware_scope_error")
+	}
+	if len(scope.Include) == 0 && len(scope.Exclude) == 0 {
+		return nil, nil
+	}
+	for _, pattern := range append(scope.Include, scope.Exclude...) {
+		if _, err = path.Match(pattern, ""); err != nil {
+			return nil, errors.Wrap(err, "invalid_middleware_scope_pattern").With("pattern", pattern)
+		}
+	}
+	return scope, nil
+}
+
+// Contains checks whether middleware applies to the gRPC full method or HTTP path
+func (s *middlewareScope) Contains(method string) bool {
+	if s == nil {
+		return true
+	}
+	for _, pattern := range s.Exclude {
+		if ok, _ := path.Match(pattern, method); ok {
+			return false
+		}
+	}
+	if len(s.Include) == 0 {
+		return true
+	}
+	for _, pattern := range s.Include {
+		if ok, _ := path.Match(pattern, method); ok {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *middlewareScope) GinHandler(handler gin.HandlerFunc) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if s.Contains(c.Request.URL.Path) {
+			handler(c)
+		}
+	}
+}
+
+func (s *middlewareScope) UnaryServerInterceptor(interceptor grpc.UnaryServerInterceptor) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if s.Contains(info.FullMethod) {
+			return interceptor(ctx, req, info, handler)
+		}
+		return handler(ctx, req)
+	}
+}
+
+func (s *middlewareScope) StreamServerInterceptor(interceptor grpc.StreamServerInterceptor) grpc.StreamServerInterceptor {
+	return func(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if s.Contains(info.FullMethod) {
+			return interceptor(srv, stream, info, handler)
+		}
+		return handler(srv, stream)
+	}
+}
+
+func (s *middlewareScope) UnaryClientInterceptor(interceptor grpc.UnaryClientInterceptor) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		if s.Contains(method) {
+			return interceptor(ctx, method, req, reply, cc, invoker, opts...)
+		}
+		return invoker(ctx, method, req, reply, cc, opts...)
+	}
+}
+
+func (s *middlewareScope) StreamClientInterceptor(interceptor grpc.StreamClientInterceptor) grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		if s.Contains(method) {
+			return interceptor(ctx, desc, cc, method, streamer, opts...)
+		}
+		return streamer(ctx, desc, cc, method, opts...)
+	}
 }
 
 type middlewareApplier struct {
-	mcs []*middlewareConstructor
+	// owner identifies service and applying of middlewares, for instance keys of middleware configs
+	owner string
+	mcs   []*middlewareConstructor
 }
 
-func newMiddlewareApplier() *middlewareApplier {
+func newMiddlewareApplier(owner string) *middlewareApplier {
 	a := &middlewareApplier{
-		mcs: make([]*middlewareConstructor, 0),
+		owner: owner,
+		mcs:   make([]*middlewareConstructor, 0),
 	}
 	return a
 }
@@ -98,7 +240,40 @@ func (a *middlewareApplier) AddMiddlewareByConfig(mm *middlewareManager, config
 	if middleware == nil {
 		return errors.New("unknown_middleware_name").With("middleware", name)
 	}
-	a.AddMiddleware(strings.ToLower(name), middleware, options)
+	scope, err := newMiddlewareScope(options)
+	if err != nil {
+		return errors.Wrap(err, "create_middleware_scope_error").With("middleware", name)
+	}
+	instanceOptions := make(map[string]interface{}, len(options)+1)
+	for key, value := range options {
+		instanceOptions[key] = value
+	}
+	instanceOptions[middlewareInstanceKey] = fmt.Sprintf("%s/%d", a.owner, len(a.mcs))
+	a.AddMiddleware(strings.ToLower(name), middleware, instanceOptions)
+	a.mcs[len(a.mcs)-1].scope = scope
+	return nil
+}
+
+// authMiddlewareNames are names of middlewares authenticating or authorizing requests
+var authMiddlewareNames = map[string]struct{}{
+	"jwt_auth":       {},
+	"api_key":        {},
+	"access_control": {},
+}
+
+// checkOrder rejects response cache placed before auth middlewares, which would serve cached responses to requests
+// before they are authenticated or authorized
+func (a *middlewareApplier) checkOrder() error {
+	cacheIndex := -1
+	for i, mc := range a.mcs {
+		if mc.name == "response_cache" && cacheIndex < 0 {
+			cacheIndex = i
+			continue
+		}
+		if _, ok := authMiddlewareNames[mc.name]; ok && cacheIndex >= 0 {
+			return errors.New("response_cache_before_auth_middleware").With("middleware", mc.name)
+		}
+	}
 	return nil
 }
 
@@ -115,11 +290,25 @@ func (a *middlewareApplier) IsMiddlewareEnabled(name string) bool {
 	return false
 }
 
+// Chain returns names of configured middlewares applied to the gRPC full method or HTTP path in order
+func (a *middlewareApplier) Chain(method string) []string {
+	names := make([]string, 0, len(a.mcs))
+	for _, mc := range a.mcs {
+		if mc.name != "" && mc.scope.Contains(method) {
+			names = append(names, mc.name)
+		}
+	}
+	return names
+}
+
 func (a *middlewareApplier) ApplyGin(routes gin.IRoutes) {
 	for _, mc := range a.mcs {
 		if mc.m != nil {
 			handler := mc.m.GinHandler(mc.options)
 			if handler != nil {
+				if mc.scope != nil {
+					handler = mc.scope.GinHandler(handler)
+				}
 				routes.Use(handler)
 			}
 		}
@@ -132,6 +321,14 @@ func (a *middlewareApplier) GrpcServerInterceptor() (grpc.UnaryServerInterceptor
 	for _, mc := range a.mcs {
 		if mc.m != nil {
 			unaryInterceptor, streamInterceptor := mc.m.GrpcServerInterceptor(mc.options)
+			if mc.scope != nil {
+				if unaryInterceptor != nil {
+					unaryInterceptor = mc.scope.UnaryServerInterceptor(unaryInterceptor)
+				}
+				if streamInterceptor != nil {
+					streamInterceptor = mc.scope.StreamServerInterceptor(streamInterceptor)
+				}
+			}
 			if unaryInterceptor != nil {
 				unaryServerInterceptors = append(unaryServerInterceptors, unaryInterceptor)
 			}
@@ -151,6 +348,14 @@ func (a *middlewareApplier) GrpcClientInterceptor() (grpc.UnaryClientInterceptor
 	for _, mc := range a.mcs {
 		if mc.m != nil {
 			unaryInterceptor, streamInterceptor := mc.m.GrpcClientInterceptor(mc.options)
+			if mc.scope != nil {
+				if unaryInterceptor != nil {
+					unaryInterceptor = mc.scope.UnaryClientInterceptor(unaryInterceptor)
+				}
+				if streamInterceptor != nil {
+					streamInterceptor = mc.scope.StreamClientInterceptor(streamInterceptor)
+				}
+			}
 			if unaryInterceptor != nil {
 				unaryClientInterceptors = append(unaryClientInterceptors, unaryInterceptor)
 			}
@@ -164,6 +369,42 @@ func (a *middlewareApplier) GrpcClientInterceptor() (grpc.UnaryClientInterceptor
 	return chainUnaryClientInterceptor, chainStreamClientInterceptor
 }
 
+// middlewareInstances holds instances created by middleware options, so that gin handler and grpc interceptor
+// of the same middleware config share one instance. Instances are keyed by middlewareInstanceKey in options,
+// and options without the key always get a new instance.
+type middlewareInstances[T any] struct {
+	mu        sync.Mutex
+	instances map[string]T
+}
+
+func (m *middlewareInstances[T]) get(options map[string]interface{}, newInstance func() T) T {
+	id, _ := options[middlewareInstanceKey].(string)
+	if id == "" {
+		return newInstance()
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	instance, ok := m.instances[id]
+	if ok {
+		return instance
+	}
+	if m.instances == nil {
+		m.instances = make(map[string]T)
+	}
+	instance = newInstance()
+	m.instances[id] = instance
+	return instance
+}
+
+// each calls f with every instance
+func (m *middlewareInstances[T]) each(f func(T)) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, instance := range m.instances {
+		f(instance)
+	}
+}
+
 type contextTagsMiddleware struct {
 	Middleware
 }
@@ -253,11 +494,34 @@ func NewLogRequestMiddleware() Middleware {
 	return &logRequestMiddleware{}
 }
 
+// newPayloadLogger creates payload logger by options, returns nil if payload logging is not enabled
+func (m *logRequestMiddleware) newPayloadLogger(options map[string]interface{}) *payloadlog.Logger {
+	payloadConfig := &payloadlog.Config{}
+	err := config.StringMap(options).ToStruct(payloadConfig)
+	if err != nil {
+		log.Logger.Fatal().Err(err).Interface("options", options).Msg("parse_log_request_config_error")
+	}
+	if len(payloadConfig.PayloadMethods) == 0 {
+		return nil
+	}
+	payloadLogger, err := payloadlog.NewLogger(payloadConfig)
+	if err != nil {
+		errors.LogError(log.Logger.Fatal(), err).Msg("log_request_middleware_init_failed")
+	}
+	return payloadLogger
+}
+
 func (m *logRequestMiddleware) GinHandler(options map[string]interface{}) gin.HandlerFunc {
+	if payloadLogger := m.newPayloadLogger(options); payloadLogger != nil {
+		return ginex.LogRequestMiddleware(ginex.WithPayloadLogger(payloadLogger))
+	}
 	return ginex.LogRequestMiddleware()
 }
 
 func (m *logRequestMiddleware) GrpcServerInterceptor(options map[string]interface{}) (grpc.UnaryServerInterceptor, grpc.StreamServerInterceptor) {
+	if payloadLogger := m.newPayloadLogger(options); payloadLogger != nil {
+		return grpcex.LogRequestUnaryServerInterceptor(grpcex.WithPayloadLogger(payloadLogger)), grpcex.LogRequestStreamServerInterceptor()
+	}
 	return grpcex.LogRequestUnaryServerInterceptor(), grpcex.LogRequestStreamServerInterceptor()
 }
 
@@ -355,7 +619,9 @@ func NewRequestValidationMiddleware() Middleware {
 }
 
 func (m *requestValidationMiddleware) GinHandler(options map[string]interface{}) gin.HandlerFunc {
-	return nil
+	// gin handlers are created while app is initializing services, before any engine serves requests
+	ginex.InstallValidator()
+	return ginex.RequestValidationMiddleware()
 }
 
 func (m *requestValidationMiddleware) GrpcServerInterceptor(options map[string]interface{}) (grpc.UnaryServerInterceptor, grpc.StreamServerInterceptor) {
@@ -408,35 +674,437 @@ func (m *compressMiddleware) GrpcClientInterceptor(options map[string]interface{
 
 type accessControlMiddleware struct {
 	Middleware
+	app         App
+	controllers middlewareInstances[*grpcex.AccessController]
+}
+
+// accessControlConfig is options of access_control middleware
+type accessControlConfig struct {
+	// Model is casbin model file, use the default model if empty
+	Model string `json:"model"`
+
+	// Policy is casbin policy file
+	Policy string `json:"policy"`
+
+	// Watch reloads policy file when it is changed
+	Watch bool `json:"watch"`
+
+	// Database is name of gorm database to load policies from, instead of policy file
+	Database string `json:"database"`
+
+	// Table is policy table in database, default is grpcex.DefaultPolicyTable
+	Table string `json:"table"`
+
+	// ReloadInterval is interval to reload policies in seconds, not reloaded periodically if 0
+	ReloadInterval int64 `json:"reload_interval"`
+
+	// AuthorizeRoutes authorizes gin routes by "p2" policies, which requires "r2" definitions in model
+	AuthorizeRoutes bool `json:"authorize_routes"`
+}
+
+func NewAccessControlMiddleware(app App) Middleware {
+	return &accessControlMiddleware{app: app}
 }
 
-func NewAccessControlMiddleware() Middleware {
-	return &accessControlMiddleware{}
+func (m *accessControlMiddleware) parseConfig(options map[string]interface{}) *accessControlConfig {
+	acConfig := &accessControlConfig{}
+	err := config.StringMap(options).ToStruct(acConfig)
+	if err != nil {
+		log.Logger.Fatal().Err(err).Interface("options", options).Msg("parse_access_control_config_error")
+	}
+	return acConfig
 }
 
+// getController gets access controller by options, gin handler and grpc interceptor of the same
+// middleware config share one controller
+func (m *accessControlMiddleware) getController(options map[string]interface{}) *grpcex.AccessController {
+	return m.controllers.get(options, func() *grpcex.AccessController {
+		acConfig := m.parseConfig(options)
+		model := acConfig.Model
+		if model != "" {
+			model = resolvePathInConfig(model)
+		}
+		opts := []grpcex.AccessControllerOption{grpcex.WithSubjectsFunc(auth.SubjectsFromContext)}
+		if acConfig.ReloadInterval > 0 {
+			opts = append(opts, grpcex.WithReloadInterval(time.Duration(acConfig.ReloadInterval)*time.Second))
+		}
+		var adapter persist.Adapter
+		if acConfig.Database != "" {
+			db := m.app.GetDatabaseClient(acConfig.Database)
+			if db == nil {
+				log.Logger.Fatal().Str("database", acConfig.Database).Msg("access_control_database_not_found")
+			}
+			adapter = grpcex.NewGormPolicyAdapter(db, acConfig.Table)
+		} else if acConfig.Policy != "" {
+			policy := resolvePathInConfig(acConfig.Policy)
+			adapter = fileadapter.NewAdapter(policy)
+			if acConfig.Watch {
+				opts = append(opts, grpcex.WithWatchFile(policy))
+			}
+		} else {
+			log.Logger.Fatal().Interface("options", options).Msg("grpc_access_middleware_without_policy")
+		}
+		accessController, err := grpcex.NewAccessControllerWithAdapter(model, adapter, opts...)
+		if err != nil {
+			errors.LogError(log.Logger.Fatal(), err).Msg("grpc_access_middleware_init_failed")
+		}
+		return accessController
+	})
+}
+
+// GinHandler authorizes gin routes only if authorize_routes is set,
+// requests forwarded to grpc-gateway are always authorized as gRPC methods
 func (m *accessControlMiddleware) GinHandler(options map[string]interface{}) gin.HandlerFunc {
-	return nil
+	if !m.parseConfig(options).AuthorizeRoutes {
+		return nil
+	}
+	accessController := m.getController(options)
+	if !accessController.HasRouteModel() {
+		log.Logger.Fatal().Interface("options", options).Msg("access_control_model_without_route_definition")
+	}
+	return ginex.AccessControlMiddleware(accessController)
 }
 
 func (m *accessControlMiddleware) GrpcServerInterceptor(options map[string]interface{}) (grpc.UnaryServerInterceptor, grpc.StreamServerInterceptor) {
-	c := config.StringMap(options)
-	model, _ := c.GetString("model")
-	if model != "" {
-		model = resolvePathInConfig(model)
-	}
-	policy, _ := c.GetString("policy")
-	if policy == "" {
-		log.Logger.Fatal().Interface("options", options).Msg("grpc_access_middleware_without_policy")
-	} else {
-		policy = resolvePathInConfig(policy)
-	}
-	accessController, err := grpcex.NewAccessController(model, policy)
-	if err != nil {
-		log.Logger.Fatal().Err(err).Msg("grpc_access_middleware_init_failed")
-	}
+	accessController := m.getController(options)
 	return accessController.UnaryServerInterceptor(), accessController.StreamServerInterceptor()
 }
 
+// close stops reloading policies of access controllers
+func (m *accessControlMiddleware) close() {
+	m.controllers.each(func(c *grpcex.AccessController) {
+		c.Close()
+	})
+}
+
 func (m *accessControlMiddleware) GrpcClientInterceptor(options map[string]interface{}) (grpc.UnaryClientInterceptor, grpc.StreamClientInterceptor) {
 	return nil, nil
 }
+
+type responseCacheMiddleware struct {
+	Middleware
+	app    App
+	caches middlewareInstances[*respcache.Cache]
+}
+
+func NewResponseCacheMiddleware(app App) Middleware {
+	return &responseCacheMiddleware{app: app}
+}
+
+// getCache gets response cache by options, gin handler and grpc interceptor of the same
+// middleware config share one cache so that invalidation applies to both of them
+func (m *responseCacheMiddleware) getCache(options map[string]interface{}) *respcache.Cache {
+	return m.caches.get(options, func() *respcache.Cache {
+		cacheConfig := &respcache.Config{}
+		err := config.StringMap(options).ToStruct(cacheConfig)
+		if err != nil {
+			log.Logger.Fatal().Err(err).Interface("options", options).Msg("parse_response_cache_config_error")
+		}
+		var client cache.Client
+		if cacheConfig.Cache != "" {
+			client = m.app.GetCacheClient(cacheConfig.Cache)
+			if client == nil {
+				log.Logger.Fatal().Str("cache", cacheConfig.Cache).Msg("response_cache_client_not_found")
+			}
+		}
+		c, err := respcache.New(cacheConfig, client)
+		if err != nil {
+			errors.LogError(log.Logger.Fatal(), err).Msg("response_cache_middleware_init_failed")
+		}
+		return c
+	})
+}
+
+func (m *responseCacheMiddleware) GinHandler(options map[string]interface{}) gin.HandlerFunc {
+	return m.getCache(options).GinHandler()
+}
+
+func (m *responseCacheMiddleware) GrpcServerInterceptor(options map[string]interface{}) (grpc.UnaryServerInterceptor, grpc.StreamServerInterceptor) {
+	return m.getCache(options).UnaryServerInterceptor(), nil
+}
+
+func (m *responseCacheMiddleware) close() {
+	m.caches.each(func(c *respcache.Cache) {
+		c.Close()
+	})
+}
+
+func (m *responseCacheMiddleware) GrpcClientInterceptor(options map[string]interface{}) (grpc.UnaryClientInterceptor, grpc.StreamClientInterceptor) {
+	return nil, nil
+}
+
+type jwtAuthMiddleware struct {
+	Middleware
+	authenticators middlewareInstances[*auth.JWTAuthenticator]
+}
+
+func NewJwtAuthMiddleware() Middleware {
+	return &jwtAuthMiddleware{}
+}
+
+// getAuthenticator gets JWT authenticator by options, gin handler and grpc interceptor of the same
+// middleware config share one JWKS cache
+func (m *jwtAuthMiddleware) getAuthenticator(options map[string]interface{}) *auth.JWTAuthenticator {
+	return m.authenticators.get(options, func() *auth.JWTAuthenticator {
+		jwtConfig := &auth.JWTConfig{}
+		err := config.StringMap(options).ToStruct(jwtConfig)
+		if err != nil {
+			log.Logger.Fatal().Err(err).Interface("options", options).Msg("parse_jwt_auth_config_error")
+		}
+		jwtConfig.JWKSFile = resolvePathInConfig(jwtConfig.JWKSFile)
+		authenticator, err := auth.NewJWTAuthenticator(jwtConfig)
+		if err != nil {
+			errors.LogError(log.Logger.Fatal(), err).Msg("jwt_auth_middleware_init_failed")
+		}
+		return authenticator
+	})
+}
+
+func (m *jwtAuthMiddleware) GinHandler(options map[string]interface{}) gin.HandlerFunc {
+	return m.getAuthenticator(options).GinHandler()
+}
+
+func (m *jwtAuthMiddleware) GrpcServerInterceptor(options map[string]interface{}) (grpc.UnaryServerInterceptor, grpc.StreamServerInterceptor) {
+	authenticator := m.getAuthenticator(options)
+	return authenticator.UnaryServerInterceptor(), authenticator.StreamServerInterceptor()
+}
+
+// close stops reloading JWKS files of authenticators
+func (m *jwtAuthMiddleware) close() {
+	m.authenticators.each(func(a *auth.JWTAuthenticator) {
+		a.Close()
+	})
+}
+
+func (m *jwtAuthMiddleware) GrpcClientInterceptor(options map[string]interface{}) (grpc.UnaryClientInterceptor, grpc.StreamClientInterceptor) {
+	return nil, nil
+}
+
+type apiKeyMiddleware struct {
+	Middleware
+	app            App
+	authenticators middlewareInstances[*auth.APIKeyAuthenticator]
+}
+
+func NewApiKeyMiddleware(app App) Middleware {
+	return &apiKeyMiddleware{app: app}
+}
+
+// getAuthenticator gets API key authenticator by options, gin handler and grpc interceptor of the same
+// middleware config share one key cache
+func (m *apiKeyMiddleware) getAuthenticator(options map[string]interface{}) *auth.APIKeyAuthenticator {
+	return m.authenticators.get(options, func() *auth.APIKeyAuthenticator {
+		apiKeyConfig := &auth.APIKeyConfig{}
+		err := config.StringMap(options).ToStruct(apiKeyConfig)
+		if err != nil {
+			log.Logger.Fatal().Err(err).Interface("options", options).Msg("parse_api_key_config_error")
+		}
+		var store auth.APIKeyStore
+		if apiKeyConfig.Database != "" {
+			db := m.app.GetDatabaseClient(apiKeyConfig.Database)
+			if db == nil {
+				log.Logger.Fatal().Str("database", apiKeyConfig.Database).Msg("api_key_database_not_found")
+			}
+			store = auth.NewGormAPIKeyStore(db, apiKeyConfig.Table)
+		} else {
+			store = auth.NewStaticAPIKeyStore(apiKeyConfig.Keys)
+		}
+		authenticator, err := auth.NewAPIKeyAuthenticator(apiKeyConfig, store)
+		if err != nil {
+			errors.LogError(log.Logger.Fatal(), err).Msg("api_key_middleware_init_failed")
+		}
+		return authenticator
+	})
+}
+
+func (m *apiKeyMiddleware) GinHandler(options map[string]interface{}) gin.HandlerFunc {
+	return m.getAuthenticator(options).GinHandler()
+}
+
+func (m *apiKeyMiddleware) GrpcServerInterceptor(options map[string]interface{}) (grpc.UnaryServerInterceptor, grpc.StreamServerInterceptor) {
+	authenticator := m.getAuthenticator(options)
+	return authenticator.UnaryServerInterceptor(), authenticator.StreamServerInterceptor()
+}
+
+func (m *apiKeyMiddleware) GrpcClientInterceptor(options map[string]interface{}) (grpc.UnaryClientInterceptor, grpc.StreamClientInterceptor) {
+	return nil, nil
+}
+
+type auditMiddleware struct {
+	Middleware
+	app      App
+	auditors middlewareInstances[*audit.Auditor]
+}
+
+func NewAuditMiddleware(app App) Middleware {
+	return &auditMiddleware{app: app}
+}
+
+// getAuditor gets auditor by options, gin handler and grpc interceptor of the same middleware config share one sink
+func (m *auditMiddleware) getAuditor(options map[string]interface{}) *audit.Auditor {
+	return m.auditors.get(options, func() *audit.Auditor {
+		auditConfig := &audit.Config{}
+		err := config.StringMap(options).ToStruct(auditConfig)
+		if err != nil {
+			log.Logger.Fatal().Err(err).Interface("options", options).Msg("parse_audit_config_error")
+		}
+		var sink audit.Sink
+		switch auditConfig.Sink {
+		case "", audit.SinkStdout:
+			sink = audit.NewWriterSink(os.Stdout)
+		case audit.SinkStderr:
+			sink = audit.NewWriterSink(os.Stderr)
+		case audit.SinkFile:
+			sink, err = audit.NewFileSink(auditConfig.File)
+		case audit.SinkPulsar:
+			client := m.app.GetPulsarClient(auditConfig.Pulsar)
+			if client == nil {
+				log.Logger.Fatal().Str("pulsar", auditConfig.Pulsar).Msg("audit_pulsar_client_not_found")
+			}
+			sink, err = audit.NewPulsarSink(client, auditConfig.Topic)
+		default:
+			log.Logger.Fatal().Str("sink", auditConfig.Sink).Msg("unknown_audit_sink")
+		}
+		if err != nil {
+			errors.LogError(log.Logger.Fatal(), err).Msg("audit_sink_init_failed")
+		}
+		auditor, err := audit.New(auditConfig, sink)
+		if err != nil {
+			errors.LogError(log.Logger.Fatal(), err).Msg("audit_middleware_init_failed")
+		}
+		return auditor
+	})
+}
+
+func (m *auditMiddleware) GinHandler(options map[string]interface{}) gin.HandlerFunc {
+	return m.getAuditor(options).GinHandler()
+}
+
+func (m *auditMiddleware) GrpcServerInterceptor(options map[string]interface{}) (grpc.UnaryServerInterceptor, grpc.StreamServerInterceptor) {
+	auditor := m.getAuditor(options)
+	return auditor.UnaryServerInterceptor(), auditor.StreamServerInterceptor()
+}
+
+// close closes sinks of auditors
+func (m *auditMiddleware) close() {
+	m.auditors.each(func(a *audit.Auditor) {
+		err := a.Close()
+		if err != nil {
+			logger.Error().Err(err).Msg("close_audit_sink_error")
+		}
+	})
+}
+
+func (m *auditMiddleware) GrpcClientInterceptor(options map[string]interface{}) (grpc.UnaryClientInterceptor, grpc.StreamClientInterceptor) {
+	return nil, nil
+}
+
+// profileLabelsConfig is options of profile_labels middleware
+type profileLabelsConfig struct {
+	// Caller adds label of caller, which is subject of authenticated principal or common name of client certificate
+	Caller bool `json:"caller"`
+}
+
+type profileLabelsMiddleware struct {
+	Middleware
+}
+
+func NewProfileLabelsMiddleware() Middleware {
+	return &profileLabelsMiddleware{}
+}
+
+func (m *profileLabelsMiddleware) parseConfig(options map[string]interface{}) *profileLabelsConfig {
+	labelsConfig := &profileLabelsConfig{}
+	err := config.StringMap(options).ToStruct(labelsConfig)
+	if err != nil {
+		log.Logger.Fatal().Err(err).Interface("options", options).Msg("parse_profile_labels_config_error")
+	}
+	return labelsConfig
+}
+
+// profileLabels returns pprof labels of service, method or route, and caller if enabled
+func profileLabels(ctx IContextGetter, key string, value string, caller string) pprof.LabelSet {
+	labels := make([]string, 0, 6)
+	if service := GetContextService(ctx); service != nil {
+		labels = append(labels, "service", service.GetName())
+	}
+	if value != "" {
+		labels = append(labels, key, value)
+	}
+	if caller != "" {
+		labels = append(labels, "caller", caller)
+	}
+	return pprof.Labels(labels...)
+}
+
+// profileCaller returns subject of authenticated principal, or common name of verified client certificate
+func profileCaller(ctx IContextGetter, tlsState *tls.ConnectionState) string {
+	if principal := auth.PrincipalFromContext(ctx); principal != nil && principal.Subject != "" {
+		return principal.Subject
+	}
+	if tlsState != nil && len(tlsState.VerifiedChains) > 0 && len(tlsState.VerifiedChains[0]) > 0 {
+		return tlsState.VerifiedChains[0][0].Subject.CommonName
+	}
+	return ""
+}
+
+// grpcProfileCaller returns caller of gRPC request in context
+func grpcProfileCaller(ctx context.Context) string {
+	var tlsState *tls.ConnectionState
+	if client, ok := peer.FromContext(ctx); ok {
+		if tlsInfo, ok := client.AuthInfo.(credentials.TLSInfo); ok {
+			tlsState = &tlsInfo.State
+		}
+	}
+	return profileCaller(ctx, tlsState)
+}
+
+func (m *profileLabelsMiddleware) GinHandler(options map[string]interface{}) gin.HandlerFunc {
+	labelsConfig := m.parseConfig(options)
+	return func(c *gin.Context) {
+		route := ""
+		if c.FullPath() != "" {
+			route = c.Request.Method + " " + c.FullPath()
+		}
+		caller := ""
+		if labelsConfig.Caller {
+			caller = profileCaller(c, c.Request.TLS)
+		}
+		pprof.Do(c.Request.Context(), profileLabels(c, "route", route, caller), func(ctx context.Context) {
+			c.Request = c.Request.WithContext(ctx)
+			c.Next()
+		})
+	}
+}
+
+func (m *profileLabelsMiddleware) GrpcServerInterceptor(options map[string]interface{}) (grpc.UnaryServerInterceptor, grpc.StreamServerInterceptor) {
+	labelsConfig := m.parseConfig(options)
+	unaryInterceptor := func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp interface{}, err error) {
+		caller := ""
+		if labelsConfig.Caller {
+			caller = grpcProfileCaller(ctx)
+		}
+		pprof.Do(ctx, profileLabels(ctx, "method", info.FullMethod, caller), func(ctx context.Context) {
+			resp, err = handler(ctx, req)
+		})
+		return resp, err
+	}
+	streamInterceptor := func(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) (err error) {
+		ctx := stream.Context()
+		caller := ""
+		if labelsConfig.Caller {
+			caller = grpcProfileCaller(ctx)
+		}
+		pprof.Do(ctx, profileLabels(ctx, "method", info.FullMethod, caller), func(ctx context.Context) {
+			wrappedStream := grpc_middleware.WrapServerStream(stream)
+			wrappedStream.WrappedContext = ctx
+			err = handler(srv, wrappedStream)
+		})
+		return err
+	}
+	return unaryInterceptor, streamInterceptor
+}
+
+func (m *profileLabelsMiddleware) GrpcClientInterceptor(options map[string]interface{}) (grpc.UnaryClientInterceptor, grpc.StreamClientInterceptor) {
+	return nil, nil
+}
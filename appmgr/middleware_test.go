@@ -0,0 +1,158 @@
+package appmgr
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"runtime/pprof"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"google.golang.org/grpc"
+
+	"github.com/frame-go/framego/auth"
+)
+
+func TestMiddlewareScope(t *testing.T) {
+	scope, err := newMiddlewareScope(map[string]interface{}{
+		"name":    "log_request",
+		"include": []interface{}{"/sample.Sample/*", "/v1/*"},
+		"exclude": []interface{}{"/sample.Sample/Health*"},
+	})
+	if err != nil {
+		t.Fatalf("new_middleware_scope_error: %v", err)
+	}
+	var tests = []struct {
+		method string
+		result bool
+	}{
+		{"/sample.Sample/GetUser", true},
+		{"/sample.Sample/HealthCheck", false},
+		{"/sample.Other/GetUser", false},
+		{"/v1/users", true},
+		{"/v1/users/1", false},
+	}
+	for _, test := range tests {
+		if scope.Contains(test.method) != test.result {
+			t.Errorf("check_scope_error: %v", test)
+		}
+	}
+
+	scope, err = newMiddlewareScope(map[string]interface{}{"name": "log_request"})
+	if err != nil || scope != nil || !scope.Contains("/sample.Sample/GetUser") {
+		t.Errorf("expect_unscoped_middleware: %v, %v", scope, err)
+	}
+	scope, err = newMiddlewareScope(nil)
+	if err != nil || scope != nil {
+		t.Errorf("expect_unscoped_middleware: %v, %v", scope, err)
+	}
+	_, err = newMiddlewareScope(map[string]interface{}{"exclude": []interface{}{"/sample.Sample/["}})
+	if err == nil {
+		t.Errorf("expect_invalid_pattern_error")
+	}
+}
+
+func TestProfileLabelsMiddleware(t *testing.T) {
+	m := NewProfileLabelsMiddleware()
+	options := map[string]interface{}{"name": "profile_labels", "caller": true}
+	principal := &auth.Principal{Type: auth.PrincipalTypeJWT, Subject: "alice"}
+
+	router := gin.New()
+	router.Use(func(c *gin.Context) { auth.SetGinContextPrincipal(c, principal) }, m.GinHandler(options))
+	var route, caller string
+	router.GET("/v1/users/:id", func(c *gin.Context) {
+		route, _ = pprof.Label(c.Request.Context(), "route")
+		caller, _ = pprof.Label(c.Request.Context(), "caller")
+	})
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/v1/users/1", nil))
+	if route != "GET /v1/users/:id" || caller != "alice" {
+		t.Errorf("unexpected gin labels: route %q, caller %q", route, caller)
+	}
+
+	unaryInterceptor, _ := m.GrpcServerInterceptor(options)
+	var method string
+	ctx := auth.SetContextPrincipal(context.Background(), principal)
+	info := &grpc.UnaryServerInfo{FullMethod: "/sample.Sample/GetUser"}
+	_, _ = unaryInterceptor(ctx, nil, info, func(ctx context.Context, req interface{}) (interface{}, error) {
+		method, _ = pprof.Label(ctx, "method")
+		caller, _ = pprof.Label(ctx, "caller")
+		return nil, nil
+	})
+	if method != info.FullMethod || caller != "alice" {
+		t.Errorf("unexpected grpc labels: method %q, caller %q", method, caller)
+	}
+}
+
+// instanceMiddleware records instance got by gin handler or grpc interceptor of each middleware config
+type instanceMiddleware struct {
+	Middleware
+	instances middlewareInstances[*int]
+	got       []*int
+	closed    int
+}
+
+func (m *instanceMiddleware) close() {
+	m.instances.each(func(*int) {
+		m.closed++
+	})
+}
+
+func (m *instanceMiddleware) GinHandler(options map[string]interface{}) gin.HandlerFunc {
+	m.got = append(m.got, m.instances.get(options, func() *int { return new(int) }))
+	return nil
+}
+
+func (m *instanceMiddleware) GrpcServerInterceptor(options map[string]interface{}) (grpc.UnaryServerInterceptor, grpc.StreamServerInterceptor) {
+	m.got = append(m.got, m.instances.get(options, func() *int { return new(int) }))
+	return nil, nil
+}
+
+func TestMiddlewareInstances(t *testing.T) {
+	m := &instanceMiddleware{}
+	mm := newMiddlewareManager()
+	mm.RegisterMiddleware("instance", m)
+	first := mm.Apply(nil, []interface{}{"instance"})
+	second := mm.Apply(nil, []interface{}{"instance", "instance"})
+	first.ApplyGin(gin.New())
+	first.GrpcServerInterceptor()
+	second.ApplyGin(gin.New())
+	if len(m.got) != 4 {
+		t.Fatalf("number of instances %v != 4", len(m.got))
+	}
+	if m.got[0] != m.got[1] {
+		t.Errorf("gin handler and grpc interceptor of the same config get different instances")
+	}
+	if m.got[0] == m.got[2] || m.got[0] == m.got[3] || m.got[2] == m.got[3] {
+		t.Errorf("different configs share one instance")
+	}
+	mm.close()
+	if m.closed != 3 {
+		t.Errorf("number of closed instances %v != 3", m.closed)
+	}
+}
+
+func TestAccessControlGinHandlerOptIn(t *testing.T) {
+	m := NewAccessControlMiddleware(nil)
+	if m.GinHandler(map[string]interface{}{"name": "access_control", "policy": "./acl.csv"}) != nil {
+		t.Errorf("expect_no_route_authorization_without_authorize_routes")
+	}
+}
+
+func TestMiddlewareCheckOrder(t *testing.T) {
+	mm := newMiddlewareManager()
+	mm.RegisterMiddleware("response_cache", &instanceMiddleware{})
+	mm.RegisterMiddleware("api_key", &instanceMiddleware{})
+	ma := mm.Apply(nil, []interface{}{"api_key", "response_cache"})
+	if err := ma.checkOrder(); err != nil {
+		t.Errorf("response cache after auth middleware is rejected: %v", err)
+	}
+	ma = newMiddlewareApplier("test")
+	for _, name := range []interface{}{"response_cache", "api_key"} {
+		if err := ma.AddMiddlewareByConfig(mm, name); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := ma.checkOrder(); err == nil {
+		t.Errorf("response cache before auth middleware is not rejected")
+	}
+}
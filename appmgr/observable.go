@@ -1,18 +1,26 @@
 package appmgr
 
 import (
+	"bytes"
 	"context"
+	"crypto/tls"
+	"html/template"
 	"net/http"
+	"sort"
 	"strings"
 	"sync"
 
 	"github.com/fullstorydev/grpcui/standalone"
 	"github.com/gin-contrib/pprof"
 	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 	channelz "github.com/rantav/go-grpc-channelz"
 	swaggerFiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
+	"github.com/swaggo/swag"
 
+	"github.com/frame-go/framego/errors"
+	"github.com/frame-go/framego/health"
 	"github.com/frame-go/framego/log"
 )
 
@@ -20,32 +28,89 @@ type observableImpl struct {
 	ObservableService
 
 	ctx          context.Context
+	app          *appImpl
 	config       *ObservableConfig
 	services     map[string]Service
 	httpEndpoint string
 	ginEngine    *gin.Engine
+	tlsConfig    *tls.Config
+	defaultAuth  *observableAuth
+	moduleAuths  map[string]*observableAuth
 	waitGroup    sync.WaitGroup
 }
 
-type moduleHandler func(*observableImpl)
+// moduleHandler registers routes of module on router with auth policy of module
+type moduleHandler func(*observableImpl, *gin.RouterGroup)
 
 var moduleMap = map[string]moduleHandler{
-	"pprof":    pprofModule,
-	"metrics":  metricsModule,
-	"swagger":  swaggerModule,
-	"channelz": channelzModule,
-	"grpcui":   grpcuiModule,
+	"pprof":     pprofModule,
+	"metrics":   metricsModule,
+	"swagger":   swaggerModule,
+	"channelz":  channelzModule,
+	"grpcui":    grpcuiModule,
+	"routes":    routesModule,
+	"buildinfo": buildinfoModule,
+	"config":    configModule,
+	"jobs":      jobsModule,
+	"loglevel":  loglevelModule,
+	"profiling": profilingModule,
 }
 
-func newObservable(ctx context.Context, mm *middlewareManager, config *ObservableConfig, services map[string]Service) ObservableService {
+func newObservable(ctx context.Context, app *appImpl, mm *middlewareManager, config *ObservableConfig, services map[string]Service) (ObservableService, error) {
 	o := &observableImpl{}
 	o.ctx = ctx
+	o.app = app
 	o.config = config
 	o.services = services
 	o.httpEndpoint = config.Endpoints.Http
+	securityConfig := &config.Security
+	securityConfig.Resolve()
+	var err error
+	o.tlsConfig, err = newTLSConfig(securityConfig.Cert, securityConfig.Key, securityConfig.Ca)
+	if err != nil {
+		return nil, err
+	}
+	if o.tlsConfig != nil && len(o.tlsConfig.Certificates) == 0 {
+		return nil, errors.New("observable_tls_without_cert_key")
+	}
+	mtls := o.tlsConfig != nil && o.tlsConfig.ClientCAs != nil
+	if mtls {
+		// client certificate is optional, and checked by auth policy of module
+		o.tlsConfig.ClientAuth = tls.VerifyClientCertIfGiven
+	}
+	o.defaultAuth, o.moduleAuths, err = newObservableAuths(config, mtls)
+	if err != nil {
+		return nil, err
+	}
 	middlewares := mm.Apply(nil, []interface{}{"recovery"})
-	o.ginEngine = newGinEngin(middlewares)
-	return o
+	o.ginEngine = newGinEngin(middlewares, errors.HTTPErrorFormatDefault)
+	reporters := make([]health.Reporter, 0, len(services))
+	for _, service := range services {
+		reporters = append(reporters, service.GetHealthReporter())
+	}
+	healthRouter := o.moduleRouter(observableHealthModule)
+	registerProbeHandlers(healthRouter, reporters...)
+	healthRouter.GET("/health", gin.WrapH(health.NewReportHandler(reporters...)))
+	return o, nil
+}
+
+// moduleRouter returns router with auth policy of module, which is default policy if module has no policy
+func (o *observableImpl) moduleRouter(module string) *gin.RouterGroup {
+	auth, ok := o.moduleAuths[module]
+	if !ok {
+		auth = o.defaultAuth
+	}
+	return o.ginEngine.Group("", auth.Handler(module))
+}
+
+// hasModule returns whether observable module is enabled in config
+func (c *ObservableConfig) hasModule(name string) bool {
+	for _, moduleName := range c.Modules {
+		if strings.EqualFold(moduleName, name) {
+			return true
+		}
+	}
+	return false
 }
 
 func (o *observableImpl) GetContext() context.Context {
@@ -56,61 +121,244 @@ func (o *observableImpl) Run() error {
 	for _, moduleName := range o.config.Modules {
 		module, ok := moduleMap[strings.ToLower(moduleName)]
 		if ok {
-			module(o)
+			module(o, o.moduleRouter(strings.ToLower(moduleName)))
 		} else {
 			log.Logger.Error().Str("module", moduleName).Msg("run_observable_unknown_module_name")
 		}
 	}
 
 	o.waitGroup.Add(1)
-	return serveHttpService(o.ctx, ".observable", o.ginEngine, o.httpEndpoint, &o.waitGroup)
+	return serveHttpService(o.ctx, ".observable", o.ginEngine, o.httpEndpoint, o.tlsConfig, &o.waitGroup)
 }
 
 func (o *observableImpl) Wait() {
 	o.waitGroup.Wait()
 }
 
-func pprofModule(o *observableImpl) {
-	pprof.Register(o.ginEngine, "/pprof")
+func pprofModule(o *observableImpl, router *gin.RouterGroup) {
+	pprof.RouteRegister(router, "/pprof")
 }
 
-func metricsModule(o *observableImpl) {
+// metricsModule serves metrics in default prometheus registry, including App.Metrics
+func metricsModule(o *observableImpl, router *gin.RouterGroup) {
+	// metrics path is set to skip requests of metrics in HTTP metrics
 	getGinPrometheus().MetricsPath = "/metrics"
-	getGinPrometheus().SetMetricsPath(o.ginEngine)
+	router.GET("/metrics", gin.WrapH(promhttp.Handler()))
 }
 
-func swaggerModule(o *observableImpl) {
-	o.ginEngine.GET("/swagger", func(c *gin.Context) { c.Redirect(301, "/swagger/index.html") })
-	o.ginEngine.Any("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
+// swaggerModule serves Swagger UI of OpenAPI documents registered by services, and document registered in swag
+func swaggerModule(o *observableImpl, router *gin.RouterGroup) {
+	staticHandler := ginSwagger.WrapHandler(swaggerFiles.Handler)
+	router.GET("/swagger", func(c *gin.Context) { c.Redirect(301, "/swagger/index.html") })
+	router.GET("/swagger/*any", func(c *gin.Context) {
+		file := c.Param("any")
+		switch {
+		case file == "/" || file == "/index.html":
+			o.serveSwaggerIndex(c)
+		case strings.HasPrefix(file, "/specs/"):
+			o.serveOpenAPISpec(c, strings.TrimSuffix(strings.TrimPrefix(file, "/specs/"), ".json"))
+		default:
+			staticHandler(c)
+		}
+	})
 }
 
-func channelzModule(o *observableImpl) {
-	for _, service := range o.services {
-		grpcEndpiont := service.GetGrpcEndpoint()
-		if grpcEndpiont != "" {
-			if strings.HasPrefix(grpcEndpiont, ":") {
-				grpcEndpiont = "127.0.0.1" + grpcEndpiont
-			}
-			if strings.HasPrefix(grpcEndpiont, "0.0.0.0:") {
-				grpcEndpiont = "127.0.0.1:" + grpcEndpiont[8:]
+// openAPIProvider is implemented by services serving OpenAPI documents
+type openAPIProvider interface {
+	openAPISpec(requestHost string) map[string]interface{}
+}
+
+func (s *serviceImpl) openAPISpec(requestHost string) map[string]interface{} {
+	if s.httpEndpoint == "" {
+		return nil
+	}
+	return s.openAPI.Document(s.name, openAPIHost(s.httpEndpoint, requestHost))
+}
+
+type swaggerURL struct {
+	URL  string `json:"url"`
+	Name string `json:"name"`
+}
+
+func (o *observableImpl) serveSwaggerIndex(c *gin.Context) {
+	var urls []swaggerURL
+	for _, service := range o.sortedServices() {
+		provider, ok := service.(openAPIProvider)
+		if ok && provider.openAPISpec(c.Request.Host) != nil {
+			urls = append(urls, swaggerURL{URL: "/swagger/specs/" + service.GetName() + ".json", Name: service.GetName()})
+		}
+	}
+	if _, err := swag.ReadDoc(); err == nil {
+		urls = append(urls, swaggerURL{URL: "/swagger/doc.json", Name: swag.Name})
+	}
+	c.Header("Content-Type", "text/html; charset=utf-8")
+	c.Status(http.StatusOK)
+	_ = swaggerIndexTemplate.Execute(c.Writer, urls)
+}
+
+func (o *observableImpl) serveOpenAPISpec(c *gin.Context, name string) {
+	service, ok := o.services[name]
+	if ok {
+		if provider, ok := service.(openAPIProvider); ok {
+			if doc := provider.openAPISpec(c.Request.Host); doc != nil {
+				c.JSON(http.StatusOK, doc)
+				return
 			}
-			channelzHandler := channelz.CreateHandler("/", grpcEndpiont)
-			o.ginEngine.Any("/channelz/*any", gin.WrapH(channelzHandler))
-			return
 		}
 	}
+	c.String(http.StatusNotFound, http.StatusText(http.StatusNotFound))
 }
 
-func grpcuiModule(o *observableImpl) {
+// swaggerIndexTemplate is Swagger UI page with selector of documents
+var swaggerIndexTemplate = template.Must(template.New("swagger").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="UTF-8">
+<title>Swagger UI</title>
+<link rel="stylesheet" type="text/css" href="./swagger-ui.css">
+<link rel="icon" type="image/png" href="./favicon-32x32.png" sizes="32x32">
+</head>
+<body>
+<div id="swagger-ui"></div>
+<script src="./swagger-ui-bundle.js" charset="UTF-8"></script>
+<script src="./swagger-ui-standalone-preset.js" charset="UTF-8"></script>
+<script>
+window.onload = function() {
+  window.ui = SwaggerUIBundle({
+    urls: {{.}},
+    dom_id: '#swagger-ui',
+    validatorUrl: null,
+    deepLinking: true,
+    presets: [SwaggerUIBundle.presets.apis, SwaggerUIStandalonePreset],
+    plugins: [SwaggerUIBundle.plugins.DownloadUrl],
+    layout: "StandaloneLayout"
+  })
+}
+</script>
+</body>
+</html>
+`))
+
+// sortedServices returns services sorted by name
+func (o *observableImpl) sortedServices() []Service {
+	services := make([]Service, 0, len(o.services))
 	for _, service := range o.services {
-		if service.GetGrpcChannelClient() != nil {
-			grpcHandler, err := standalone.HandlerViaReflection(context.Background(), service.GetGrpcChannelClient(), service.GetGrpcEndpoint())
-			if err == nil {
-				o.ginEngine.Any("/grpcui/*any", gin.WrapH(http.StripPrefix("/grpcui", grpcHandler)))
-			} else {
-				log.Logger.Error().Err(err).Str("service", service.GetName()).Msg("init_observable_grpcui_error")
-			}
-			return
+		services = append(services, service)
+	}
+	sort.Slice(services, func(i, j int) bool { return services[i].GetName() < services[j].GetName() })
+	return services
+}
+
+// channelzModule serves channelz UI of each gRPC service under /channelz/<service>
+func channelzModule(o *observableImpl, router *gin.RouterGroup) {
+	var names []string
+	for _, service := range o.sortedServices() {
+		grpcEndpiont := service.GetGrpcEndpoint()
+		if grpcEndpiont == "" {
+			continue
 		}
+		if strings.HasPrefix(grpcEndpiont, ":") {
+			grpcEndpiont = "127.0.0.1" + grpcEndpiont
+		}
+		if strings.HasPrefix(grpcEndpiont, "0.0.0.0:") {
+			grpcEndpiont = "127.0.0.1:" + grpcEndpiont[8:]
+		}
+		prefix := "/channelz/" + service.GetName()
+		channelzHandler := newServiceChannelzHandler(prefix, channelz.CreateHandler("/", grpcEndpiont))
+		router.Any(prefix+"/*any", gin.WrapH(channelzHandler))
+		names = append(names, service.GetName())
+	}
+	router.GET("/channelz", serviceIndexHandler("Channelz", "/channelz", names))
+}
+
+// grpcuiModule serves gRPC UI of each gRPC service under /grpcui/<service>
+func grpcuiModule(o *observableImpl, router *gin.RouterGroup) {
+	var names []string
+	for _, service := range o.sortedServices() {
+		if service.GetGrpcChannelClient() == nil {
+			continue
+		}
+		grpcHandler, err := standalone.HandlerViaReflection(context.Background(), service.GetGrpcChannelClient(), service.GetGrpcEndpoint())
+		if err != nil {
+			log.Logger.Error().Err(err).Str("service", service.GetName()).Msg("init_observable_grpcui_error")
+			continue
+		}
+		prefix := "/grpcui/" + service.GetName()
+		router.Any(prefix+"/*any", gin.WrapH(http.StripPrefix(prefix, grpcHandler)))
+		names = append(names, service.GetName())
+	}
+	router.GET("/grpcui", serviceIndexHandler("gRPC UI", "/grpcui", names))
+}
+
+var serviceIndexTemplate = template.Must(template.New("index").Parse(`<!DOCTYPE html>
+<html>
+<head><title>{{.Title}}</title></head>
+<body>
+<h1>{{.Title}}</h1>
+<ul>
+{{- range .Names}}
+<li><a href="{{$.Prefix}}/{{.}}/">{{.}}</a></li>
+{{- end}}
+</ul>
+</body>
+</html>
+`))
+
+// serviceIndexHandler serves index page listing links of services under prefix
+func serviceIndexHandler(title string, prefix string, names []string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Header("Content-Type", "text/html; charset=utf-8")
+		c.Status(http.StatusOK)
+		_ = serviceIndexTemplate.Execute(c.Writer, map[string]interface{}{
+			"Title":  title,
+			"Prefix": prefix,
+			"Names":  names,
+		})
 	}
 }
+
+// serviceChannelzHandler serves channelz UI under prefix of service.
+// Channelz UI is created with prefix "/channelz" shared by all services, because links in pages are generated by
+// a global prefix. Requests are rewritten to "/channelz", and links in pages are rewritten back to prefix of service.
+type serviceChannelzHandler struct {
+	prefix  string
+	handler http.Handler
+}
+
+func newServiceChannelzHandler(prefix string, handler http.Handler) http.Handler {
+	return &serviceChannelzHandler{prefix: prefix, handler: handler}
+}
+
+func (h *serviceChannelzHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	r = r.Clone(r.Context())
+	r.URL.Path = "/channelz" + strings.TrimPrefix(r.URL.Path, h.prefix)
+	r.URL.RawPath = ""
+	buffer := &bufferResponseWriter{header: make(http.Header), code: http.StatusOK}
+	h.handler.ServeHTTP(buffer, r)
+	body := bytes.ReplaceAll(buffer.body.Bytes(), []byte(`href="/channelz/`), []byte(`href="`+h.prefix+`/`))
+	for key, values := range buffer.header {
+		w.Header()[key] = values
+	}
+	w.Header().Del("Content-Length")
+	w.WriteHeader(buffer.code)
+	_, _ = w.Write(body)
+}
+
+// bufferResponseWriter buffers response to be rewritten
+type bufferResponseWriter struct {
+	header http.Header
+	code   int
+	body   bytes.Buffer
+}
+
+func (w *bufferResponseWriter) Header() http.Header {
+	return w.header
+}
+
+func (w *bufferResponseWriter) Write(b []byte) (int, error) {
+	return w.body.Write(b)
+}
+
+func (w *bufferResponseWriter) WriteHeader(code int) {
+	w.code = code
+}
@@ -0,0 +1,159 @@
+package appmgr
+
+import (
+	"crypto/subtle"
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/frame-go/framego/errors"
+)
+
+// observableHealthModule is module name of probes and health report in auth policies
+const observableHealthModule = "health"
+
+// observableAuth checks requests of observable endpoint by auth policy
+type observableAuth struct {
+	accounts   map[string]string
+	tokens     []string
+	clientCert bool
+	allowIPs   []*net.IPNet
+}
+
+func newObservableAuth(config *ObservableAuthConfig, mtls bool) (*observableAuth, error) {
+	a := &observableAuth{
+		accounts:   make(map[string]string, len(config.BasicAuth)),
+		tokens:     config.Tokens,
+		clientCert: config.ClientCert,
+	}
+	for _, account := range config.BasicAuth {
+		a.accounts[account.User] = account.Password
+	}
+	if a.clientCert && !mtls {
+		return nil, errors.New("observable_client_cert_auth_without_ca")
+	}
+	for _, allowIP := range config.AllowIPs {
+		ipNet, err := parseIPNet(allowIP)
+		if err != nil {
+			return nil, err
+		}
+		a.allowIPs = append(a.allowIPs, ipNet)
+	}
+	return a, nil
+}
+
+// parseIPNet parses IP or CIDR
+func parseIPNet(s string) (*net.IPNet, error) {
+	if strings.Contains(s, "/") {
+		_, ipNet, err := net.ParseCIDR(s)
+		if err != nil {
+			return nil, errors.Wrap(err, "parse_observable_allow_ip_error").With("ip", s)
+		}
+		return ipNet, nil
+	}
+	ip := net.ParseIP(s)
+	if ip == nil {
+		return nil, errors.New("parse_observable_allow_ip_error").With("ip", s)
+	}
+	bits := 8 * net.IPv6len
+	if ip4 := ip.To4(); ip4 != nil {
+		ip = ip4
+		bits = 8 * net.IPv4len
+	}
+	return &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)}, nil
+}
+
+// hasCredentials returns whether any credential check is configured
+func (a *observableAuth) hasCredentials() bool {
+	return len(a.accounts) > 0 || len(a.tokens) > 0 || a.clientCert
+}
+
+func (a *observableAuth) isIPAllowed(c *gin.Context) bool {
+	if len(a.allowIPs) == 0 {
+		return true
+	}
+	// remote address is used instead of forwarded headers which can be forged
+	ip := net.ParseIP(c.RemoteIP())
+	if ip == nil {
+		return false
+	}
+	for _, ipNet := range a.allowIPs {
+		if ipNet.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+func (a *observableAuth) isAuthenticated(c *gin.Context) bool {
+	if !a.hasCredentials() {
+		return true
+	}
+	if a.clientCert && c.Request.TLS != nil && len(c.Request.TLS.VerifiedChains) > 0 {
+		return true
+	}
+	if len(a.accounts) > 0 {
+		user, password, ok := c.Request.BasicAuth()
+		if ok {
+			expected, found := a.accounts[user]
+			if found && subtle.ConstantTimeCompare([]byte(password), []byte(expected)) == 1 {
+				return true
+			}
+		}
+	}
+	if len(a.tokens) > 0 {
+		token, ok := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer ")
+		if ok {
+			for _, expected := range a.tokens {
+				if subtle.ConstantTimeCompare([]byte(token), []byte(expected)) == 1 {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// Handler creates gin handler checking requests of module
+func (a *observableAuth) Handler(module string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !a.isIPAllowed(c) {
+			logger.Warn().Str("module", module).Str("ip", c.RemoteIP()).Str("path", c.Request.URL.Path).
+				Msg("observable_ip_not_allowed")
+			c.AbortWithStatus(http.StatusForbidden)
+			return
+		}
+		if !a.isAuthenticated(c) {
+			logger.Warn().Str("module", module).Str("ip", c.RemoteIP()).Str("path", c.Request.URL.Path).
+				Msg("observable_unauthenticated")
+			if len(a.accounts) > 0 {
+				c.Header("WWW-Authenticate", `Basic realm="observable"`)
+			}
+			c.AbortWithStatus(http.StatusUnauthorized)
+			return
+		}
+		c.Next()
+	}
+}
+
+// newObservableAuths creates default auth policy and policies of modules
+func newObservableAuths(config *ObservableConfig, mtls bool) (*observableAuth, map[string]*observableAuth, error) {
+	defaultAuth, err := newObservableAuth(&config.Auth, mtls)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "create_observable_auth_error")
+	}
+	moduleAuths := make(map[string]*observableAuth, len(config.ModuleAuth))
+	for module, authConfig := range config.ModuleAuth {
+		if _, ok := moduleMap[strings.ToLower(module)]; !ok && !strings.EqualFold(module, observableHealthModule) {
+			return nil, nil, errors.New("unknown_observable_module_of_auth").With("module", module)
+		}
+		authConfig := authConfig
+		moduleAuths[strings.ToLower(module)], err = newObservableAuth(&authConfig, mtls)
+		if err != nil {
+			return nil, nil, errors.Wrap(err, "create_observable_auth_error").With("module", module)
+		}
+	}
+	return defaultAuth, moduleAuths, nil
+}
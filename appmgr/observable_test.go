@@ -0,0 +1,121 @@
+package appmgr
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestServiceChannelzHandler(t *testing.T) {
+	channelzHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/channelz/channel/1" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html")
+		_, _ = w.Write([]byte(`<a href="/channelz/subchannel/2">subchannel</a>`))
+	})
+	handler := newServiceChannelzHandler("/channelz/sample", channelzHandler)
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/channelz/sample/channel/1", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("status code %v != 200", w.Code)
+	}
+	if body := w.Body.String(); body != `<a href="/channelz/sample/subchannel/2">subchannel</a>` {
+		t.Errorf("unexpected body: %s", body)
+	}
+	if w.Header().Get("Content-Type") != "text/html" {
+		t.Errorf("content type %v != text/html", w.Header().Get("Content-Type"))
+	}
+
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/channelz/sample/unknown", nil))
+	if w.Code != http.StatusNotFound {
+		t.Errorf("status code %v != 404", w.Code)
+	}
+}
+
+func TestServiceIndexHandler(t *testing.T) {
+	handler := serviceIndexHandler("gRPC UI", "/grpcui", []string{"admin", "sample"})
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	handler(c)
+	body := w.Body.String()
+	for _, link := range []string{`href="/grpcui/admin/"`, `href="/grpcui/sample/"`} {
+		if !strings.Contains(body, link) {
+			t.Errorf("link %s not found in %s", link, body)
+		}
+	}
+}
+
+func TestObservableAuth(t *testing.T) {
+	config := &ObservableConfig{
+		Auth: ObservableAuthConfig{
+			BasicAuth: []ObservableAccountConfig{{User: "admin", Password: "pass"}},
+			Tokens:    []string{"secret_token"},
+		},
+		ModuleAuth: map[string]ObservableAuthConfig{
+			"metrics": {},
+			"health":  {AllowIPs: []string{"10.0.0.0/8", "127.0.0.1"}},
+		},
+	}
+	defaultAuth, moduleAuths, err := newObservableAuths(config, false)
+	if err != nil {
+		t.Fatalf("create auth error: %v", err)
+	}
+	o := &observableImpl{ginEngine: gin.New(), defaultAuth: defaultAuth, moduleAuths: moduleAuths}
+	ok := func(c *gin.Context) { c.Status(http.StatusOK) }
+	o.moduleRouter("pprof").GET("/pprof", ok)
+	o.moduleRouter("metrics").GET("/metrics", ok)
+	o.moduleRouter("health").GET("/livez", ok)
+
+	var tests = []struct {
+		path       string
+		remoteAddr string
+		header     string
+		code       int
+	}{
+		{"/pprof", "192.0.2.1:1000", "", http.StatusUnauthorized},
+		{"/pprof", "192.0.2.1:1000", "Basic YWRtaW46cGFzcw==", http.StatusOK},
+		{"/pprof", "192.0.2.1:1000", "Basic YWRtaW46d3Jvbmc=", http.StatusUnauthorized},
+		{"/pprof", "192.0.2.1:1000", "Bearer secret_token", http.StatusOK},
+		{"/pprof", "192.0.2.1:1000", "Bearer wrong_token", http.StatusUnauthorized},
+		{"/metrics", "192.0.2.1:1000", "", http.StatusOK},
+		{"/livez", "10.1.2.3:1000", "", http.StatusOK},
+		{"/livez", "127.0.0.1:1000", "", http.StatusOK},
+		{"/livez", "192.0.2.1:1000", "", http.StatusForbidden},
+	}
+	for _, test := range tests {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, test.path, nil)
+		r.RemoteAddr = test.remoteAddr
+		if test.header != "" {
+			r.Header.Set("Authorization", test.header)
+		}
+		o.ginEngine.ServeHTTP(w, r)
+		if w.Code != test.code {
+			t.Errorf("status code %v != %v: %+v", w.Code, test.code, test)
+		}
+	}
+
+	_, _, err = newObservableAuths(&ObservableConfig{Auth: ObservableAuthConfig{ClientCert: true}}, false)
+	if err == nil {
+		t.Errorf("client cert auth without ca should fail")
+	}
+	_, _, err = newObservableAuths(&ObservableConfig{Auth: ObservableAuthConfig{AllowIPs: []string{"10.0.0"}}}, false)
+	if err == nil {
+		t.Errorf("invalid allow ip should fail")
+	}
+	_, _, err = newObservableAuths(&ObservableConfig{ModuleAuth: map[string]ObservableAuthConfig{"metric": {}}}, false)
+	if err == nil {
+		t.Errorf("auth of unknown module should fail")
+	}
+	_, _, err = newObservableAuths(&ObservableConfig{ModuleAuth: map[string]ObservableAuthConfig{"Metrics": {}, "HEALTH": {}}}, false)
+	if err != nil {
+		t.Errorf("auth of known modules in any case should pass: %v", err)
+	}
+}
@@ -0,0 +1,217 @@
+package appmgr
+
+import (
+	"encoding/json"
+	"io/fs"
+	"net"
+	"path"
+	"strings"
+	"sync"
+
+	"github.com/frame-go/framego/errors"
+)
+
+// openAPIMergeDepths are depths of objects merged in OpenAPI documents, e.g. operations of the same path are merged
+var openAPIMergeDepths = map[string]int{
+	"paths":               2,
+	"definitions":         1,
+	"parameters":          1,
+	"responses":           1,
+	"securityDefinitions": 1,
+	"components":          2,
+}
+
+// openAPIDocument is OpenAPI document merged from documents registered by service, Swagger 2.0 or OpenAPI 3
+type openAPIDocument struct {
+	lock sync.RWMutex
+	doc  map[string]interface{}
+}
+
+// openAPIMajorVersion returns major version of document, e.g. "2" for Swagger 2.0 and "3" for OpenAPI 3
+func openAPIMajorVersion(doc map[string]interface{}) string {
+	version, _ := doc["swagger"].(string)
+	if version == "" {
+		version, _ = doc["openapi"].(string)
+	}
+	major, _, _ := strings.Cut(version, ".")
+	return major
+}
+
+// Register merges OpenAPI documents in JSON matched by patterns in fsys, all JSON files if no pattern
+func (d *openAPIDocument) Register(fsys fs.FS, patterns ...string) error {
+	var files []string
+	if len(patterns) == 0 {
+		err := fs.WalkDir(fsys, ".", func(name string, entry fs.DirEntry, err error) error {
+			if err == nil && !entry.IsDir() && path.Ext(name) == ".json" {
+				files = append(files, name)
+			}
+			return err
+		})
+		if err != nil {
+			return errors.Wrap(err, "list_openapi_files_error")
+		}
+	}
+	for _, pattern := range patterns {
+		matches, err := fs.Glob(fsys, pattern)
+		if err != nil {
+			return errors.Wrap(err, "list_openapi_files_error").With("pattern", pattern)
+		}
+		files = append(files, matches...)
+	}
+	for _, file := range files {
+		data, err := fs.ReadFile(fsys, file)
+		if err != nil {
+			return errors.Wrap(err, "read_openapi_file_error").With("file", file)
+		}
+		err = d.Merge(data)
+		if err != nil {
+			return errors.Wrap(err, "merge_openapi_file_error").With("file", file)
+		}
+	}
+	return nil
+}
+
+// Merge merges OpenAPI document in JSON, objects with the same name are kept as the first one
+func (d *openAPIDocument) Merge(data []byte) error {
+	doc := make(map[string]interface{})
+	err := json.Unmarshal(data, &doc)
+	if err != nil {
+		return errors.Wrap(err, "parse_openapi_document_error")
+	}
+	version := openAPIMajorVersion(doc)
+	if version == "" {
+		return errors.New("unknown_openapi_version")
+	}
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	if d.doc == nil {
+		d.doc = doc
+		return nil
+	}
+	if current := openAPIMajorVersion(d.doc); version != current {
+		return errors.New("openapi_version_mismatch").With("version", version).With("expected", current)
+	}
+	for key, value := range doc {
+		switch key {
+		case "tags":
+			d.doc[key] = mergeOpenAPITags(d.doc[key], value)
+		case "consumes", "produces", "schemes":
+			d.doc[key] = mergeOpenAPIList(d.doc[key], value)
+		default:
+			depth, ok := openAPIMergeDepths[key]
+			if !ok {
+				continue
+			}
+			dst, _ := d.doc[key].(map[string]interface{})
+			src, _ := value.(map[string]interface{})
+			if dst == nil {
+				d.doc[key] = src
+			} else {
+				mergeOpenAPIObject(dst, src, depth)
+			}
+		}
+	}
+	return nil
+}
+
+// Document returns copy of merged document with title and server rewritten, returns nil if no document registered
+func (d *openAPIDocument) Document(title string, host string) map[string]interface{} {
+	d.lock.RLock()
+	defer d.lock.RUnlock()
+	if d.doc == nil {
+		return nil
+	}
+	doc := make(map[string]interface{}, len(d.doc))
+	for key, value := range d.doc {
+		doc[key] = value
+	}
+	info := make(map[string]interface{})
+	if srcInfo, ok := d.doc["info"].(map[string]interface{}); ok {
+		for key, value := range srcInfo {
+			info[key] = value
+		}
+	}
+	info["title"] = title
+	doc["info"] = info
+	if openAPIMajorVersion(doc) == "2" {
+		doc["host"] = host
+		doc["schemes"] = []string{"http"}
+	} else {
+		doc["servers"] = []map[string]string{{"url": "http://" + host}}
+	}
+	return doc
+}
+
+func mergeOpenAPIObject(dst map[string]interface{}, src map[string]interface{}, depth int) {
+	for key, value := range src {
+		existing, ok := dst[key]
+		if !ok {
+			dst[key] = value
+			continue
+		}
+		if depth > 1 {
+			dstObject, dstOK := existing.(map[string]interface{})
+			srcObject, srcOK := value.(map[string]interface{})
+			if dstOK && srcOK {
+				mergeOpenAPIObject(dstObject, srcObject, depth-1)
+			}
+		}
+	}
+}
+
+func mergeOpenAPITags(dst interface{}, src interface{}) interface{} {
+	dstTags, _ := dst.([]interface{})
+	srcTags, _ := src.([]interface{})
+	names := make(map[string]bool, len(dstTags))
+	for _, tag := range dstTags {
+		if object, ok := tag.(map[string]interface{}); ok {
+			name, _ := object["name"].(string)
+			names[name] = true
+		}
+	}
+	for _, tag := range srcTags {
+		object, ok := tag.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		name, _ := object["name"].(string)
+		if !names[name] {
+			names[name] = true
+			dstTags = append(dstTags, tag)
+		}
+	}
+	return dstTags
+}
+
+func mergeOpenAPIList(dst interface{}, src interface{}) interface{} {
+	dstList, _ := dst.([]interface{})
+	srcList, _ := src.([]interface{})
+	for _, item := range srcList {
+		found := false
+		for _, existing := range dstList {
+			if existing == item {
+				found = true
+				break
+			}
+		}
+		if !found {
+			dstList = append(dstList, item)
+		}
+	}
+	return dstList
+}
+
+// openAPIHost returns host of HTTP endpoint for clients, unspecified host is replaced by host of request
+func openAPIHost(endpoint string, requestHost string) string {
+	host, port, err := net.SplitHostPort(endpoint)
+	if err != nil {
+		return endpoint
+	}
+	if host == "" || net.ParseIP(host).IsUnspecified() {
+		host = requestHost
+		if h, _, err := net.SplitHostPort(requestHost); err == nil {
+			host = h
+		}
+	}
+	return net.JoinHostPort(host, port)
+}
@@ -0,0 +1,79 @@
+package appmgr
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"github.com/frame-go/framego/health"
+)
+
+func TestOpenAPIDocument(t *testing.T) {
+	doc := &openAPIDocument{}
+	err := doc.Register(health.OpenAPI())
+	if err != nil {
+		t.Fatalf("register health document error: %v", err)
+	}
+	fsys := fstest.MapFS{
+		"sample.swagger.json": {Data: []byte(`{
+			"swagger": "2.0",
+			"info": {"title": "sample.proto", "version": "1.0"},
+			"tags": [{"name": "Sample"}, {"name": "Health"}],
+			"paths": {
+				"/v1/users": {"get": {"operationId": "Sample_ListUsers"}},
+				"/health/v1/check": {"post": {"operationId": "Sample_Check"}}
+			},
+			"definitions": {"v1User": {"type": "object"}, "rpcStatus": {"type": "string"}}
+		}`)},
+		"v3.json":    {Data: []byte(`{"openapi": "3.0.0", "paths": {}}`)},
+		"readme.txt": {Data: []byte(`not a document`)},
+	}
+	err = doc.Register(fsys, "sample.*.json")
+	if err != nil {
+		t.Fatalf("register sample document error: %v", err)
+	}
+	err = doc.Register(fsys, "v3.json")
+	if err == nil {
+		t.Errorf("documents of different versions should not be merged")
+	}
+
+	result := doc.Document("sample", openAPIHost(":8081", "10.0.0.1:8080"))
+	if result["host"] != "10.0.0.1:8081" {
+		t.Errorf("host %v != 10.0.0.1:8081", result["host"])
+	}
+	if result["info"].(map[string]interface{})["title"] != "sample" {
+		t.Errorf("title %v != sample", result["info"])
+	}
+	paths := result["paths"].(map[string]interface{})
+	for _, p := range []string{"/v1/users", "/health/v1/check"} {
+		if paths[p] == nil {
+			t.Errorf("path %v not found", p)
+		}
+	}
+	check := paths["/health/v1/check"].(map[string]interface{})
+	if check["get"] == nil || check["post"] == nil {
+		t.Errorf("operations of path are not merged: %v", check)
+	}
+	definitions := result["definitions"].(map[string]interface{})
+	if definitions["v1User"] == nil || definitions["rpcStatus"].(map[string]interface{})["type"] != "object" {
+		t.Errorf("unexpected definitions: %v", definitions)
+	}
+	if tags := result["tags"].([]interface{}); len(tags) != 2 {
+		t.Errorf("tags %v != 2", len(tags))
+	}
+
+	var hostTests = []struct {
+		endpoint    string
+		requestHost string
+		host        string
+	}{
+		{":8081", "example.com:8080", "example.com:8081"},
+		{"0.0.0.0:8081", "example.com", "example.com:8081"},
+		{"127.0.0.1:8081", "example.com:8080", "127.0.0.1:8081"},
+		{":8081", "[::1]:8080", "[::1]:8081"},
+	}
+	for _, test := range hostTests {
+		if host := openAPIHost(test.endpoint, test.requestHost); host != test.host {
+			t.Errorf("host %v != %v: %+v", host, test.host, test)
+		}
+	}
+}
@@ -0,0 +1,405 @@
+package appmgr
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"runtime/pprof"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/procfs"
+
+	"github.com/frame-go/framego/errors"
+	"github.com/frame-go/framego/log"
+)
+
+// Types of captured profiles
+const (
+	profileCPU       = "cpu"
+	profileHeap      = "heap"
+	profileGoroutine = "goroutine"
+	profileMutex     = "mutex"
+)
+
+// Reasons of captures, which are scheduled or triggered by thresholds
+const (
+	profilingReasonSchedule   = "schedule"
+	profilingReasonCPU        = "cpu"
+	profilingReasonRSS        = "rss"
+	profilingReasonGoroutines = "goroutines"
+)
+
+const (
+	defaultProfilingDir                  = "profiles"
+	defaultProfilingMaxCaptures          = 25
+	defaultProfilingCPUDuration          = 10
+	defaultProfilingCheckInterval        = 10
+	defaultProfilingCooldown             = 300
+	defaultProfilingMutexProfileFraction = 10
+)
+
+// profile files are named as <time>_<reason>_<profile>.pb.gz, so that names are sorted by time
+const (
+	profileFileExt        = ".pb.gz"
+	profileFileTempExt    = ".tmp"
+	profileFileTimeFormat = "20060102T150405.000Z"
+)
+
+var profileTypes = []string{profileCPU, profileHeap, profileGoroutine, profileMutex}
+
+var profilingReasons = []string{profilingReasonSchedule, profilingReasonCPU, profilingReasonRSS, profilingReasonGoroutines}
+
+// profileFile is profile file captured in profiling dir
+type profileFile struct {
+	Name    string    `json:"name"`
+	Profile string    `json:"profile"`
+	Reason  string    `json:"reason"`
+	Time    time.Time `json:"time"`
+	Size    int64     `json:"size"`
+}
+
+// parseProfileFileName parses name of profile file, returns false if it is not a profile file
+func parseProfileFileName(name string) (profileFile, bool) {
+	base, ok := strings.CutSuffix(name, profileFileExt)
+	if !ok {
+		return profileFile{}, false
+	}
+	parts := strings.Split(base, "_")
+	if len(parts) != 3 || !containsString(profilingReasons, parts[1]) || !containsString(profileTypes, parts[2]) {
+		return profileFile{}, false
+	}
+	captureTime, err := time.Parse(profileFileTimeFormat, parts[0])
+	if err != nil {
+		return profileFile{}, false
+	}
+	return profileFile{Name: name, Profile: parts[2], Reason: parts[1], Time: captureTime}, true
+}
+
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
+
+// processSample is usage of process checked against thresholds
+type processSample struct {
+	CPUPercent float64
+	RSS        int64
+	Goroutines int
+}
+
+// processSampler samples usage of process, CPU and RSS are only available with procfs
+type processSampler struct {
+	proc        *procfs.Proc
+	lastCPUTime float64
+	lastTime    time.Time
+}
+
+func newProcessSampler() *processSampler {
+	s := &processSampler{}
+	proc, err := procfs.Self()
+	if err != nil {
+		logger.Warn().Err(err).Msg("profiling_process_stat_unavailable")
+		return s
+	}
+	s.proc = &proc
+	return s
+}
+
+// sample returns usage of process, CPU usage is average since last sample and zero for the first sample
+func (s *processSampler) sample() processSample {
+	result := processSample{Goroutines: runtime.NumGoroutine()}
+	if s.proc == nil {
+		return result
+	}
+	stat, err := s.proc.Stat()
+	if err != nil {
+		logger.Warn().Err(err).Msg("profiling_read_process_stat_error")
+		return result
+	}
+	now := time.Now()
+	cpuTime := stat.CPUTime()
+	result.RSS = int64(stat.ResidentMemory())
+	if !s.lastTime.IsZero() {
+		elapsed := now.Sub(s.lastTime).Seconds()
+		if elapsed > 0 {
+			result.CPUPercent = (cpuTime - s.lastCPUTime) / elapsed / float64(runtime.NumCPU()) * 100
+		}
+	}
+	s.lastCPUTime = cpuTime
+	s.lastTime = now
+	return result
+}
+
+// profiler captures profiles on schedule or when thresholds are crossed, and keeps the newest files in dir
+type profiler struct {
+	dir           string
+	maxCaptures   int
+	profiles      []string
+	cpuDuration   time.Duration
+	interval      time.Duration
+	checkInterval time.Duration
+	cooldown      time.Duration
+	thresholds    ProfilingThresholdsConfig
+	sampler       *processSampler
+	lastTriggered time.Time
+
+	// lock serializes captures and rotation
+	lock sync.Mutex
+}
+
+func newProfiler(config *ProfilingConfig) (*profiler, error) {
+	p := &profiler{
+		dir:           config.Dir,
+		maxCaptures:   config.MaxCaptures,
+		profiles:      config.Profiles,
+		cpuDuration:   time.Duration(config.CPUDuration) * time.Second,
+		interval:      time.Duration(config.Interval) * time.Second,
+		checkInterval: time.Duration(config.CheckInterval) * time.Second,
+		cooldown:      time.Duration(config.Cooldown) * time.Second,
+		thresholds:    config.Thresholds,
+	}
+	if p.dir == "" {
+		p.dir = defaultProfilingDir
+	}
+	if p.maxCaptures == 0 {
+		p.maxCaptures = defaultProfilingMaxCaptures
+	}
+	if len(p.profiles) == 0 {
+		p.profiles = profileTypes
+	}
+	if p.cpuDuration == 0 {
+		p.cpuDuration = defaultProfilingCPUDuration * time.Second
+	}
+	if p.checkInterval == 0 {
+		p.checkInterval = defaultProfilingCheckInterval * time.Second
+	}
+	if p.cooldown == 0 {
+		p.cooldown = defaultProfilingCooldown * time.Second
+	}
+	err := os.MkdirAll(p.dir, 0o755)
+	if err != nil {
+		return nil, errors.Wrap(err, "create_profiling_dir_error").With("dir", p.dir)
+	}
+	if containsString(p.profiles, profileMutex) && runtime.SetMutexProfileFraction(-1) == 0 {
+		// mutex profile is empty unless contention events are reported
+		fraction := config.MutexProfileFraction
+		if fraction == 0 {
+			fraction = defaultProfilingMutexProfileFraction
+		}
+		runtime.SetMutexProfileFraction(fraction)
+	}
+	return p, nil
+}
+
+// hasThresholds returns whether any threshold is configured
+func (p *profiler) hasThresholds() bool {
+	return p.thresholds.CPUPercent > 0 || p.thresholds.RSS > 0 || p.thresholds.Goroutines > 0
+}
+
+// exceeded returns reason and value of the first threshold crossed by sample, empty reason if none is crossed
+func (p *profiler) exceeded(sample processSample) (string, float64) {
+	if p.thresholds.CPUPercent > 0 && sample.CPUPercent >= p.thresholds.CPUPercent {
+		return profilingReasonCPU, sample.CPUPercent
+	}
+	if p.thresholds.RSS > 0 && sample.RSS >= p.thresholds.RSS*1024*1024 {
+		return profilingReasonRSS, float64(sample.RSS)
+	}
+	if p.thresholds.Goroutines > 0 && sample.Goroutines >= p.thresholds.Goroutines {
+		return profilingReasonGoroutines, float64(sample.Goroutines)
+	}
+	return "", 0
+}
+
+// Run captures profiles on schedule and checks thresholds until context is done
+func (p *profiler) Run(ctx context.Context) {
+	var scheduleC, checkC <-chan time.Time
+	if p.interval > 0 {
+		ticker := time.NewTicker(p.interval)
+		defer ticker.Stop()
+		scheduleC = ticker.C
+	}
+	if p.hasThresholds() {
+		p.sampler = newProcessSampler()
+		p.sampler.sample()
+		ticker := time.NewTicker(p.checkInterval)
+		defer ticker.Stop()
+		checkC = ticker.C
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-scheduleC:
+			p.capture(ctx, profilingReasonSchedule)
+		case <-checkC:
+			reason, value := p.exceeded(p.sampler.sample())
+			if reason == "" || time.Since(p.lastTriggered) < p.cooldown {
+				continue
+			}
+			logger.Warn().Str("reason", reason).Float64("value", value).Msg("profiling_threshold_crossed")
+			p.lastTriggered = time.Now()
+			p.capture(ctx, reason)
+		}
+	}
+}
+
+// capture writes configured profiles to dir and removes files of the oldest captures beyond max captures
+func (p *profiler) capture(ctx context.Context, reason string) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	prefix := time.Now().UTC().Format(profileFileTimeFormat) + "_" + reason + "_"
+	var names []string
+	for _, profile := range p.profiles {
+		name := prefix + profile + profileFileExt
+		err := p.writeProfile(ctx, profile, filepath.Join(p.dir, name))
+		if err != nil {
+			errors.LogError(logger.Error(), err).Str("profile", profile).Str("reason", reason).
+				Msg("capture_profile_error")
+			continue
+		}
+		names = append(names, name)
+	}
+	logger.Info().Str("reason", reason).Strs("files", names).Msg("profiles_captured")
+	p.rotate()
+}
+
+// writeProfile writes profile to temp file, and renames it to path on success
+func (p *profiler) writeProfile(ctx context.Context, profile string, path string) error {
+	tempPath := path + profileFileTempExt
+	f, err := os.Create(tempPath)
+	if err != nil {
+		return errors.Wrap(err, "create_profile_file_error").With("path", tempPath)
+	}
+	if profile == profileCPU {
+		err = p.writeCPUProfile(ctx, f)
+	} else {
+		err = pprof.Lookup(profile).WriteTo(f, 0)
+		if err != nil {
+			err = errors.Wrap(err, "write_profile_error")
+		}
+	}
+	closeErr := f.Close()
+	if err == nil && closeErr != nil {
+		err = errors.Wrap(closeErr, "close_profile_file_error").With("path", tempPath)
+	}
+	if err == nil {
+		err = os.Rename(tempPath, path)
+		if err != nil {
+			err = errors.Wrap(err, "rename_profile_file_error").With("path", path)
+		}
+	}
+	if err != nil {
+		_ = os.Remove(tempPath)
+	}
+	return err
+}
+
+// writeCPUProfile records CPU profile for duration, or until context is done.
+// It fails if CPU profile is being recorded by others, e.g. pprof module.
+func (p *profiler) writeCPUProfile(ctx context.Context, f *os.File) error {
+	err := pprof.StartCPUProfile(f)
+	if err != nil {
+		return errors.Wrap(err, "start_cpu_profile_error")
+	}
+	timer := time.NewTimer(p.cpuDuration)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+	case <-timer.C:
+	}
+	pprof.StopCPUProfile()
+	return nil
+}
+
+// rotate removes files of the oldest captures beyond max captures.
+// Files of one capture share the prefix "<time>_<reason>_", and are kept or removed together.
+func (p *profiler) rotate() {
+	files, err := p.list()
+	if err != nil {
+		errors.LogError(logger.Error(), err).Msg("rotate_profile_files_error")
+		return
+	}
+	captures := 0
+	lastPrefix := ""
+	for _, file := range files {
+		prefix := strings.TrimSuffix(file.Name, file.Profile+profileFileExt)
+		if prefix != lastPrefix {
+			captures++
+			lastPrefix = prefix
+		}
+		if captures <= p.maxCaptures {
+			continue
+		}
+		err = os.Remove(filepath.Join(p.dir, file.Name))
+		if err != nil && !os.IsNotExist(err) {
+			logger.Error().Err(err).Str("file", file.Name).Msg("remove_profile_file_error")
+		}
+	}
+}
+
+// list returns profile files in dir, the newest first
+func (p *profiler) list() ([]profileFile, error) {
+	entries, err := os.ReadDir(p.dir)
+	if err != nil {
+		return nil, errors.Wrap(err, "read_profiling_dir_error").With("dir", p.dir)
+	}
+	files := make([]profileFile, 0, len(entries))
+	for _, entry := range entries {
+		file, ok := parseProfileFileName(entry.Name())
+		if !ok || !entry.Type().IsRegular() {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		file.Size = info.Size()
+		files = append(files, file)
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].Name > files[j].Name })
+	return files, nil
+}
+
+// filePath returns path of profile file by name, returns false if name is not a profile file
+func (p *profiler) filePath(name string) (string, bool) {
+	if _, ok := parseProfileFileName(name); !ok {
+		return "", false
+	}
+	return filepath.Join(p.dir, name), true
+}
+
+// profilingModule lists profile files captured by profiler, and downloads them by name
+func profilingModule(o *observableImpl, router *gin.RouterGroup) {
+	if o.app == nil || o.app.profiler == nil {
+		log.Logger.Error().Msg("profiling_module_without_profiling_enabled")
+		return
+	}
+	p := o.app.profiler
+	router.GET("/profiling", func(c *gin.Context) {
+		files, err := p.list()
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"profiles": files})
+	})
+	router.GET("/profiling/:name", func(c *gin.Context) {
+		name := c.Param("name")
+		path, ok := p.filePath(name)
+		if !ok {
+			c.Status(http.StatusNotFound)
+			return
+		}
+		c.FileAttachment(path, name)
+	})
+}
@@ -0,0 +1,109 @@
+package appmgr
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestParseProfileFileName(t *testing.T) {
+	var tests = []struct {
+		name string
+		ok   bool
+	}{
+		{"20261018T221242.123Z_schedule_heap.pb.gz", true},
+		{"20261018T221242.123Z_cpu_cpu.pb.gz", true},
+		{"20261018T221242.123Z_cpu_cpu.pb.gz.tmp", false},
+		{"20261018T221242.123Z_unknown_heap.pb.gz", false},
+		{"20261018T221242.123Z_schedule_block.pb.gz", false},
+		{"now_schedule_heap.pb.gz", false},
+		{"../20261018T221242.123Z_schedule_heap.pb.gz", false},
+	}
+	for _, test := range tests {
+		if _, ok := parseProfileFileName(test.name); ok != test.ok {
+			t.Errorf("parse %s: %v != %v", test.name, ok, test.ok)
+		}
+	}
+}
+
+func TestProfilerExceeded(t *testing.T) {
+	p := &profiler{thresholds: ProfilingThresholdsConfig{CPUPercent: 80, RSS: 100, Goroutines: 1000}}
+	var tests = []struct {
+		sample processSample
+		reason string
+	}{
+		{processSample{CPUPercent: 50, RSS: 10 << 20, Goroutines: 10}, ""},
+		{processSample{CPUPercent: 90, RSS: 200 << 20, Goroutines: 10}, profilingReasonCPU},
+		{processSample{CPUPercent: 50, RSS: 200 << 20, Goroutines: 10}, profilingReasonRSS},
+		{processSample{CPUPercent: 50, RSS: 10 << 20, Goroutines: 2000}, profilingReasonGoroutines},
+	}
+	for _, test := range tests {
+		if reason, _ := p.exceeded(test.sample); reason != test.reason {
+			t.Errorf("exceeded %+v: %v != %v", test.sample, reason, test.reason)
+		}
+	}
+}
+
+func TestProfilerCapture(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "profiles")
+	p, err := newProfiler(&ProfilingConfig{Dir: dir, MaxCaptures: 2, Profiles: []string{profileCPU, profileHeap}})
+	if err != nil {
+		t.Fatalf("create profiler error: %v", err)
+	}
+	p.cpuDuration = 10 * time.Millisecond
+	for i := 0; i < 3; i++ {
+		p.capture(context.Background(), profilingReasonSchedule)
+		time.Sleep(2 * time.Millisecond)
+	}
+	files, err := p.list()
+	if err != nil {
+		t.Fatalf("list profiles error: %v", err)
+	}
+	if len(files) != 4 {
+		t.Fatalf("number of profiles %v != 4", len(files))
+	}
+	if !files[0].Time.After(files[3].Time) {
+		t.Errorf("profiles not sorted by newest first: %+v", files)
+	}
+	if files[0].Time != files[1].Time || files[2].Time != files[3].Time {
+		t.Errorf("files of capture not kept together: %+v", files)
+	}
+	for _, file := range files {
+		if file.Size == 0 {
+			t.Errorf("empty profile %s", file.Name)
+		}
+	}
+
+	router := gin.New()
+	profilingModule(&observableImpl{app: &appImpl{profiler: p}}, router.Group(""))
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/profiling", nil))
+	var result struct {
+		Profiles []profileFile `json:"profiles"`
+	}
+	if err = json.Unmarshal(w.Body.Bytes(), &result); err != nil || len(result.Profiles) != 4 {
+		t.Errorf("unexpected list: %v, %s", err, w.Body.String())
+	}
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/profiling/"+files[0].Name, nil))
+	if w.Code != http.StatusOK || int64(w.Body.Len()) != files[0].Size {
+		t.Errorf("download status %v, size %v != %v", w.Code, w.Body.Len(), files[0].Size)
+	}
+
+	if err = os.WriteFile(filepath.Join(dir, "secret.txt"), []byte("secret"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/profiling/secret.txt", nil))
+	if w.Code != http.StatusNotFound {
+		t.Errorf("download of non-profile file status %v != 404", w.Code)
+	}
+}
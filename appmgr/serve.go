@@ -2,6 +2,7 @@ package appmgr
 
 import (
 	"context"
+	"crypto/tls"
 	"errors"
 	"net"
 	"net/http"
@@ -15,13 +16,15 @@ import (
 
 const shutdownTimeout = 10
 
-func serveHttpService(ctx context.Context, name string, httpHandler http.Handler, endpoint string, wg *sync.WaitGroup) error {
+func serveHttpService(ctx context.Context, name string, httpHandler http.Handler, endpoint string, tlsConfig *tls.Config,
+	wg *sync.WaitGroup) error {
 	ctxLogger := log.Logger.With().Str("service", name).Str("type", "http").Str("endpoint", endpoint).Logger()
 	ctxLogger.Info().Msg("start_serving_http_server")
 
 	srv := &http.Server{
-		Addr:    endpoint,
-		Handler: httpHandler,
+		Addr:      endpoint,
+		Handler:   httpHandler,
+		TLSConfig: tlsConfig,
 	}
 
 	lis, err := net.Listen("tcp", endpoint)
@@ -33,7 +36,13 @@ func serveHttpService(ctx context.Context, name string, httpHandler http.Handler
 	// Initializing the server in a goroutine so that
 	// it won't block the graceful shutdown handling below
 	go func() {
-		err := srv.Serve(lis)
+		var err error
+		if tlsConfig != nil {
+			// certificates are loaded in TLS config
+			err = srv.ServeTLS(lis, "", "")
+		} else {
+			err = srv.Serve(lis)
+		}
 		if err != nil && !errors.Is(err, http.ErrServerClosed) {
 			ctxLogger.Error().Err(err).Msg("run_http_server_error")
 			panic(err)
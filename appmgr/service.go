@@ -3,8 +3,10 @@ package appmgr
 import (
 	"context"
 	"fmt"
+	"io/fs"
 	"net/http"
 	"sync"
+	"time"
 
 	"github.com/fullstorydev/grpchan"
 	"github.com/fullstorydev/grpchan/inprocgrpc"
@@ -15,26 +17,30 @@ import (
 	channelzservice "google.golang.org/grpc/channelz/service"
 	"google.golang.org/grpc/reflection"
 
+	"github.com/frame-go/framego/errors"
+	"github.com/frame-go/framego/grpcex"
 	"github.com/frame-go/framego/health"
 )
 
 type serviceImpl struct {
 	Service
 
-	ctx           context.Context
-	name          string
-	app           App
-	middlewares   *middlewareApplier
-	grpcEndpoint  string
-	grpcServer    *grpc.Server
-	grpcRegistrar *grpchan.HandlerMap
-	httpEndpoint  string
-	ginEngine     *gin.Engine
-	grpcChannel   *inprocgrpc.Channel
-	grpcHttpMux   *runtime.ServeMux
-	waitGroup     sync.WaitGroup
-	healthServer  health.Server
-	healthRunner  health.Runner
+	ctx               context.Context
+	name              string
+	app               App
+	middlewares       *middlewareApplier
+	grpcEndpoint      string
+	grpcServer        *grpc.Server
+	grpcRegistrar     *grpchan.HandlerMap
+	httpEndpoint      string
+	ginEngine         *gin.Engine
+	grpcChannel       *inprocgrpc.Channel
+	grpcHttpMux       *runtime.ServeMux
+	grpcStreamGateway *grpcex.StreamGateway
+	waitGroup         sync.WaitGroup
+	healthServer      health.Server
+	healthRunner      health.Runner
+	openAPI           openAPIDocument
 }
 
 func newService(ctx context.Context, app App, mm *middlewareManager, config *ServiceConfig) (Service, error) {
@@ -43,6 +49,12 @@ func newService(ctx context.Context, app App, mm *middlewareManager, config *Ser
 	s.app = app
 	s.name = config.Name
 	s.middlewares = mm.Apply(s, config.Middlewares)
+	startupTimeout := config.Health.StartupTimeout
+	if startupTimeout == 0 {
+		startupTimeout = defaultStartupTimeout
+	}
+	s.healthRunner = health.NewRunner(health.WithName(s.name),
+		health.WithStartupTimeout(time.Duration(startupTimeout)*time.Second))
 	if config.Endpoints.Grpc != "" {
 		s.grpcEndpoint = config.Endpoints.Grpc
 		grpcSecurityConfig := &config.Security.Grpc
@@ -56,17 +68,42 @@ func newService(ctx context.Context, app App, mm *middlewareManager, config *Ser
 	}
 	if config.Endpoints.Http != "" {
 		s.httpEndpoint = config.Endpoints.Http
-		s.ginEngine = newGinEngin(s.middlewares)
+		var unguarded []func(gin.IRoutes)
+		if !config.Health.DisableProbes {
+			// probes are checked by kubelet without credentials
+			unguarded = append(unguarded, func(routes gin.IRoutes) {
+				registerProbeHandlers(routes, s.healthRunner)
+			})
+		}
+		s.ginEngine = newGinEngin(s.middlewares, errors.HTTPErrorFormat(config.ErrorFormat), unguarded...)
 		if config.Endpoints.Grpc != "" {
-			s.grpcHttpMux = newGrpcHttpMux()
+			errorFormat := errors.HTTPErrorFormat(config.ErrorFormat)
+			jsonMarshaler := newGatewayJSONMarshaler(&config.Gateway)
+			s.grpcHttpMux = newGrpcHttpMux(jsonMarshaler, errorFormat)
+			err := s.RegisterOpenAPI(health.OpenAPI())
+			if err != nil {
+				return nil, err
+			}
+			s.grpcStreamGateway = grpcex.NewStreamGateway(s.grpcHttpMux, s.grpcChannel, s.grpcRegistrar,
+				jsonMarshaler, errorFormat)
 			s.ginEngine.NoRoute(func(c *gin.Context) {
 				c.Status(http.StatusOK) // NoRoute handlers will be set to NotFound status by default, here reset to OK.
+				if s.grpcStreamGateway.Handle(c.Writer, c.Request) {
+					return
+				}
+				grpcex.NegotiateAccept(c.Request, gatewayMIMETypes)
 				s.grpcHttpMux.ServeHTTP(c.Writer, c.Request)
 			})
 		}
 	}
-	s.healthRunner = health.NewRunner(health.WithName(s.name))
-	s.healthServer = health.NewServer(s.healthRunner)
+	var healthOptions []health.ServerOption
+	if s.grpcRegistrar != nil {
+		healthOptions = append(healthOptions, health.WithServiceLookup(func(name string) bool {
+			desc, _ := s.grpcRegistrar.QueryService(name)
+			return desc != nil
+		}))
+	}
+	s.healthServer = health.NewServer(s.healthRunner, healthOptions...)
 	return s, nil
 }
 
@@ -117,13 +154,22 @@ func (s *serviceImpl) GetGinRouter() gin.IRoutes {
 	return s.ginEngine
 }
 
-func (s *serviceImpl) AddHealthCheck(name string, check health.CheckFunc) {
-	s.healthRunner.AddCheck(name, check)
+func (s *serviceImpl) AddHealthCheck(name string, check health.CheckFunc, options ...health.CheckOption) {
+	s.healthRunner.AddCheck(name, check, options...)
+}
+
+func (s *serviceImpl) GetHealthReporter() health.Reporter {
+	return s.healthRunner
+}
+
+func (s *serviceImpl) RegisterOpenAPI(fsys fs.FS, patterns ...string) error {
+	return s.openAPI.Register(fsys, patterns...)
 }
 
 func (s *serviceImpl) Run() (err error) {
-	status := s.healthRunner.Start()
-	if status.State != health.StateHealthy {
+	s.healthRunner.Start()
+	status := s.healthRunner.ProbeStatus(health.ProbeStartup)
+	if !status.State.IsAvailable() {
 		return fmt.Errorf("init_health_check_failed(state=%v,error=%v,source=%s)",
 			status.State, status.Error, status.Source)
 	}
@@ -152,7 +198,7 @@ func (s *serviceImpl) Run() (err error) {
 			_ = health.RegisterHandlerClient(s.ctx, s.grpcHttpMux, s.grpcChannel)
 		}
 		s.waitGroup.Add(1)
-		err = serveHttpService(s.ctx, s.name, s.ginEngine, s.httpEndpoint, &s.waitGroup)
+		err = serveHttpService(s.ctx, s.name, s.ginEngine, s.httpEndpoint, nil, &s.waitGroup)
 		if err != nil {
 			return
 		}
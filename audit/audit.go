@@ -0,0 +1,267 @@
+package audit
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/frame-go/framego/auth"
+	"github.com/frame-go/framego/errors"
+	"github.com/frame-go/framego/grpcex"
+)
+
+const (
+	requestIDHeader    = "x-request-id"
+	forwardedForHeader = "x-forwarded-for"
+
+	// principalTypeTLS is the principal type of client TLS certificate
+	principalTypeTLS = "tls"
+
+	// maxBodySize is the max size of HTTP request body parsed for audit fields
+	maxBodySize = 1 << 20
+)
+
+// Auditor records audit events of selected gRPC methods and HTTP routes to sink
+type Auditor struct {
+	sink    Sink
+	methods []string
+	fields  []string
+}
+
+// New creates auditor by config and sink
+func New(config *Config, sink Sink) (*Auditor, error) {
+	for _, pattern := range config.Methods {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, errors.Wrap(err, "audit_invalid_method_pattern").With("pattern", pattern)
+		}
+	}
+	a := &Auditor{
+		sink:    sink,
+		methods: config.Methods,
+		fields:  config.Fields,
+	}
+	return a, nil
+}
+
+// Close closes sink of auditor
+func (a *Auditor) Close() error {
+	return a.sink.Close()
+}
+
+// IsAudited checks whether any of the method names is audited
+func (a *Auditor) IsAudited(methods ...string) bool {
+	if len(a.methods) == 0 {
+		return true
+	}
+	for _, pattern := range a.methods {
+		for _, method := range methods {
+			if ok, _ := path.Match(pattern, method); ok {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (a *Auditor) newGrpcEvent(ctx context.Context, method string, start time.Time) *Event {
+	event := &Event{
+		Time:         start,
+		Protocol:     ProtocolGRPC,
+		ClientIP:     grpcPeerIP(ctx),
+		ForwardedFor: strings.Join(metadata.ValueFromIncomingContext(ctx, forwardedForHeader), ", "),
+		Method:       method,
+		RequestID:    grpcex.RequestIDFromContext(ctx),
+	}
+	if event.RequestID == "" {
+		event.RequestID = grpcex.GetHeader(ctx, requestIDHeader)
+	}
+	if event.RequestID == "" {
+		event.RequestID = uuid.New().String()
+	}
+	if principal := auth.PrincipalFromContext(ctx); principal != nil && principal.Subject != "" {
+		event.Principal = principal.Subject
+		event.PrincipalType = principal.Type
+	} else if name := grpcex.GetClientCommonName(ctx); name != "" {
+		event.Principal = name
+		event.PrincipalType = principalTypeTLS
+	}
+	return event
+}
+
+// grpcPeerIP gets IP of peer connection, forwarded headers are ignored since they can be set by client
+func grpcPeerIP(ctx context.Context) string {
+	p, ok := peer.FromContext(ctx)
+	if !ok || p.Addr == nil {
+		return ""
+	}
+	addr := p.Addr.String()
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return addr
+	}
+	return host
+}
+
+func (a *Auditor) finishGrpcEvent(event *Event, err error) {
+	s := status.Convert(err)
+	event.Code = s.Code().String()
+	if err != nil {
+		event.Error = s.Message()
+	}
+	event.Latency = float64(time.Since(event.Time)) / float64(time.Millisecond)
+	a.sink.Write(event)
+}
+
+// UnaryServerInterceptor returns a unary server interceptor that records audit events.
+// It should be placed after authentication middlewares to get principal.
+func (a *Auditor) UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if !a.IsAudited(info.FullMethod) {
+			return handler(ctx, req)
+		}
+		event := a.newGrpcEvent(ctx, info.FullMethod, time.Now())
+		if msg, ok := req.(proto.Message); ok {
+			event.Fields = a.selectFields(protoToMap(msg))
+		}
+		resp, err := handler(ctx, req)
+		a.finishGrpcEvent(event, err)
+		return resp, err
+	}
+}
+
+// StreamServerInterceptor returns a stream server interceptor that records audit events when stream ends
+func (a *Auditor) StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if !a.IsAudited(info.FullMethod) {
+			return handler(srv, stream)
+		}
+		event := a.newGrpcEvent(stream.Context(), info.FullMethod, time.Now())
+		err := handler(srv, stream)
+		a.finishGrpcEvent(event, err)
+		return err
+	}
+}
+
+// GinHandler returns a gin middleware that records audit events.
+// Requests not matching any gin route are forwarded to grpc-gateway and audited as gRPC methods, so they are skipped.
+func (a *Auditor) GinHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.FullPath() == "" {
+			return
+		}
+		method := c.Request.Method + " " + c.Request.URL.Path
+		if !a.IsAudited(c.Request.URL.Path, method) {
+			return
+		}
+		event := &Event{
+			Time:         time.Now(),
+			Protocol:     ProtocolHTTP,
+			ClientIP:     c.RemoteIP(),
+			ForwardedFor: strings.Join(c.Request.Header.Values(forwardedForHeader), ", "),
+			Method:       method,
+			RequestID:    c.GetHeader(requestIDHeader),
+		}
+		if event.RequestID == "" {
+			event.RequestID = uuid.New().String()
+		}
+		if len(a.fields) > 0 {
+			event.Fields = a.selectFields(ginRequestToMap(c))
+		}
+
+		c.Next()
+
+		if principal := auth.PrincipalFromContext(c); principal != nil && principal.Subject != "" {
+			event.Principal = principal.Subject
+			event.PrincipalType = principal.Type
+		} else if tls := c.Request.TLS; tls != nil && len(tls.PeerCertificates) > 0 &&
+			tls.PeerCertificates[0].Subject.CommonName != "" {
+			event.Principal = tls.PeerCertificates[0].Subject.CommonName
+			event.PrincipalType = principalTypeTLS
+		}
+		event.Code = strconv.Itoa(c.Writer.Status())
+		if len(c.Errors) > 0 {
+			event.Error = c.Errors.Last().Error()
+		}
+		event.Latency = float64(time.Since(event.Time)) / float64(time.Millisecond)
+		a.sink.Write(event)
+	}
+}
+
+// selectFields selects configured fields from request data
+func (a *Auditor) selectFields(data map[string]interface{}) map[string]interface{} {
+	if len(a.fields) == 0 || data == nil {
+		return nil
+	}
+	fields := make(map[string]interface{}, len(a.fields))
+	for _, field := range a.fields {
+		if value, ok := lookupField(data, field); ok {
+			fields[field] = value
+		}
+	}
+	return fields
+}
+
+func lookupField(data map[string]interface{}, field string) (interface{}, bool) {
+	var value interface{} = data
+	for _, name := range strings.Split(field, ".") {
+		m, ok := value.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		value, ok = m[name]
+		if !ok {
+			return nil, false
+		}
+	}
+	return value, true
+}
+
+func protoToMap(msg proto.Message) map[string]interface{} {
+	data, err := protojson.MarshalOptions{UseProtoNames: true}.Marshal(msg)
+	if err != nil {
+		return nil
+	}
+	var m map[string]interface{}
+	if json.Unmarshal(data, &m) != nil {
+		return nil
+	}
+	return m
+}
+
+// ginRequestToMap merges fields of JSON body, query params and route params, the request body is kept for handlers
+func ginRequestToMap(c *gin.Context) map[string]interface{} {
+	m := make(map[string]interface{})
+	if c.Request.Body != nil && strings.HasPrefix(c.ContentType(), gin.MIMEJSON) {
+		body, err := io.ReadAll(io.LimitReader(c.Request.Body, maxBodySize))
+		if err == nil {
+			_ = json.Unmarshal(body, &m)
+		}
+		c.Request.Body = io.NopCloser(io.MultiReader(bytes.NewReader(body), c.Request.Body))
+	}
+	for key, values := range c.Request.URL.Query() {
+		if len(values) == 1 {
+			m[key] = values[0]
+		} else {
+			m[key] = values
+		}
+	}
+	for _, param := range c.Params {
+		m[param.Key] = param.Value
+	}
+	return m
+}
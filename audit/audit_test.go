@@ -0,0 +1,161 @@
+package audit
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/structpb"
+
+	"github.com/frame-go/framego/auth"
+)
+
+type testSink struct {
+	mu     sync.Mutex
+	events []*Event
+}
+
+func (s *testSink) Write(event *Event) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.events = append(s.events, event)
+}
+
+func (s *testSink) Close() error {
+	return nil
+}
+
+func newTestAuditor(t *testing.T, sink Sink) *Auditor {
+	a, err := New(&Config{
+		Methods: []string{"/test.Service/Update*", "POST /users/*"},
+		Fields:  []string{"name", "profile.level", "id", "missing"},
+	}, sink)
+	if err != nil {
+		t.Fatalf("new_auditor_error: %v", err)
+	}
+	return a
+}
+
+func TestUnaryServerInterceptor(t *testing.T) {
+	sink := &testSink{}
+	a := newTestAuditor(t, sink)
+	interceptor := a.UnaryServerInterceptor()
+	req, _ := structpb.NewStruct(map[string]interface{}{
+		"name":     "user1",
+		"password": "secret",
+		"profile":  map[string]interface{}{"level": 3},
+	})
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return nil, status.Error(codes.PermissionDenied, "denied")
+	}
+	ctx := auth.SetContextPrincipal(context.Background(), &auth.Principal{Type: auth.PrincipalTypeJWT, Subject: "user1"})
+	ctx = peer.NewContext(ctx, &peer.Peer{Addr: &net.TCPAddr{IP: net.ParseIP("10.0.0.1"), Port: 5000}})
+	ctx = metadata.NewIncomingContext(ctx, metadata.Pairs("x-forwarded-for", "1.2.3.4"))
+
+	_, _ = interceptor(ctx, req, &grpc.UnaryServerInfo{FullMethod: "/test.Service/UpdateUser"}, handler)
+	_, _ = interceptor(ctx, req, &grpc.UnaryServerInfo{FullMethod: "/test.Service/GetUser"}, handler)
+	if len(sink.events) != 1 {
+		t.Fatalf("unexpected_event_count: %d", len(sink.events))
+	}
+	event := sink.events[0]
+	if event.Protocol != ProtocolGRPC || event.Method != "/test.Service/UpdateUser" ||
+		event.Principal != "user1" || event.PrincipalType != auth.PrincipalTypeJWT ||
+		event.Code != codes.PermissionDenied.String() || event.Error != "denied" || event.RequestID == "" {
+		t.Errorf("unexpected_event: %+v", event)
+	}
+	if event.ClientIP != "10.0.0.1" || event.ForwardedFor != "1.2.3.4" {
+		t.Errorf("unexpected_client_ip: %s, forwarded_for: %s", event.ClientIP, event.ForwardedFor)
+	}
+	if len(event.Fields) != 2 || event.Fields["name"] != "user1" || event.Fields["profile.level"] != float64(3) {
+		t.Errorf("unexpected_fields: %v", event.Fields)
+	}
+}
+
+func TestGinHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	sink := &testSink{}
+	a := newTestAuditor(t, sink)
+	engine := gin.New()
+	engine.Use(a.GinHandler(), func(c *gin.Context) {
+		auth.SetGinContextPrincipal(c, &auth.Principal{Type: auth.PrincipalTypeAPIKey, Subject: "partner1"})
+	})
+	var body map[string]interface{}
+	engine.POST("/users/:id", func(c *gin.Context) {
+		_ = c.BindJSON(&body)
+		c.Status(http.StatusCreated)
+	})
+	engine.GET("/users/:id", func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	data, _ := json.Marshal(map[string]interface{}{"name": "user2"})
+	req := httptest.NewRequest(http.MethodPost, "/users/2", bytes.NewReader(data))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Request-Id", "req-1")
+	req.Header.Set("X-Forwarded-For", "1.2.3.4, 10.0.0.2")
+	engine.ServeHTTP(httptest.NewRecorder(), req)
+	engine.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/users/2", nil))
+
+	if body["name"] != "user2" {
+		t.Errorf("expect_body_kept_for_handler: %v", body)
+	}
+	if len(sink.events) != 1 {
+		t.Fatalf("unexpected_event_count: %d", len(sink.events))
+	}
+	event := sink.events[0]
+	if event.Protocol != ProtocolHTTP || event.Method != "POST /users/2" || event.Principal != "partner1" ||
+		event.Code != "201" || event.RequestID != "req-1" {
+		t.Errorf("unexpected_event: %+v", event)
+	}
+	// remote address of httptest request
+	if event.ClientIP != "192.0.2.1" || event.ForwardedFor != "1.2.3.4, 10.0.0.2" {
+		t.Errorf("unexpected_client_ip: %s, forwarded_for: %s", event.ClientIP, event.ForwardedFor)
+	}
+	if len(event.Fields) != 2 || event.Fields["name"] != "user2" || event.Fields["id"] != "2" {
+		t.Errorf("unexpected_fields: %v", event.Fields)
+	}
+
+	// client certificate without principal
+	engine = gin.New()
+	engine.Use(a.GinHandler())
+	engine.POST("/users/:id", func(c *gin.Context) {
+		c.Status(http.StatusCreated)
+	})
+	req = httptest.NewRequest(http.MethodPost, "/users/3", nil)
+	req.TLS = &tls.ConnectionState{PeerCertificates: []*x509.Certificate{{Subject: pkix.Name{CommonName: "service1"}}}}
+	engine.ServeHTTP(httptest.NewRecorder(), req)
+	event = sink.events[len(sink.events)-1]
+	if event.Principal != "service1" || event.PrincipalType != principalTypeTLS {
+		t.Errorf("unexpected_tls_principal: %s, %s", event.Principal, event.PrincipalType)
+	}
+}
+
+func TestWriterSink(t *testing.T) {
+	var buf bytes.Buffer
+	sink := NewWriterSink(&buf)
+	sink.Write(&Event{Protocol: ProtocolGRPC, Method: "/test.Service/UpdateUser", Code: "OK", Principal: "user1",
+		ForwardedFor: "1.2.3.4"})
+	var record map[string]interface{}
+	err := json.Unmarshal(buf.Bytes(), &record)
+	if err != nil {
+		t.Fatalf("unmarshal_audit_record_error: %v", err)
+	}
+	if record["method"] != "/test.Service/UpdateUser" || record["principal"] != "user1" || record["message"] != "audit" ||
+		record["forwarded_for"] != "1.2.3.4" {
+		t.Errorf("unexpected_audit_record: %v", record)
+	}
+}
@@ -0,0 +1,39 @@
+package audit
+
+const (
+	// SinkStdout writes audit events to stdout
+	SinkStdout = "stdout"
+
+	// SinkStderr writes audit events to stderr
+	SinkStderr = "stderr"
+
+	// SinkFile writes audit events to file
+	SinkFile = "file"
+
+	// SinkPulsar sends audit events to Pulsar topic
+	SinkPulsar = "pulsar"
+)
+
+// Config is configuration of audit middleware
+type Config struct {
+	// Sink is the destination of audit events: "stdout" (default), "stderr", "file" or "pulsar"
+	Sink string `json:"sink"`
+
+	// File is the file path to append audit events, required for "file" sink
+	File string `json:"file"`
+
+	// Pulsar is the name of Pulsar client, required for "pulsar" sink
+	Pulsar string `json:"pulsar"`
+
+	// Topic is the Pulsar topic of audit events, required for "pulsar" sink
+	Topic string `json:"topic"`
+
+	// Methods are glob patterns of audited gRPC full method names, HTTP paths or "<HTTP method> <path>".
+	// All requests are audited if empty.
+	Methods []string `json:"methods"`
+
+	// Fields are request fields recorded in audit events, nested fields are separated by ".".
+	// Field names of gRPC requests are proto field names,
+	// and field names of HTTP requests are route params, query params or fields of JSON body.
+	Fields []string `json:"fields"`
+}
@@ -0,0 +1,52 @@
+package audit
+
+import (
+	"time"
+)
+
+const (
+	// ProtocolGRPC is the protocol of audit events for gRPC methods
+	ProtocolGRPC = "grpc"
+
+	// ProtocolHTTP is the protocol of audit events for HTTP routes
+	ProtocolHTTP = "http"
+)
+
+// Event is an audit record of one request
+type Event struct {
+	// Time is the time when request started
+	Time time.Time `json:"time"`
+
+	// Protocol is ProtocolGRPC or ProtocolHTTP
+	Protocol string `json:"protocol"`
+
+	// Principal is the subject of authenticated principal, or name of client TLS certificate
+	Principal string `json:"principal,omitempty"`
+
+	// PrincipalType is the authentication type of principal, e.g. "jwt", "api_key" or "tls"
+	PrincipalType string `json:"principal_type,omitempty"`
+
+	// ClientIP is IP of peer connection, which is not affected by forwarded headers set by client
+	ClientIP string `json:"client_ip,omitempty"`
+
+	// ForwardedFor is X-Forwarded-For header of request, recorded as is since it can be set by client
+	ForwardedFor string `json:"forwarded_for,omitempty"`
+
+	// Method is gRPC full method name, or "<HTTP method> <path>" for HTTP routes
+	Method string `json:"method"`
+
+	// RequestID is ID of request
+	RequestID string `json:"request_id,omitempty"`
+
+	// Fields are selected request fields
+	Fields map[string]interface{} `json:"fields,omitempty"`
+
+	// Code is gRPC status code name, or HTTP status code
+	Code string `json:"code"`
+
+	// Error is error message if request failed
+	Error string `json:"error,omitempty"`
+
+	// Latency is processing time of request in milliseconds
+	Latency float64 `json:"latency"`
+}
@@ -0,0 +1,123 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"os"
+
+	pulsarclient "github.com/apache/pulsar-client-go/pulsar"
+	"github.com/rs/zerolog"
+
+	"github.com/frame-go/framego/errors"
+	"github.com/frame-go/framego/log"
+)
+
+// Sink is the destination of audit events
+type Sink interface {
+	// Write writes audit event, failures are logged by sink
+	Write(event *Event)
+
+	// Close flushes pending events and closes sink
+	Close() error
+}
+
+// writerSink writes audit events by a dedicated zerolog logger, isolated from application logs
+type writerSink struct {
+	logger zerolog.Logger
+	closer io.Closer
+}
+
+// NewWriterSink creates sink writing audit events as JSON lines to writer
+func NewWriterSink(w io.Writer) Sink {
+	return &writerSink{
+		logger: zerolog.New(zerolog.SyncWriter(w)),
+	}
+}
+
+// NewFileSink creates sink appending audit events as JSON lines to file
+func NewFileSink(file string) (Sink, error) {
+	f, err := os.OpenFile(file, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0640)
+	if err != nil {
+		return nil, errors.Wrap(err, "open_audit_file_error").With("file", file)
+	}
+	return &writerSink{
+		logger: zerolog.New(zerolog.SyncWriter(f)),
+		closer: f,
+	}, nil
+}
+
+func (s *writerSink) Write(event *Event) {
+	e := s.logger.Log().
+		Time("time", event.Time).
+		Str("protocol", event.Protocol).
+		Str("method", event.Method).
+		Str("code", event.Code).
+		Float64("latency", event.Latency)
+	if event.Principal != "" {
+		e = e.Str("principal", event.Principal).Str("principal_type", event.PrincipalType)
+	}
+	if event.ClientIP != "" {
+		e = e.Str("client_ip", event.ClientIP)
+	}
+	if event.ForwardedFor != "" {
+		e = e.Str("forwarded_for", event.ForwardedFor)
+	}
+	if event.RequestID != "" {
+		e = e.Str("request_id", event.RequestID)
+	}
+	if len(event.Fields) > 0 {
+		e = e.Interface("fields", event.Fields)
+	}
+	if event.Error != "" {
+		e = e.Str("error", event.Error)
+	}
+	e.Msg("audit")
+}
+
+func (s *writerSink) Close() error {
+	if s.closer == nil {
+		return nil
+	}
+	return s.closer.Close()
+}
+
+// pulsarSink sends audit events as JSON messages to Pulsar topic
+type pulsarSink struct {
+	producer pulsarclient.Producer
+	topic    string
+}
+
+// NewPulsarSink creates sink sending audit events to Pulsar topic, events are keyed by principal
+func NewPulsarSink(client pulsarclient.Client, topic string) (Sink, error) {
+	producer, err := client.CreateProducer(pulsarclient.ProducerOptions{Topic: topic})
+	if err != nil {
+		return nil, errors.Wrap(err, "create_audit_producer_error").With("topic", topic)
+	}
+	return &pulsarSink{producer: producer, topic: topic}, nil
+}
+
+func (s *pulsarSink) Write(event *Event) {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		log.Logger.Error().Err(err).Str("method", event.Method).Msg("marshal_audit_event_error")
+		return
+	}
+	msg := &pulsarclient.ProducerMessage{
+		Payload:   payload,
+		Key:       event.Principal,
+		EventTime: event.Time,
+	}
+	s.producer.SendAsync(context.Background(), msg, func(_ pulsarclient.MessageID, _ *pulsarclient.ProducerMessage, err error) {
+		if err != nil {
+			log.Logger.Error().Err(err).Str("topic", s.topic).Str("method", event.Method).
+				Str("request_id", event.RequestID).Msg("send_audit_event_error")
+		}
+	})
+}
+
+func (s *pulsarSink) Close() error {
+	err := s.producer.Flush()
+	s.producer.Close()
+	return err
+}
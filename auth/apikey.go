@@ -0,0 +1,328 @@
+package auth
+
+import (
+	"context"
+	"encoding/hex"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	grpc_middleware "github.com/grpc-ecosystem/go-grpc-middleware"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"gorm.io/gorm"
+
+	"github.com/frame-go/framego/crypto"
+	"github.com/frame-go/framego/errors"
+	"github.com/frame-go/framego/ginex"
+	"github.com/frame-go/framego/grpcex"
+)
+
+const (
+	// DefaultAPIKeyHeader is the default header or metadata name of API key
+	DefaultAPIKeyHeader = "x-api-key"
+
+	// DefaultAPIKeyTable is the default table name of API keys in database
+	DefaultAPIKeyTable = "api_keys"
+
+	// DefaultAPIKeyCacheExpiration is the default expiration of cached API keys, in seconds
+	DefaultAPIKeyCacheExpiration = 60
+
+	// DefaultAPIKeyCacheSize is the default max number of cached API keys
+	DefaultAPIKeyCacheSize = 10000
+
+	// apiKeyMissExpiration is the expiration of cached unknown API keys
+	apiKeyMissExpiration = 5 * time.Second
+
+	// apiKeyLoadTimeout is the timeout to load uncached API key from store
+	apiKeyLoadTimeout = time.Second
+)
+
+// APIKey is a hashed API key and its principal. The raw key is never stored.
+type APIKey struct {
+	// Hash is hex encoded HMAC-SHA2-256 of raw key with salt, generated by HashAPIKey
+	Hash string `json:"hash" gorm:"column:key_hash;primaryKey;size:64"`
+
+	// Principal is the subject of key for access control
+	Principal string `json:"principal" gorm:"column:principal;size:255"`
+
+	// Scopes are the space-delimited granted scopes
+	Scopes string `json:"scopes" gorm:"column:scopes;size:1024"`
+
+	// ExpiresAt is the unix timestamp in seconds when key expires, never expires if 0
+	ExpiresAt int64 `json:"expires_at" gorm:"column:expires_at"`
+
+	// Revoked marks key as revoked
+	Revoked bool `json:"revoked" gorm:"column:revoked"`
+}
+
+// APIKeyStore is storage of hashed API keys
+type APIKeyStore interface {
+	// GetAPIKey gets API key by hash, returns nil if not found
+	GetAPIKey(ctx context.Context, hash string) (*APIKey, error)
+}
+
+// APIKeyConfig is configuration of API key authentication
+type APIKeyConfig struct {
+	// Header is the HTTP header or gRPC metadata name of API key, default is DefaultAPIKeyHeader
+	Header string `json:"header"`
+
+	// Salt is the HMAC key to hash API keys
+	Salt string `json:"salt"`
+
+	// Keys are the hashed API keys in config, used if database is not configured
+	Keys []APIKey `json:"keys"`
+
+	// Database is the name of database to load API keys
+	Database string `json:"database"`
+
+	// Table is the table of API keys in database, default is DefaultAPIKeyTable
+	Table string `json:"table"`
+
+	// CacheExpiration is the expiration of cached API keys in seconds, default is DefaultAPIKeyCacheExpiration.
+	// Revocation in database takes effect after cached key expires.
+	CacheExpiration int64 `json:"cache_expiration"`
+
+	// CacheSize is the max number of cached API keys, including unknown keys, default is DefaultAPIKeyCacheSize
+	CacheSize int `json:"cache_size"`
+
+	// ExcludedMethods are glob patterns of gRPC full method names or HTTP paths which skip authentication
+	ExcludedMethods []string `json:"excluded_methods"`
+}
+
+// HashAPIKey returns hex encoded HMAC-SHA2-256 of raw API key with salt
+func HashAPIKey(key string, salt string) string {
+	return hex.EncodeToString(crypto.HmacSha2Sum256([]byte(key), []byte(salt)))
+}
+
+type staticAPIKeyStore struct {
+	keys map[string]*APIKey
+}
+
+// NewStaticAPIKeyStore creates API key store with fixed keys
+func NewStaticAPIKeyStore(keys []APIKey) APIKeyStore {
+	s := &staticAPIKeyStore{
+		keys: make(map[string]*APIKey, len(keys)),
+	}
+	for i := range keys {
+		s.keys[strings.ToLower(keys[i].Hash)] = &keys[i]
+	}
+	return s
+}
+
+func (s *staticAPIKeyStore) GetAPIKey(ctx context.Context, hash string) (*APIKey, error) {
+	return s.keys[hash], nil
+}
+
+type gormAPIKeyStore struct {
+	db    *gorm.DB
+	table string
+}
+
+// NewGormAPIKeyStore creates API key store loading keys from database table,
+// use DefaultAPIKeyTable if table is empty
+func NewGormAPIKeyStore(db *gorm.DB, table string) APIKeyStore {
+	if table == "" {
+		table = DefaultAPIKeyTable
+	}
+	return &gormAPIKeyStore{db: db, table: table}
+}
+
+func (s *gormAPIKeyStore) GetAPIKey(ctx context.Context, hash string) (*APIKey, error) {
+	var keys []*APIKey
+	err := s.db.WithContext(ctx).Table(s.table).Where("key_hash = ?", hash).Limit(1).Find(&keys).Error
+	if err != nil {
+		return nil, errors.Wrap(err, "load_api_key_error").With("table", s.table)
+	}
+	if len(keys) == 0 {
+		return nil, nil
+	}
+	return keys[0], nil
+}
+
+// apiKeyCacheEntry is cached API key, key is nil if it is unknown
+type apiKeyCacheEntry struct {
+	key       *APIKey
+	expiresAt time.Time
+}
+
+// apiKeyCache caches API keys loaded from store by hash with bounded size.
+// Unknown keys are cached for apiKeyMissExpiration, so that repeated invalid keys do not query store.
+type apiKeyCache struct {
+	store      APIKeyStore
+	expiration time.Duration
+	size       int
+	mu         sync.Mutex
+	entries    map[string]apiKeyCacheEntry
+}
+
+// get gets API key by hash from cache, or from store if not cached, returns nil if key is unknown
+func (c *apiKeyCache) get(hash string) (*APIKey, error) {
+	now := time.Now()
+	c.mu.Lock()
+	entry, ok := c.entries[hash]
+	c.mu.Unlock()
+	if ok && now.Before(entry.expiresAt) {
+		return entry.key, nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), apiKeyLoadTimeout)
+	defer cancel()
+	key, err := c.store.GetAPIKey(ctx, hash)
+	if err != nil {
+		return nil, err
+	}
+	entry = apiKeyCacheEntry{key: key, expiresAt: now.Add(c.expiration)}
+	if key == nil {
+		entry.expiresAt = now.Add(apiKeyMissExpiration)
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, ok = c.entries[hash]; !ok && len(c.entries) >= c.size {
+		c.evict(now)
+	}
+	c.entries[hash] = entry
+	return key, nil
+}
+
+// evict removes expired entries, or an arbitrary entry if none is expired
+func (c *apiKeyCache) evict(now time.Time) {
+	for hash, entry := range c.entries {
+		if !now.Before(entry.expiresAt) {
+			delete(c.entries, hash)
+		}
+	}
+	if len(c.entries) < c.size {
+		return
+	}
+	for hash := range c.entries {
+		delete(c.entries, hash)
+		return
+	}
+}
+
+// APIKeyAuthenticator verifies API keys in gRPC metadata or HTTP header
+type APIKeyAuthenticator struct {
+	header          string
+	salt            string
+	keys            *apiKeyCache
+	excludedMethods []string
+}
+
+// NewAPIKeyAuthenticator creates API key authenticator by config and key store
+func NewAPIKeyAuthenticator(config *APIKeyConfig, store APIKeyStore) (*APIKeyAuthenticator, error) {
+	if config.Salt == "" {
+		return nil, errors.New("api_key_auth_without_salt")
+	}
+	if err := checkPatterns(config.ExcludedMethods); err != nil {
+		return nil, err
+	}
+	a := &APIKeyAuthenticator{
+		header:          strings.ToLower(config.Header),
+		salt:            config.Salt,
+		excludedMethods: config.ExcludedMethods,
+	}
+	if a.header == "" {
+		a.header = DefaultAPIKeyHeader
+	}
+	expiration := config.CacheExpiration
+	if expiration <= 0 {
+		expiration = DefaultAPIKeyCacheExpiration
+	}
+	size := config.CacheSize
+	if size <= 0 {
+		size = DefaultAPIKeyCacheSize
+	}
+	a.keys = &apiKeyCache{
+		store:      store,
+		expiration: time.Duration(expiration) * time.Second,
+		size:       size,
+		entries:    make(map[string]apiKeyCacheEntry),
+	}
+	return a, nil
+}
+
+// IsExcluded checks whether the gRPC full method or HTTP path skips authentication
+func (a *APIKeyAuthenticator) IsExcluded(method string) bool {
+	return matchPatterns(a.excludedMethods, method)
+}
+
+// Verify verifies raw API key, returns principal of key.
+// Failure of key store is returned with codes.Unavailable.
+func (a *APIKeyAuthenticator) Verify(key string) (*Principal, error) {
+	if key == "" {
+		return nil, errors.New("api_key_auth_missing_key").WithGRPCCode(codes.Unauthenticated)
+	}
+	apiKey, err := a.keys.get(HashAPIKey(key, a.salt))
+	if err != nil {
+		return nil, errors.Wrap(err, "api_key_auth_load_key_error").WithGRPCCode(codes.Unavailable)
+	}
+	if apiKey == nil {
+		return nil, errors.New("api_key_auth_invalid_key").WithGRPCCode(codes.Unauthenticated)
+	}
+	if apiKey.Revoked {
+		return nil, errors.New("api_key_auth_revoked_key").With("principal", apiKey.Principal).
+			WithGRPCCode(codes.Unauthenticated)
+	}
+	if apiKey.ExpiresAt > 0 && time.Now().Unix() >= apiKey.ExpiresAt {
+		return nil, errors.New("api_key_auth_expired_key").With("principal", apiKey.Principal).
+			WithGRPCCode(codes.Unauthenticated)
+	}
+	principal := &Principal{
+		Type:    PrincipalTypeAPIKey,
+		Subject: apiKey.Principal,
+		Scopes:  strings.Fields(apiKey.Scopes),
+	}
+	return principal, nil
+}
+
+func (a *APIKeyAuthenticator) authenticate(ctx context.Context, method string) (context.Context, error) {
+	if grpcex.IsBuiltinServiceMethod(method) || a.IsExcluded(method) {
+		return ctx, nil
+	}
+	principal, err := a.Verify(grpcex.GetHeader(ctx, a.header))
+	if err != nil {
+		return ctx, err
+	}
+	return SetContextPrincipal(ctx, principal), nil
+}
+
+// UnaryServerInterceptor returns a unary server interceptor that authenticates requests by API key
+func (a *APIKeyAuthenticator) UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		ctx, err := a.authenticate(ctx, info.FullMethod)
+		if err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// StreamServerInterceptor returns a stream server interceptor that authenticates requests by API key
+func (a *APIKeyAuthenticator) StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, err := a.authenticate(stream.Context(), info.FullMethod)
+		if err != nil {
+			return err
+		}
+		wrappedStream := grpc_middleware.WrapServerStream(stream)
+		wrappedStream.WrappedContext = ctx
+		return handler(srv, wrappedStream)
+	}
+}
+
+// GinHandler returns a gin middleware that authenticates requests by API key in header
+func (a *APIKeyAuthenticator) GinHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if a.IsExcluded(c.Request.URL.Path) {
+			return
+		}
+		principal, err := a.Verify(c.GetHeader(a.header))
+		if err != nil {
+			ginex.AbortWithError(c, errors.HTTPStatusFromCode(status.Code(err)), err)
+			return
+		}
+		SetGinContextPrincipal(c, principal)
+	}
+}
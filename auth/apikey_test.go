@@ -0,0 +1,139 @@
+package auth
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/frame-go/framego/errors"
+)
+
+const testAPIKeySalt = "test-salt"
+
+func newTestAPIKeyAuthenticator(t *testing.T) *APIKeyAuthenticator {
+	a, err := NewAPIKeyAuthenticator(&APIKeyConfig{
+		Salt:            testAPIKeySalt,
+		ExcludedMethods: []string{"/test.Public/*"},
+	}, NewStaticAPIKeyStore([]APIKey{
+		{Hash: HashAPIKey("key1", testAPIKeySalt), Principal: "partner1", Scopes: "read write"},
+		{Hash: HashAPIKey("key2", testAPIKeySalt), Principal: "partner2", ExpiresAt: time.Now().Add(-time.Hour).Unix()},
+		{Hash: HashAPIKey("key3", testAPIKeySalt), Principal: "partner3", Revoked: true},
+		{Hash: HashAPIKey("key4", testAPIKeySalt), Principal: "partner4", ExpiresAt: time.Now().Add(time.Hour).Unix()},
+	}))
+	if err != nil {
+		t.Fatalf("new_authenticator_error: %v", err)
+	}
+	return a
+}
+
+func TestVerifyAPIKey(t *testing.T) {
+	a := newTestAPIKeyAuthenticator(t)
+	principal, err := a.Verify("key1")
+	if err != nil {
+		t.Fatalf("verify_error: %v", err)
+	}
+	if principal.Type != PrincipalTypeAPIKey || principal.Subject != "partner1" ||
+		len(principal.Scopes) != 2 || principal.Scopes[1] != "write" {
+		t.Errorf("unexpected_principal: %v", principal)
+	}
+	principal, err = a.Verify("key4")
+	if err != nil || principal.Subject != "partner4" {
+		t.Errorf("expect_unexpired_key_verified: %v, %v", principal, err)
+	}
+
+	for _, key := range []string{"", "unknown", "key2", "key3", HashAPIKey("key1", testAPIKeySalt)} {
+		_, err = a.Verify(key)
+		if status.Code(err) != codes.Unauthenticated {
+			t.Errorf("expect_unauthenticated: key=%s, err=%v", key, err)
+		}
+	}
+}
+
+func TestAPIKeyUnaryServerInterceptor(t *testing.T) {
+	a := newTestAPIKeyAuthenticator(t)
+	interceptor := a.UnaryServerInterceptor()
+	var subjects []string
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		subjects = SubjectsFromContext(ctx)
+		return nil, nil
+	}
+
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(DefaultAPIKeyHeader, "key1"))
+	_, err := interceptor(ctx, nil, &grpc.UnaryServerInfo{FullMethod: "/test.Private/Get"}, handler)
+	if err != nil || len(subjects) != 1 || subjects[0] != "api_key:partner1" {
+		t.Errorf("expect_authenticated: subjects=%v, err=%v", subjects, err)
+	}
+
+	// header forwarded by grpc-gateway
+	ctx = metadata.NewIncomingContext(context.Background(), metadata.Pairs("grpcgateway-"+DefaultAPIKeyHeader, "key1"))
+	_, err = interceptor(ctx, nil, &grpc.UnaryServerInfo{FullMethod: "/test.Private/Get"}, handler)
+	if err != nil {
+		t.Errorf("expect_authenticated_by_gateway_header: %v", err)
+	}
+
+	_, err = interceptor(context.Background(), nil, &grpc.UnaryServerInfo{FullMethod: "/test.Private/Get"}, handler)
+	if status.Code(err) != codes.Unauthenticated {
+		t.Errorf("expect_unauthenticated: %v", err)
+	}
+
+	_, err = interceptor(context.Background(), nil, &grpc.UnaryServerInfo{FullMethod: "/test.Public/Get"}, handler)
+	if err != nil {
+		t.Errorf("expect_excluded_method: %v", err)
+	}
+}
+
+func TestNewAPIKeyAuthenticatorWithoutSalt(t *testing.T) {
+	_, err := NewAPIKeyAuthenticator(&APIKeyConfig{}, NewStaticAPIKeyStore(nil))
+	if err == nil {
+		t.Errorf("expect_without_salt_error")
+	}
+}
+
+// countingAPIKeyStore counts lookups of keys and fails if err is set
+type countingAPIKeyStore struct {
+	APIKeyStore
+	count int
+	err   error
+}
+
+func (s *countingAPIKeyStore) GetAPIKey(ctx context.Context, hash string) (*APIKey, error) {
+	s.count++
+	if s.err != nil {
+		return nil, s.err
+	}
+	return s.APIKeyStore.GetAPIKey(ctx, hash)
+}
+
+func TestAPIKeyCache(t *testing.T) {
+	store := &countingAPIKeyStore{APIKeyStore: NewStaticAPIKeyStore([]APIKey{
+		{Hash: HashAPIKey("key1", testAPIKeySalt), Principal: "partner1"},
+	})}
+	a, err := NewAPIKeyAuthenticator(&APIKeyConfig{Salt: testAPIKeySalt, CacheSize: 2}, store)
+	if err != nil {
+		t.Fatalf("new_authenticator_error: %v", err)
+	}
+	for i := 0; i < 2; i++ {
+		_, _ = a.Verify("key1")
+		_, _ = a.Verify("unknown")
+	}
+	if store.count != 2 {
+		t.Errorf("expect_known_and_unknown_keys_cached: count=%d", store.count)
+	}
+	for _, key := range []string{"unknown1", "unknown2", "unknown3"} {
+		_, _ = a.Verify(key)
+	}
+	if len(a.keys.entries) > 2 {
+		t.Errorf("cache size %v > 2", len(a.keys.entries))
+	}
+
+	store.err = errors.New("store_error")
+	_, err = a.Verify("unknown4")
+	if status.Code(err) != codes.Unavailable {
+		t.Errorf("expect_unavailable: %v", err)
+	}
+}
@@ -0,0 +1,137 @@
+package auth
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-jose/go-jose/v4/jwt"
+)
+
+const contextClaimsKey = "_jwt_claims"
+
+type IContextGetter interface {
+	Value(key interface{}) interface{}
+}
+
+// Claims is verified JWT claims
+type Claims struct {
+	registered jwt.Claims
+	raw        map[string]interface{}
+}
+
+// Subject returns "sub" claim
+func (c *Claims) Subject() string {
+	return c.registered.Subject
+}
+
+// Issuer returns "iss" claim
+func (c *Claims) Issuer() string {
+	return c.registered.Issuer
+}
+
+// Audience returns "aud" claim
+func (c *Claims) Audience() []string {
+	return c.registered.Audience
+}
+
+// ID returns "jti" claim
+func (c *Claims) ID() string {
+	return c.registered.ID
+}
+
+// ExpiresAt returns "exp" claim, returns zero time if not exists
+func (c *Claims) ExpiresAt() time.Time {
+	if c.registered.Expiry == nil {
+		return time.Time{}
+	}
+	return c.registered.Expiry.Time()
+}
+
+// IssuedAt returns "iat" claim, returns zero time if not exists
+func (c *Claims) IssuedAt() time.Time {
+	if c.registered.IssuedAt == nil {
+		return time.Time{}
+	}
+	return c.registered.IssuedAt.Time()
+}
+
+// Scopes returns scopes in space-delimited "scope" claim or "scp" list claim
+func (c *Claims) Scopes() []string {
+	scope, ok := c.GetString("scope")
+	if ok {
+		return strings.Fields(scope)
+	}
+	scopes, _ := c.GetStrings("scp")
+	return scopes
+}
+
+// Get returns raw value of claim
+func (c *Claims) Get(name string) (interface{}, bool) {
+	v, ok := c.raw[name]
+	return v, ok
+}
+
+// GetString returns string value of claim
+func (c *Claims) GetString(name string) (string, bool) {
+	v, ok := c.raw[name].(string)
+	return v, ok
+}
+
+// GetStrings returns string list value of claim, a single string value is converted to list
+func (c *Claims) GetStrings(name string) ([]string, bool) {
+	switch v := c.raw[name].(type) {
+	case string:
+		return []string{v}, true
+	case []interface{}:
+		values := make([]string, 0, len(v))
+		for _, item := range v {
+			s, ok := item.(string)
+			if !ok {
+				return nil, false
+			}
+			values = append(values, s)
+		}
+		return values, true
+	}
+	return nil, false
+}
+
+// GetInt64 returns integer value of claim
+func (c *Claims) GetInt64(name string) (int64, bool) {
+	v, ok := c.raw[name].(float64)
+	if !ok {
+		return 0, false
+	}
+	return int64(v), true
+}
+
+// GetBool returns bool value of claim
+func (c *Claims) GetBool(name string) (bool, bool) {
+	v, ok := c.raw[name].(bool)
+	return v, ok
+}
+
+// ClaimsFromContext gets verified JWT claims from context, returns nil if not exists
+func ClaimsFromContext(ctx IContextGetter) *Claims {
+	v := ctx.Value(contextClaimsKey)
+	if v == nil {
+		return nil
+	}
+	claims, ok := v.(*Claims)
+	if !ok {
+		return nil
+	}
+	return claims
+}
+
+// SetContextClaims puts JWT claims into context
+func SetContextClaims(ctx context.Context, claims *Claims) context.Context {
+	return context.WithValue(ctx, contextClaimsKey, claims)
+}
+
+// SetGinContextClaims puts JWT claims into gin context
+func SetGinContextClaims(ctx *gin.Context, claims *Claims) {
+	ctx.Set(contextClaimsKey, claims)
+}
@@ -0,0 +1,27 @@
+package auth
+
+import (
+	"path"
+
+	"github.com/frame-go/framego/errors"
+)
+
+// checkPatterns checks whether glob patterns of excluded methods are valid
+func checkPatterns(patterns []string) error {
+	for _, pattern := range patterns {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return errors.Wrap(err, "invalid_excluded_method").With("pattern", pattern)
+		}
+	}
+	return nil
+}
+
+// matchPatterns checks whether the gRPC full method or HTTP path matches any of glob patterns
+func matchPatterns(patterns []string, method string) bool {
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(pattern, method); ok {
+			return true
+		}
+	}
+	return false
+}
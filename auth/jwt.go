@@ -0,0 +1,254 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"os"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-jose/go-jose/v4"
+	"github.com/go-jose/go-jose/v4/jwt"
+	grpc_middleware "github.com/grpc-ecosystem/go-grpc-middleware"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+
+	"github.com/frame-go/framego/datacache"
+	"github.com/frame-go/framego/errors"
+	"github.com/frame-go/framego/ginex"
+	"github.com/frame-go/framego/grpcex"
+	"github.com/frame-go/framego/log"
+)
+
+const (
+	// DefaultJWKSReloadInterval is the interval to reload JWKS file if not configured, in seconds
+	DefaultJWKSReloadInterval = 60
+
+	bearerPrefix = "Bearer "
+)
+
+// JWTConfig is configuration of JWT authentication
+type JWTConfig struct {
+	// HS256Key is the secret key to verify HS256 tokens
+	HS256Key string `json:"hs256_key"`
+
+	// JWKSFile is the path of local JWKS file to verify RS256/ES256 tokens
+	JWKSFile string `json:"jwks_file"`
+
+	// JWKSReloadInterval is the interval to reload JWKS file in seconds, default is DefaultJWKSReloadInterval
+	JWKSReloadInterval int64 `json:"jwks_reload_interval"`
+
+	// Issuer is the expected "iss" claim, not checked if empty
+	Issuer string `json:"issuer"`
+
+	// Audiences are the accepted "aud" claims, token should contain any of them. Not checked if empty
+	Audiences []string `json:"audiences"`
+
+	// Leeway is the allowed clock skew in seconds for checking "exp" and "nbf" claims
+	Leeway int64 `json:"leeway"`
+
+	// ExcludedMethods are glob patterns of gRPC full method names or HTTP paths which skip authentication
+	ExcludedMethods []string `json:"excluded_methods"`
+}
+
+// JWTAuthenticator verifies JWT bearer tokens in gRPC metadata or HTTP header
+type JWTAuthenticator struct {
+	hs256Key        []byte
+	jwks            *datacache.DataCache[*jose.JSONWebKeySet]
+	closed          atomic.Bool
+	algorithms      []jose.SignatureAlgorithm
+	expected        jwt.Expected
+	leeway          time.Duration
+	excludedMethods []string
+}
+
+// NewJWTAuthenticator creates JWT authenticator by config
+func NewJWTAuthenticator(config *JWTConfig) (*JWTAuthenticator, error) {
+	a := &JWTAuthenticator{
+		algorithms: make([]jose.SignatureAlgorithm, 0, 3),
+		expected: jwt.Expected{
+			Issuer:      config.Issuer,
+			AnyAudience: config.Audiences,
+		},
+		leeway:          time.Duration(config.Leeway) * time.Second,
+		excludedMethods: config.ExcludedMethods,
+	}
+	if err := checkPatterns(a.excludedMethods); err != nil {
+		return nil, err
+	}
+	if config.HS256Key != "" {
+		a.hs256Key = []byte(config.HS256Key)
+		a.algorithms = append(a.algorithms, jose.HS256)
+	}
+	if config.JWKSFile != "" {
+		jwks, err := loadJWKS(config.JWKSFile)
+		if err != nil {
+			return nil, err
+		}
+		interval := config.JWKSReloadInterval
+		if interval <= 0 {
+			interval = DefaultJWKSReloadInterval
+		}
+		a.jwks = datacache.NewDataCache(&datacache.CacheInitOption[*jose.JSONWebKeySet]{
+			WithInitData: true,
+			InitData:     jwks,
+			LoadData: func() (*jose.JSONWebKeySet, error) {
+				if a.closed.Load() {
+					// keep loaded keys
+					return nil, errors.New("jwt_auth_closed")
+				}
+				jwks, err := loadJWKS(config.JWKSFile)
+				if err != nil {
+					errors.LogError(log.Logger.Error(), err).Msg("jwt_auth_reload_jwks_error")
+				}
+				return jwks, err
+			},
+			Expiration:    interval * 1000,
+			RetryInterval: interval * 1000,
+		})
+		a.algorithms = append(a.algorithms, jose.RS256, jose.ES256)
+	}
+	if len(a.algorithms) == 0 {
+		return nil, errors.New("jwt_auth_no_verification_key")
+	}
+	return a, nil
+}
+
+func loadJWKS(file string) (*jose.JSONWebKeySet, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, errors.Wrap(err, "read_jwks_file_error").With("file", file)
+	}
+	jwks := &jose.JSONWebKeySet{}
+	err = json.Unmarshal(data, jwks)
+	if err != nil {
+		return nil, errors.Wrap(err, "parse_jwks_file_error").With("file", file)
+	}
+	return jwks, nil
+}
+
+// Close stops reloading JWKS file, loaded keys are still used to verify tokens
+func (a *JWTAuthenticator) Close() {
+	a.closed.Store(true)
+}
+
+// IsExcluded checks whether the gRPC full method or HTTP path skips authentication
+func (a *JWTAuthenticator) IsExcluded(method string) bool {
+	return matchPatterns(a.excludedMethods, method)
+}
+
+// Verify verifies token signature and claims, returns verified claims
+func (a *JWTAuthenticator) Verify(token string) (*Claims, error) {
+	tok, err := jwt.ParseSigned(token, a.algorithms)
+	if err != nil {
+		return nil, errors.Wrap(err, "jwt_auth_invalid_token").WithGRPCCode(codes.Unauthenticated)
+	}
+	claims := &Claims{}
+	err = errors.New("jwt_auth_key_not_found")
+	for _, key := range a.verificationKeys(tok.Headers[0]) {
+		err = tok.Claims(key, &claims.registered, &claims.raw)
+		if err == nil {
+			break
+		}
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "jwt_auth_invalid_signature").WithGRPCCode(codes.Unauthenticated)
+	}
+	if claims.registered.Expiry == nil {
+		return nil, errors.New("jwt_auth_missing_exp").WithGRPCCode(codes.Unauthenticated)
+	}
+	err = claims.registered.ValidateWithLeeway(a.expected.WithTime(time.Now()), a.leeway)
+	if err != nil {
+		return nil, errors.Wrap(err, "jwt_auth_invalid_claims").WithGRPCCode(codes.Unauthenticated)
+	}
+	return claims, nil
+}
+
+// verificationKeys returns candidate keys for token header
+func (a *JWTAuthenticator) verificationKeys(header jose.Header) []interface{} {
+	if header.Algorithm == string(jose.HS256) {
+		return []interface{}{a.hs256Key}
+	}
+	if a.jwks == nil {
+		return nil
+	}
+	jwks := a.jwks.Get()
+	var keys []jose.JSONWebKey
+	if header.KeyID != "" {
+		keys = jwks.Key(header.KeyID)
+	} else {
+		keys = jwks.Keys
+	}
+	candidates := make([]interface{}, 0, len(keys))
+	for _, key := range keys {
+		if key.Algorithm == "" || key.Algorithm == header.Algorithm {
+			candidates = append(candidates, key.Key)
+		}
+	}
+	return candidates
+}
+
+func (a *JWTAuthenticator) authenticate(ctx context.Context, method string) (context.Context, error) {
+	if grpcex.IsBuiltinServiceMethod(method) || a.IsExcluded(method) {
+		return ctx, nil
+	}
+	token := grpcex.GetAuthToken(ctx)
+	if token == "" {
+		return ctx, errors.New("jwt_auth_missing_token").WithGRPCCode(codes.Unauthenticated)
+	}
+	claims, err := a.Verify(token)
+	if err != nil {
+		return ctx, err
+	}
+	ctx = SetContextClaims(ctx, claims)
+	return SetContextPrincipal(ctx, newJWTPrincipal(claims)), nil
+}
+
+// UnaryServerInterceptor returns a unary server interceptor that authenticates requests by JWT
+func (a *JWTAuthenticator) UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		ctx, err := a.authenticate(ctx, info.FullMethod)
+		if err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// StreamServerInterceptor returns a stream server interceptor that authenticates requests by JWT
+func (a *JWTAuthenticator) StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, err := a.authenticate(stream.Context(), info.FullMethod)
+		if err != nil {
+			return err
+		}
+		wrappedStream := grpc_middleware.WrapServerStream(stream)
+		wrappedStream.WrappedContext = ctx
+		return handler(srv, wrappedStream)
+	}
+}
+
+// GinHandler returns a gin middleware that authenticates requests by JWT in Authorization header
+func (a *JWTAuthenticator) GinHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if a.IsExcluded(c.Request.URL.Path) {
+			return
+		}
+		auth := c.GetHeader("Authorization")
+		if !strings.HasPrefix(auth, bearerPrefix) {
+			err := errors.New("jwt_auth_missing_token").WithGRPCCode(codes.Unauthenticated)
+			ginex.AbortWithError(c, http.StatusUnauthorized, err)
+			return
+		}
+		claims, err := a.Verify(auth[len(bearerPrefix):])
+		if err != nil {
+			ginex.AbortWithError(c, http.StatusUnauthorized, err)
+			return
+		}
+		SetGinContextClaims(c, claims)
+		SetGinContextPrincipal(c, newJWTPrincipal(claims))
+	}
+}
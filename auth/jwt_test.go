@@ -0,0 +1,212 @@
+package auth
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-jose/go-jose/v4"
+	"github.com/go-jose/go-jose/v4/jwt"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/frame-go/framego/log"
+)
+
+const testHS256Key = "test-secret-key-with-enough-length"
+
+func TestMain(m *testing.M) {
+	log.Init("fatal", false, false)
+	os.Exit(m.Run())
+}
+
+type testClaims struct {
+	jwt.Claims
+	Scope string `json:"scope,omitempty"`
+	Level int    `json:"level,omitempty"`
+}
+
+func signToken(t *testing.T, alg jose.SignatureAlgorithm, key interface{}, kid string, claims interface{}) string {
+	opts := (&jose.SignerOptions{}).WithType("JWT")
+	if kid != "" {
+		opts = opts.WithHeader("kid", kid)
+	}
+	signer, err := jose.NewSigner(jose.SigningKey{Algorithm: alg, Key: key}, opts)
+	if err != nil {
+		t.Fatalf("new_signer_error: %v", err)
+	}
+	token, err := jwt.Signed(signer).Claims(claims).Serialize()
+	if err != nil {
+		t.Fatalf("sign_token_error: %v", err)
+	}
+	return token
+}
+
+func newClaims(subject string, expiry time.Time) testClaims {
+	return testClaims{
+		Claims: jwt.Claims{
+			Subject:  subject,
+			Issuer:   "test-issuer",
+			Audience: jwt.Audience{"test-audience"},
+			Expiry:   jwt.NewNumericDate(expiry),
+		},
+		Scope: "read write",
+		Level: 3,
+	}
+}
+
+func writeJWKS(t *testing.T, keys ...jose.JSONWebKey) string {
+	data, err := json.Marshal(jose.JSONWebKeySet{Keys: keys})
+	if err != nil {
+		t.Fatalf("marshal_jwks_error: %v", err)
+	}
+	file := filepath.Join(t.TempDir(), "jwks.json")
+	err = os.WriteFile(file, data, 0600)
+	if err != nil {
+		t.Fatalf("write_jwks_error: %v", err)
+	}
+	return file
+}
+
+func TestVerifyHS256(t *testing.T) {
+	a, err := NewJWTAuthenticator(&JWTConfig{
+		HS256Key:  testHS256Key,
+		Issuer:    "test-issuer",
+		Audiences: []string{"test-audience", "other-audience"},
+	})
+	if err != nil {
+		t.Fatalf("new_authenticator_error: %v", err)
+	}
+
+	token := signToken(t, jose.HS256, []byte(testHS256Key), "", newClaims("user1", time.Now().Add(time.Hour)))
+	claims, err := a.Verify(token)
+	if err != nil {
+		t.Fatalf("verify_error: %v", err)
+	}
+	if claims.Subject() != "user1" {
+		t.Errorf("unexpected_subject: %s", claims.Subject())
+	}
+	if scopes := claims.Scopes(); len(scopes) != 2 || scopes[0] != "read" || scopes[1] != "write" {
+		t.Errorf("unexpected_scopes: %v", scopes)
+	}
+	if level, ok := claims.GetInt64("level"); !ok || level != 3 {
+		t.Errorf("unexpected_level: %v", level)
+	}
+
+	var tests = []struct {
+		name  string
+		token string
+	}{
+		{"expired", signToken(t, jose.HS256, []byte(testHS256Key), "", newClaims("user1", time.Now().Add(-time.Hour)))},
+		{"wrong_key", signToken(t, jose.HS256, []byte("wrong-secret-key-with-enough-length"), "", newClaims("user1", time.Now().Add(time.Hour)))},
+		{"no_exp", signToken(t, jose.HS256, []byte(testHS256Key), "", jwt.Claims{Subject: "user1", Issuer: "test-issuer", Audience: jwt.Audience{"test-audience"}})},
+		{"wrong_issuer", signToken(t, jose.HS256, []byte(testHS256Key), "", jwt.Claims{Issuer: "other", Audience: jwt.Audience{"test-audience"}, Expiry: jwt.NewNumericDate(time.Now().Add(time.Hour))})},
+		{"wrong_audience", signToken(t, jose.HS256, []byte(testHS256Key), "", jwt.Claims{Issuer: "test-issuer", Audience: jwt.Audience{"other"}, Expiry: jwt.NewNumericDate(time.Now().Add(time.Hour))})},
+		{"not_before", signToken(t, jose.HS256, []byte(testHS256Key), "", jwt.Claims{Issuer: "test-issuer", Audience: jwt.Audience{"test-audience"}, Expiry: jwt.NewNumericDate(time.Now().Add(time.Hour)), NotBefore: jwt.NewNumericDate(time.Now().Add(time.Minute))})},
+		{"malformed", "not-a-token"},
+	}
+	for _, test := range tests {
+		_, err = a.Verify(test.token)
+		if err == nil {
+			t.Errorf("expect_verify_error: %s", test.name)
+		} else if status.Code(err) != codes.Unauthenticated {
+			t.Errorf("unexpected_error_code: %s, %v", test.name, err)
+		}
+	}
+}
+
+func TestVerifyJWKS(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generate_rsa_key_error: %v", err)
+	}
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("generate_ec_key_error: %v", err)
+	}
+	file := writeJWKS(t,
+		jose.JSONWebKey{Key: rsaKey.Public(), KeyID: "rsa1", Algorithm: string(jose.RS256), Use: "sig"},
+		jose.JSONWebKey{Key: ecKey.Public(), KeyID: "ec1", Algorithm: string(jose.ES256), Use: "sig"},
+	)
+	a, err := NewJWTAuthenticator(&JWTConfig{JWKSFile: file})
+	if err != nil {
+		t.Fatalf("new_authenticator_error: %v", err)
+	}
+
+	claims := newClaims("user2", time.Now().Add(time.Hour))
+	for _, token := range []string{
+		signToken(t, jose.RS256, rsaKey, "rsa1", claims),
+		signToken(t, jose.ES256, ecKey, "ec1", claims),
+		signToken(t, jose.ES256, ecKey, "", claims),
+	} {
+		verified, err := a.Verify(token)
+		if err != nil {
+			t.Errorf("verify_error: %v", err)
+		} else if verified.Subject() != "user2" {
+			t.Errorf("unexpected_subject: %s", verified.Subject())
+		}
+	}
+
+	_, err = a.Verify(signToken(t, jose.RS256, rsaKey, "unknown", claims))
+	if err == nil {
+		t.Errorf("expect_unknown_kid_error")
+	}
+	_, err = a.Verify(signToken(t, jose.HS256, []byte(testHS256Key), "", claims))
+	if err == nil {
+		t.Errorf("expect_unsupported_algorithm_error")
+	}
+
+	a.Close()
+	_, err = a.Verify(signToken(t, jose.RS256, rsaKey, "rsa1", claims))
+	if err != nil {
+		t.Errorf("expect_loaded_keys_used_after_close: %v", err)
+	}
+}
+
+func TestUnaryServerInterceptor(t *testing.T) {
+	a, err := NewJWTAuthenticator(&JWTConfig{
+		HS256Key:        testHS256Key,
+		ExcludedMethods: []string{"/test.Public/*"},
+	})
+	if err != nil {
+		t.Fatalf("new_authenticator_error: %v", err)
+	}
+	interceptor := a.UnaryServerInterceptor()
+	var subject string
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		subject = ""
+		if claims := ClaimsFromContext(ctx); claims != nil {
+			subject = claims.Subject()
+		}
+		if subjects := SubjectsFromContext(ctx); subject != "" && (len(subjects) != 1 || subjects[0] != "jwt:"+subject) {
+			t.Errorf("unexpected_principal_subjects: %v", subjects)
+		}
+		return nil, nil
+	}
+
+	token := signToken(t, jose.HS256, []byte(testHS256Key), "", newClaims("user3", time.Now().Add(time.Hour)))
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", "Bearer "+token))
+	_, err = interceptor(ctx, nil, &grpc.UnaryServerInfo{FullMethod: "/test.Private/Get"}, handler)
+	if err != nil || subject != "user3" {
+		t.Errorf("expect_authenticated: subject=%s, err=%v", subject, err)
+	}
+
+	_, err = interceptor(context.Background(), nil, &grpc.UnaryServerInfo{FullMethod: "/test.Private/Get"}, handler)
+	if status.Code(err) != codes.Unauthenticated {
+		t.Errorf("expect_unauthenticated: %v", err)
+	}
+
+	_, err = interceptor(context.Background(), nil, &grpc.UnaryServerInfo{FullMethod: "/test.Public/Get"}, handler)
+	if err != nil {
+		t.Errorf("expect_excluded_method: %v", err)
+	}
+}
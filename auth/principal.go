@@ -0,0 +1,71 @@
+package auth
+
+import (
+	"context"
+
+	"github.com/gin-gonic/gin"
+)
+
+const contextPrincipalKey = "_principal"
+
+const (
+	// PrincipalTypeJWT is the type of principal authenticated by JWT
+	PrincipalTypeJWT = "jwt"
+
+	// PrincipalTypeAPIKey is the type of principal authenticated by API key
+	PrincipalTypeAPIKey = "api_key"
+)
+
+// Principal is the authenticated identity of request
+type Principal struct {
+	// Type is the authentication type, e.g. PrincipalTypeJWT
+	Type string
+
+	// Subject is the identity used for access control
+	Subject string
+
+	// Scopes are the granted scopes of principal
+	Scopes []string
+}
+
+// PrincipalFromContext gets authenticated principal from context, returns nil if not exists
+func PrincipalFromContext(ctx IContextGetter) *Principal {
+	v := ctx.Value(contextPrincipalKey)
+	if v == nil {
+		return nil
+	}
+	principal, ok := v.(*Principal)
+	if !ok {
+		return nil
+	}
+	return principal
+}
+
+// SetContextPrincipal puts authenticated principal into context
+func SetContextPrincipal(ctx context.Context, principal *Principal) context.Context {
+	return context.WithValue(ctx, contextPrincipalKey, principal)
+}
+
+// SetGinContextPrincipal puts authenticated principal into gin context
+func SetGinContextPrincipal(ctx *gin.Context, principal *Principal) {
+	ctx.Set(contextPrincipalKey, principal)
+}
+
+// SubjectsFromContext returns subjects of authenticated principal in context for access control.
+// Subjects are prefixed by principal type, e.g. "jwt:alice" or "api_key:reporter", so that they never match
+// names of TLS client certificates or "<anonymous>" in policies.
+func SubjectsFromContext(ctx context.Context) []string {
+	principal := PrincipalFromContext(ctx)
+	if principal == nil || principal.Subject == "" {
+		return nil
+	}
+	return []string{principal.Type + ":" + principal.Subject}
+}
+
+func newJWTPrincipal(claims *Claims) *Principal {
+	return &Principal{
+		Type:    PrincipalTypeJWT,
+		Subject: claims.Subject(),
+		Scopes:  claims.Scopes(),
+	}
+}
@@ -0,0 +1,59 @@
+package auth
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"google.golang.org/grpc"
+
+	"github.com/frame-go/framego/grpcex"
+)
+
+func TestSubjectsNotMatchingServicePolicies(t *testing.T) {
+	policy := filepath.Join(t.TempDir(), "acl.csv")
+	err := os.WriteFile(policy, []byte(`
+p, ServiceA, Method1
+p, jwt:alice, Method2
+p2, ServiceA, /v1/*, GET
+p2, api_key:alice, /v2/*, GET
+`), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, err := grpcex.NewAccessController("", policy, grpcex.WithSubjectsFunc(SubjectsFromContext))
+	if err != nil {
+		t.Fatalf("new_access_controller_error: %v", err)
+	}
+	defer c.Close()
+	interceptor := c.UnaryServerInterceptor()
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return nil, nil
+	}
+
+	var tests = []struct {
+		principal *Principal
+		method    string
+		path      string
+		allowed   bool
+	}{
+		{&Principal{Type: PrincipalTypeJWT, Subject: "ServiceA"}, "/test.Service/Method1", "", false},
+		{&Principal{Type: PrincipalTypeJWT, Subject: "alice"}, "/test.Service/Method2", "", true},
+		{&Principal{Type: PrincipalTypeAPIKey, Subject: "alice"}, "/test.Service/Method2", "", false},
+		{&Principal{Type: PrincipalTypeJWT, Subject: "ServiceA"}, "", "/v1/users", false},
+		{&Principal{Type: PrincipalTypeAPIKey, Subject: "alice"}, "", "/v2/users", true},
+		{&Principal{Type: PrincipalTypeJWT, Subject: "alice"}, "", "/v2/users", false},
+	}
+	for _, test := range tests {
+		ctx := SetContextPrincipal(context.Background(), test.principal)
+		if test.method != "" {
+			_, err = interceptor(ctx, nil, &grpc.UnaryServerInfo{FullMethod: test.method}, handler)
+		} else {
+			err = c.CheckRouteAccess(ctx, test.path, "GET")
+		}
+		if (err == nil) != test.allowed {
+			t.Errorf("unexpected_access: %+v %s%s, err=%v", test.principal, test.method, test.path, err)
+		}
+	}
+}
@@ -1,5 +1,9 @@
 package cache
 
+import (
+	"github.com/frame-go/framego/health"
+)
+
 type Config struct {
 	// Name of the client.
 	Name string
@@ -18,4 +22,7 @@ type Config struct {
 
 	// Database to be selected after connecting to the server.
 	DB uint32
+
+	// HealthCheck sends PING command to server
+	HealthCheck health.CheckConfig `json:"health_check" mapstructure:"health_check"`
 }
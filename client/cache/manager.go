@@ -5,14 +5,21 @@ import (
 	"strings"
 
 	"github.com/rs/zerolog"
+
+	"github.com/frame-go/framego/health"
+	"github.com/frame-go/framego/metrics"
 )
 
 type ClientManager interface {
 	GetClient(name string) Client
+
+	// HealthCheck returns health check of client, returns nil if client is not found
+	HealthCheck(name string) health.CheckFunc
 }
 
 type options struct {
-	logger *zerolog.Logger
+	logger  *zerolog.Logger
+	metrics *metrics.Registry
 }
 
 type Option func(*options)
@@ -23,6 +30,13 @@ func WithLogger(logger *zerolog.Logger) Option {
 	}
 }
 
+// WithMetrics records metrics of commands and connection pools in registry, labelled by client name
+func WithMetrics(registry *metrics.Registry) Option {
+	return func(o *options) {
+		o.metrics = registry
+	}
+}
+
 type clientManagerImpl struct {
 	configs []Config
 	opts    []Option
@@ -57,3 +71,11 @@ func NewClientManager(configs []Config, opts ...Option) (ClientManager, error) {
 func (c *clientManagerImpl) GetClient(name string) Client {
 	return c.clients[name]
 }
+
+func (c *clientManagerImpl) HealthCheck(name string) health.CheckFunc {
+	checker, ok := c.clients[name].(health.Checker)
+	if !ok {
+		return nil
+	}
+	return checker.HealthCheck
+}
@@ -0,0 +1,109 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	redis "github.com/redis/go-redis/v9"
+
+	"github.com/frame-go/framego/metrics"
+)
+
+// redisCommandBuckets are buckets of redis command latency in seconds
+var redisCommandBuckets = []float64{.0005, .001, .0025, .005, .01, .025, .05, .1, .25, .5, 1}
+
+// redisMetricsHook is redis hook recording command latency and errors by command name.
+// Pipelines are recorded as command "pipeline".
+type redisMetricsHook struct {
+	name     string
+	duration *prometheus.HistogramVec
+	errors   *prometheus.CounterVec
+}
+
+func newRedisMetricsHook(registry *metrics.Registry, name string) *redisMetricsHook {
+	return &redisMetricsHook{
+		name: name,
+		duration: registry.Histogram("redis_client_command_duration_seconds", "Latency of redis commands.",
+			redisCommandBuckets, "client", "command"),
+		errors: registry.Counter("redis_client_command_errors_total", "Total number of redis command errors.",
+			"client", "command"),
+	}
+}
+
+func (h *redisMetricsHook) DialHook(next redis.DialHook) redis.DialHook {
+	return next
+}
+
+func (h *redisMetricsHook) ProcessHook(next redis.ProcessHook) redis.ProcessHook {
+	return func(ctx context.Context, cmd redis.Cmder) error {
+		start := time.Now()
+		err := next(ctx, cmd)
+		h.observe(cmd.Name(), start, err)
+		return err
+	}
+}
+
+func (h *redisMetricsHook) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return func(ctx context.Context, cmds []redis.Cmder) error {
+		start := time.Now()
+		err := next(ctx, cmds)
+		h.observe("pipeline", start, err)
+		return err
+	}
+}
+
+func (h *redisMetricsHook) observe(command string, start time.Time, err error) {
+	h.duration.WithLabelValues(h.name, command).Observe(time.Since(start).Seconds())
+	if err != nil && !errors.Is(err, redis.Nil) {
+		h.errors.WithLabelValues(h.name, command).Inc()
+	}
+}
+
+// poolCollector collects stats of connection pool of redis client
+type poolCollector struct {
+	client *redis.Client
+
+	hits       *prometheus.Desc
+	misses     *prometheus.Desc
+	timeouts   *prometheus.Desc
+	total      *prometheus.Desc
+	idle       *prometheus.Desc
+	staleConns *prometheus.Desc
+}
+
+func newPoolCollector(registry *metrics.Registry, name string, client *redis.Client) *poolCollector {
+	labels := prometheus.Labels{"client": name}
+	newDesc := func(metric string, help string) *prometheus.Desc {
+		return prometheus.NewDesc(registry.Name(metric), help, nil, labels)
+	}
+	return &poolCollector{
+		client:     client,
+		hits:       newDesc("redis_client_pool_hits_total", "Total number of times free connection was found in pool."),
+		misses:     newDesc("redis_client_pool_misses_total", "Total number of times free connection was not found in pool."),
+		timeouts:   newDesc("redis_client_pool_timeouts_total", "Total number of times a wait timeout occurred."),
+		total:      newDesc("redis_client_pool_connections", "Number of connections in pool."),
+		idle:       newDesc("redis_client_pool_idle_connections", "Number of idle connections in pool."),
+		staleConns: newDesc("redis_client_pool_stale_connections_total", "Total number of stale connections removed from pool."),
+	}
+}
+
+func (c *poolCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.hits
+	ch <- c.misses
+	ch <- c.timeouts
+	ch <- c.total
+	ch <- c.idle
+	ch <- c.staleConns
+}
+
+func (c *poolCollector) Collect(ch chan<- prometheus.Metric) {
+	stats := c.client.PoolStats()
+	ch <- prometheus.MustNewConstMetric(c.hits, prometheus.CounterValue, float64(stats.Hits))
+	ch <- prometheus.MustNewConstMetric(c.misses, prometheus.CounterValue, float64(stats.Misses))
+	ch <- prometheus.MustNewConstMetric(c.timeouts, prometheus.CounterValue, float64(stats.Timeouts))
+	ch <- prometheus.MustNewConstMetric(c.total, prometheus.GaugeValue, float64(stats.TotalConns))
+	ch <- prometheus.MustNewConstMetric(c.idle, prometheus.GaugeValue, float64(stats.IdleConns))
+	ch <- prometheus.MustNewConstMetric(c.staleConns, prometheus.CounterValue, float64(stats.StaleConns))
+}
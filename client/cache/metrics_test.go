@@ -0,0 +1,64 @@
+package cache
+
+import (
+	"context"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	redis "github.com/redis/go-redis/v9"
+
+	"github.com/frame-go/framego/errors"
+	"github.com/frame-go/framego/metrics"
+)
+
+func TestRedisMetricsHook(t *testing.T) {
+	registry := metrics.NewRegistry(&metrics.Options{})
+	hook := newRedisMetricsHook(registry, "main")
+	ctx := context.Background()
+
+	process := hook.ProcessHook(func(ctx context.Context, cmd redis.Cmder) error {
+		return cmd.Err()
+	})
+	_ = process(ctx, redis.NewStringCmd(ctx, "get", "key"))
+	missCmd := redis.NewStringCmd(ctx, "get", "missing")
+	missCmd.SetErr(redis.Nil)
+	_ = process(ctx, missCmd)
+	errCmd := redis.NewStatusCmd(ctx, "set", "key", "value")
+	errCmd.SetErr(errors.New("connection_error"))
+	_ = process(ctx, errCmd)
+
+	pipeline := hook.ProcessPipelineHook(func(ctx context.Context, cmds []redis.Cmder) error {
+		return nil
+	})
+	_ = pipeline(ctx, []redis.Cmder{redis.NewStringCmd(ctx, "get", "key")})
+
+	count, _, err := registry.HistogramValue("redis_client_command_duration_seconds",
+		prometheus.Labels{"client": "main", "command": "get"})
+	assertError(t, err, "get duration")
+	assertCondition(t, count == 2, "get count %v != 2", count)
+	count, _, err = registry.HistogramValue("redis_client_command_duration_seconds",
+		prometheus.Labels{"client": "main", "command": "pipeline"})
+	assertError(t, err, "get pipeline duration")
+	assertCondition(t, count == 1, "pipeline count %v != 1", count)
+
+	errorsTotal, err := registry.Value("redis_client_command_errors_total", prometheus.Labels{"client": "main"})
+	assertError(t, err, "get errors")
+	assertCondition(t, errorsTotal == 1, "errors %v != 1", errorsTotal)
+	errorsTotal, err = registry.Value("redis_client_command_errors_total", prometheus.Labels{"command": "set"})
+	assertError(t, err, "get set errors")
+	assertCondition(t, errorsTotal == 1, "set errors %v != 1", errorsTotal)
+}
+
+func TestRedisPoolCollector(t *testing.T) {
+	registry := metrics.NewRegistry(&metrics.Options{})
+	client := redis.NewClient(&redis.Options{Addr: "127.0.0.1:6379", PoolSize: 5})
+	defer client.Close()
+	err := registry.Register(newPoolCollector(registry, "main", client))
+	assertError(t, err, "register collector")
+	err = registry.Register(newPoolCollector(registry, "main", client))
+	assertCondition(t, err != nil, "duplicated client name should not be registered")
+
+	value, err := registry.Value("redis_client_pool_connections", prometheus.Labels{"client": "main"})
+	assertError(t, err, "get connections")
+	assertCondition(t, value == 0, "connections %v != 0", value)
+}
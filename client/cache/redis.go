@@ -45,12 +45,33 @@ func NewRedisClient(config *Config, opts ...Option) (Client, error) {
 		ConnMaxLifetime:       connMaxLifeTime,
 	}
 	c := redis.NewClient(option)
+	var clientOpts options
+	for _, opt := range opts {
+		opt(&clientOpts)
+	}
+	if clientOpts.metrics != nil {
+		c.AddHook(newRedisMetricsHook(clientOpts.metrics, config.Name))
+		err := clientOpts.metrics.Register(newPoolCollector(clientOpts.metrics, config.Name, c))
+		if err != nil {
+			_ = c.Close()
+			return nil, err
+		}
+	}
 	client := &redisClient{
 		client: c,
 	}
 	return client, nil
 }
 
+// HealthCheck sends PING command to server
+func (c *redisClient) HealthCheck(ctx context.Context) error {
+	err := c.client.Ping(ctx).Err()
+	if err != nil {
+		return errors.Wrap(err, "redis_ping_error")
+	}
+	return nil
+}
+
 func (c *redisClient) GetRawClient() any {
 	return c.client
 }
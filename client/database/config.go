@@ -1,5 +1,9 @@
 package database
 
+import (
+	"github.com/frame-go/framego/health"
+)
+
 type Config struct {
 	Name     string   `json:"name"`
 	Database string   `json:"database"`
@@ -7,4 +11,7 @@ type Config struct {
 	Password string   `json:"password"`
 	Masters  []string `json:"masters"`
 	Slaves   []string `json:"slaves"`
+
+	// HealthCheck pings masters and slaves of database, only errors of masters fail the check
+	HealthCheck health.CheckConfig `json:"health_check" mapstructure:"health_check"`
 }
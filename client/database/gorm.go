@@ -1,6 +1,8 @@
 package database
 
 import (
+	"context"
+	"database/sql"
 	"fmt"
 	"time"
 
@@ -20,6 +22,7 @@ type GormClient struct {
 	config  *Config
 	options options
 	db      *gorm.DB
+	health  *poolHealth
 }
 
 func (c *GormClient) init(config *Config, opts ...Option) error {
@@ -47,33 +50,71 @@ func (c *GormClient) init(config *Config, opts ...Option) error {
 	if err != nil {
 		return err
 	}
-	err = db.Use(
-		dbresolver.Register(
-			dbresolver.Config{
-				Sources:  sources,
-				Replicas: replicas,
-				Policy:   dbresolver.RandomPolicy{},
-			}).
-			SetConnMaxIdleTime(gormConnMaxIdle).
-			SetConnMaxLifetime(gormConnMaxLife).
-			SetMaxIdleConns(gormMaxIdleConns).
-			SetMaxOpenConns(gormMaxOpenConns))
+	resolver := dbresolver.Register(
+		dbresolver.Config{
+			Sources:  sources,
+			Replicas: replicas,
+			Policy:   dbresolver.RandomPolicy{},
+		}).
+		SetConnMaxIdleTime(gormConnMaxIdle).
+		SetConnMaxLifetime(gormConnMaxLife).
+		SetMaxIdleConns(gormMaxIdleConns).
+		SetMaxOpenConns(gormMaxOpenConns)
+	err = db.Use(resolver)
 	if err != nil {
 		return err
 	}
+	// pools of sources are called before replicas
+	var pools []*sql.DB
+	_ = resolver.Call(func(pool gorm.ConnPool) error {
+		if sqlDB, ok := pool.(*sql.DB); ok {
+			pools = append(pools, sqlDB)
+		}
+		return nil
+	})
+	masterCount := min(len(sources), len(pools))
+	c.health = newPoolHealth(c.config.Name, &c.options, pools[:masterCount], pools[masterCount:])
+	if c.options.metrics != nil {
+		err = c.initMetrics(db, pools)
+		if err != nil {
+			return err
+		}
+	}
 	c.db = db
 	return nil
 }
 
+func (c *GormClient) initMetrics(db *gorm.DB, pools []*sql.DB) error {
+	err := db.Use(newGormMetricsPlugin(c.options.metrics, c.config.Name))
+	if err != nil {
+		return err
+	}
+	return c.options.metrics.Register(newPoolCollector(c.options.metrics, c.config.Name, driverGorm,
+		func() []*sql.DB { return pools }))
+}
+
 func (c *GormClient) DB() *gorm.DB {
 	return c.db
 }
 
-func NewGormClient(config *Config, opts ...Option) (*gorm.DB, error) {
-	c := GormClient{}
+// HealthCheck pings masters and slaves, only errors of masters are returned
+func (c *GormClient) HealthCheck(ctx context.Context) error {
+	return c.health.HealthCheck(ctx)
+}
+
+func newGormClient(config *Config, opts ...Option) (*GormClient, error) {
+	c := &GormClient{}
 	err := c.init(config, opts...)
 	if err != nil {
 		return nil, err
 	}
+	return c, nil
+}
+
+func NewGormClient(config *Config, opts ...Option) (*gorm.DB, error) {
+	c, err := newGormClient(config, opts...)
+	if err != nil {
+		return nil, err
+	}
 	return c.DB(), nil
 }
@@ -0,0 +1,79 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"sync"
+
+	"github.com/frame-go/framego/errors"
+)
+
+// poolHealth pings connection pools of masters and slaves
+type poolHealth struct {
+	name    string
+	options *options
+	masters []*sql.DB
+	slaves  []*sql.DB
+
+	lock      sync.Mutex
+	slaveDown []bool
+}
+
+func newPoolHealth(name string, options *options, masters []*sql.DB, slaves []*sql.DB) *poolHealth {
+	return &poolHealth{
+		name:      name,
+		options:   options,
+		masters:   masters,
+		slaves:    slaves,
+		slaveDown: make([]bool, len(slaves)),
+	}
+}
+
+// HealthCheck pings all masters and slaves concurrently. Error of any master is returned,
+// while errors of slaves are only logged when slave goes down or recovers, since queries can use other replicas.
+func (h *poolHealth) HealthCheck(ctx context.Context) error {
+	masterErrs := make([]error, len(h.masters))
+	slaveErrs := make([]error, len(h.slaves))
+	wg := &sync.WaitGroup{}
+	ping := func(db *sql.DB, err *error) {
+		defer wg.Done()
+		*err = db.PingContext(ctx)
+	}
+	for i, db := range h.masters {
+		wg.Add(1)
+		go ping(db, &masterErrs[i])
+	}
+	for i, db := range h.slaves {
+		wg.Add(1)
+		go ping(db, &slaveErrs[i])
+	}
+	wg.Wait()
+
+	h.trackSlaves(slaveErrs)
+	for i, err := range masterErrs {
+		if err != nil {
+			return errors.Wrap(err, "database_master_ping_error").With("client", h.name).With("master", i)
+		}
+	}
+	return nil
+}
+
+func (h *poolHealth) trackSlaves(errs []error) {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	for i, err := range errs {
+		down := err != nil
+		if down == h.slaveDown[i] {
+			continue
+		}
+		h.slaveDown[i] = down
+		if h.options.logger == nil {
+			continue
+		}
+		if down {
+			h.options.logger.Warn().Err(err).Str("client", h.name).Int("slave", i).Msg("database_slave_ping_error")
+		} else {
+			h.options.logger.Info().Str("client", h.name).Int("slave", i).Msg("database_slave_recovered")
+		}
+	}
+}
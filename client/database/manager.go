@@ -4,16 +4,23 @@ import (
 	"github.com/linxGnu/mssqlx"
 	"github.com/rs/zerolog"
 	"gorm.io/gorm"
+
+	"github.com/frame-go/framego/health"
+	"github.com/frame-go/framego/metrics"
 )
 
 type ClientManager interface {
 	GetClient(string) *gorm.DB
 	GetGormClient(string) *gorm.DB
 	GetSqlxClient(string) *mssqlx.DBs
+
+	// HealthCheck returns health check of gorm client, returns nil if client is not found
+	HealthCheck(string) health.CheckFunc
 }
 
 type options struct {
-	logger *zerolog.Logger
+	logger  *zerolog.Logger
+	metrics *metrics.Registry
 }
 
 type Option func(*options)
@@ -24,11 +31,19 @@ func WithLogger(logger *zerolog.Logger) Option {
 	}
 }
 
+// WithMetrics records metrics of connection pools and queries in registry, labelled by client name
+func WithMetrics(registry *metrics.Registry) Option {
+	return func(o *options) {
+		o.metrics = registry
+	}
+}
+
 type clientManagerImpl struct {
-	configs     []Config
-	opts        []Option
-	gormClients map[string]*gorm.DB
-	sqlxClients map[string]*mssqlx.DBs
+	configs      []Config
+	opts         []Option
+	gormClients  map[string]*gorm.DB
+	sqlxClients  map[string]*mssqlx.DBs
+	healthChecks map[string]health.CheckFunc
 }
 
 func (c *clientManagerImpl) GetClient(name string) *gorm.DB {
@@ -63,14 +78,20 @@ func (c *clientManagerImpl) GetSqlxClient(name string) *mssqlx.DBs {
 	return client
 }
 
+func (c *clientManagerImpl) HealthCheck(name string) health.CheckFunc {
+	return c.healthChecks[name]
+}
+
 func (c *clientManagerImpl) initGormClients() error {
 	c.gormClients = make(map[string]*gorm.DB)
+	c.healthChecks = make(map[string]health.CheckFunc)
 	for _, config := range c.configs {
-		db, err := NewGormClient(&config, c.opts...)
+		client, err := newGormClient(&config, c.opts...)
 		if err != nil {
 			return err
 		}
-		c.gormClients[config.Name] = db
+		c.gormClients[config.Name] = client.DB()
+		c.healthChecks[config.Name] = client.HealthCheck
 	}
 	return nil
 }
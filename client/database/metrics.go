@@ -0,0 +1,215 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	sqldblogger "github.com/simukti/sqldb-logger"
+	"gorm.io/gorm"
+
+	"github.com/frame-go/framego/metrics"
+)
+
+const (
+	driverGorm = "gorm"
+	driverSqlx = "sqlx"
+
+	gormMetricsStartKey = "framego:metrics_start"
+)
+
+// poolCollector collects stats of connection pools of database client, stats of all sources and replicas are summed
+type poolCollector struct {
+	pools func() []*sql.DB
+
+	maxOpen           *prometheus.Desc
+	open              *prometheus.Desc
+	inUse             *prometheus.Desc
+	idle              *prometheus.Desc
+	waitCount         *prometheus.Desc
+	waitDuration      *prometheus.Desc
+	maxIdleClosed     *prometheus.Desc
+	maxIdleTimeClosed *prometheus.Desc
+	maxLifetimeClosed *prometheus.Desc
+}
+
+func newPoolCollector(registry *metrics.Registry, name string, driver string, pools func() []*sql.DB) *poolCollector {
+	labels := prometheus.Labels{"client": name, "driver": driver}
+	newDesc := func(metric string, help string) *prometheus.Desc {
+		return prometheus.NewDesc(registry.Name(metric), help, nil, labels)
+	}
+	return &poolCollector{
+		pools:             pools,
+		maxOpen:           newDesc("db_client_max_open_connections", "Maximum number of open connections to database."),
+		open:              newDesc("db_client_open_connections", "Number of established connections both in use and idle."),
+		inUse:             newDesc("db_client_in_use_connections", "Number of connections currently in use."),
+		idle:              newDesc("db_client_idle_connections", "Number of idle connections."),
+		waitCount:         newDesc("db_client_wait_count_total", "Total number of connections waited for."),
+		waitDuration:      newDesc("db_client_wait_duration_seconds_total", "Total time blocked waiting for a new connection."),
+		maxIdleClosed:     newDesc("db_client_max_idle_closed_total", "Total number of connections closed due to max idle connections."),
+		maxIdleTimeClosed: newDesc("db_client_max_idle_time_closed_total", "Total number of connections closed due to max idle time."),
+		maxLifetimeClosed: newDesc("db_client_max_lifetime_closed_total", "Total number of connections closed due to max lifetime."),
+	}
+}
+
+func (c *poolCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.maxOpen
+	ch <- c.open
+	ch <- c.inUse
+	ch <- c.idle
+	ch <- c.waitCount
+	ch <- c.waitDuration
+	ch <- c.maxIdleClosed
+	ch <- c.maxIdleTimeClosed
+	ch <- c.maxLifetimeClosed
+}
+
+func (c *poolCollector) Collect(ch chan<- prometheus.Metric) {
+	var stats sql.DBStats
+	for _, db := range c.pools() {
+		s := db.Stats()
+		stats.MaxOpenConnections += s.MaxOpenConnections
+		stats.OpenConnections += s.OpenConnections
+		stats.InUse += s.InUse
+		stats.Idle += s.Idle
+		stats.WaitCount += s.WaitCount
+		stats.WaitDuration += s.WaitDuration
+		stats.MaxIdleClosed += s.MaxIdleClosed
+		stats.MaxIdleTimeClosed += s.MaxIdleTimeClosed
+		stats.MaxLifetimeClosed += s.MaxLifetimeClosed
+	}
+	ch <- prometheus.MustNewConstMetric(c.maxOpen, prometheus.GaugeValue, float64(stats.MaxOpenConnections))
+	ch <- prometheus.MustNewConstMetric(c.open, prometheus.GaugeValue, float64(stats.OpenConnections))
+	ch <- prometheus.MustNewConstMetric(c.inUse, prometheus.GaugeValue, float64(stats.InUse))
+	ch <- prometheus.MustNewConstMetric(c.idle, prometheus.GaugeValue, float64(stats.Idle))
+	ch <- prometheus.MustNewConstMetric(c.waitCount, prometheus.CounterValue, float64(stats.WaitCount))
+	ch <- prometheus.MustNewConstMetric(c.waitDuration, prometheus.CounterValue, stats.WaitDuration.Seconds())
+	ch <- prometheus.MustNewConstMetric(c.maxIdleClosed, prometheus.CounterValue, float64(stats.MaxIdleClosed))
+	ch <- prometheus.MustNewConstMetric(c.maxIdleTimeClosed, prometheus.CounterValue, float64(stats.MaxIdleTimeClosed))
+	ch <- prometheus.MustNewConstMetric(c.maxLifetimeClosed, prometheus.CounterValue, float64(stats.MaxLifetimeClosed))
+}
+
+// newQueryMetrics creates metrics of query latency and errors by operation shared by gorm and sqlx clients
+func newQueryMetrics(registry *metrics.Registry) (*prometheus.HistogramVec, *prometheus.CounterVec) {
+	return registry.Histogram("db_client_query_duration_seconds", "Latency of database queries.", nil, "client", "operation"),
+		registry.Counter("db_client_query_errors_total", "Total number of database query errors.", "client", "operation")
+}
+
+// gormMetricsPlugin is gorm plugin recording query latency and errors by operation
+type gormMetricsPlugin struct {
+	name     string
+	duration *prometheus.HistogramVec
+	errors   *prometheus.CounterVec
+}
+
+func newGormMetricsPlugin(registry *metrics.Registry, name string) *gormMetricsPlugin {
+	duration, queryErrors := newQueryMetrics(registry)
+	return &gormMetricsPlugin{
+		name:     name,
+		duration: duration,
+		errors:   queryErrors,
+	}
+}
+
+func (p *gormMetricsPlugin) Name() string {
+	return "framego:metrics"
+}
+
+func (p *gormMetricsPlugin) Initialize(db *gorm.DB) error {
+	callback := db.Callback()
+	errs := []error{
+		callback.Create().Before("gorm:create").Register("framego:metrics_before_create", p.before),
+		callback.Create().After("gorm:create").Register("framego:metrics_after_create", p.after("create")),
+		callback.Query().Before("gorm:query").Register("framego:metrics_before_query", p.before),
+		callback.Query().After("gorm:query").Register("framego:metrics_after_query", p.after("query")),
+		callback.Update().Before("gorm:update").Register("framego:metrics_before_update", p.before),
+		callback.Update().After("gorm:update").Register("framego:metrics_after_update", p.after("update")),
+		callback.Delete().Before("gorm:delete").Register("framego:metrics_before_delete", p.before),
+		callback.Delete().After("gorm:delete").Register("framego:metrics_after_delete", p.after("delete")),
+		callback.Row().Before("gorm:row").Register("framego:metrics_before_row", p.before),
+		callback.Row().After("gorm:row").Register("framego:metrics_after_row", p.after("row")),
+		callback.Raw().Before("gorm:raw").Register("framego:metrics_before_raw", p.before),
+		callback.Raw().After("gorm:raw").Register("framego:metrics_after_raw", p.after("raw")),
+	}
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *gormMetricsPlugin) before(db *gorm.DB) {
+	db.InstanceSet(gormMetricsStartKey, time.Now())
+}
+
+func (p *gormMetricsPlugin) after(operation string) func(*gorm.DB) {
+	return func(db *gorm.DB) {
+		v, ok := db.InstanceGet(gormMetricsStartKey)
+		if !ok {
+			return
+		}
+		start, ok := v.(time.Time)
+		if !ok {
+			return
+		}
+		p.duration.WithLabelValues(p.name, operation).Observe(time.Since(start).Seconds())
+		if db.Error != nil && !errors.Is(db.Error, gorm.ErrRecordNotFound) {
+			p.errors.WithLabelValues(p.name, operation).Inc()
+		}
+	}
+}
+
+// sqlxOperations maps driver calls logged by sqldb-logger to operations of query metrics
+var sqlxOperations = map[string]string{
+	"Query":            "query",
+	"QueryContext":     "query",
+	"StmtQuery":        "query",
+	"StmtQueryContext": "query",
+	"Exec":             "exec",
+	"ExecContext":      "exec",
+	"StmtExec":         "exec",
+	"StmtExecContext":  "exec",
+	"Prepare":          "prepare",
+	"PrepareContext":   "prepare",
+	"Begin":            "begin",
+	"BeginTx":          "begin",
+	"Commit":           "commit",
+	"Rollback":         "rollback",
+}
+
+// sqlxMetricsLogger is sqldb-logger logger recording query latency and errors by operation,
+// then passing the log to next logger if it is not nil
+type sqlxMetricsLogger struct {
+	name     string
+	next     sqldblogger.Logger
+	duration *prometheus.HistogramVec
+	errors   *prometheus.CounterVec
+}
+
+func newSqlxMetricsLogger(registry *metrics.Registry, name string, next sqldblogger.Logger) *sqlxMetricsLogger {
+	duration, queryErrors := newQueryMetrics(registry)
+	return &sqlxMetricsLogger{
+		name:     name,
+		next:     next,
+		duration: duration,
+		errors:   queryErrors,
+	}
+}
+
+func (l *sqlxMetricsLogger) Log(ctx context.Context, level sqldblogger.Level, msg string, data map[string]interface{}) {
+	if operation, ok := sqlxOperations[msg]; ok {
+		// duration is logged in milliseconds by default
+		if duration, ok := data["duration"].(float64); ok {
+			l.duration.WithLabelValues(l.name, operation).Observe(duration / 1000)
+		}
+		if level == sqldblogger.LevelError {
+			l.errors.WithLabelValues(l.name, operation).Inc()
+		}
+	}
+	if l.next != nil {
+		l.next.Log(ctx, level, msg, data)
+	}
+}
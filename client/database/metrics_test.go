@@ -0,0 +1,81 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"math"
+	"testing"
+
+	_ "github.com/go-sql-driver/mysql"
+	"github.com/prometheus/client_golang/prometheus"
+	sqldblogger "github.com/simukti/sqldb-logger"
+
+	"github.com/frame-go/framego/metrics"
+)
+
+func TestPoolCollector(t *testing.T) {
+	var pools []*sql.DB
+	for i := 0; i < 2; i++ {
+		// connections are not established until used
+		db, err := sql.Open("mysql", "user:password@tcp(127.0.0.1:3306)/test")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer db.Close()
+		db.SetMaxOpenConns(10)
+		pools = append(pools, db)
+	}
+
+	registry := metrics.NewRegistry(&metrics.Options{Namespace: "test"})
+	collector := newPoolCollector(registry, "main", driverSqlx, func() []*sql.DB { return pools })
+	if err := registry.Register(collector); err != nil {
+		t.Fatal(err)
+	}
+
+	value, err := registry.Value("db_client_max_open_connections", prometheus.Labels{"client": "main", "driver": "sqlx"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if value != 20 {
+		t.Errorf("max open connections %v != 20", value)
+	}
+	value, err = registry.Value("db_client_open_connections", prometheus.Labels{"client": "main"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if value != 0 {
+		t.Errorf("open connections %v != 0", value)
+	}
+}
+
+func TestSqlxMetricsLogger(t *testing.T) {
+	registry := metrics.NewRegistry(&metrics.Options{Namespace: "test"})
+	logger := newSqlxMetricsLogger(registry, "main", nil)
+	ctx := context.Background()
+	logger.Log(ctx, sqldblogger.LevelInfo, "QueryContext", map[string]interface{}{"duration": 20.0})
+	logger.Log(ctx, sqldblogger.LevelInfo, "StmtQueryContext", map[string]interface{}{"duration": 10.0})
+	logger.Log(ctx, sqldblogger.LevelError, "ExecContext", map[string]interface{}{"duration": 1.0, "error": "failed"})
+	logger.Log(ctx, sqldblogger.LevelTrace, "RowsNext", map[string]interface{}{"duration": 1.0})
+
+	count, sum, err := registry.HistogramValue("db_client_query_duration_seconds", prometheus.Labels{"client": "main", "operation": "query"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != 2 || math.Abs(sum-0.03) > 1e-9 {
+		t.Errorf("query duration count %v sum %v != 2, 0.03", count, sum)
+	}
+	count, _, err = registry.HistogramValue("db_client_query_duration_seconds", prometheus.Labels{"client": "main"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != 3 {
+		t.Errorf("duration count of all operations %v != 3", count)
+	}
+	value, err := registry.Value("db_client_query_errors_total", prometheus.Labels{"client": "main", "operation": "exec"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if value != 1 {
+		t.Errorf("exec errors %v != 1", value)
+	}
+}
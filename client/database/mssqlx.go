@@ -1,11 +1,13 @@
 package database
 
 import (
+	"context"
 	"errors"
 	"fmt"
 
 	"database/sql"
 	"github.com/go-sql-driver/mysql"
+	"github.com/jmoiron/sqlx"
 	"github.com/linxGnu/mssqlx"
 	sqldblogger "github.com/simukti/sqldb-logger"
 	"github.com/simukti/sqldb-logger/logadapter/zerologadapter"
@@ -17,18 +19,22 @@ type MssqlxClient struct {
 	config  *Config
 	options options
 	db      *mssqlx.DBs
+	health  *poolHealth
 }
 
 func (c *MssqlxClient) mysqlInstantiate(driverName, dsn string) (*sql.DB, error) {
 	if driverName != "mysql" {
 		return nil, errors.New("only_supported_mysql")
 	}
+	var logger sqldblogger.Logger
+	if c.options.logger != nil {
+		logger = zerologadapter.New(*c.options.logger)
+	}
+	if c.options.metrics != nil {
+		logger = newSqlxMetricsLogger(c.options.metrics, c.config.Name, logger)
+	}
 	driver := &mysql.MySQLDriver{}
-	db := sqldblogger.OpenDriver(
-		dsn,
-		driver,
-		zerologadapter.New(*c.options.logger),
-	)
+	db := sqldblogger.OpenDriver(dsn, driver, logger)
 	return db, nil
 }
 
@@ -47,7 +53,7 @@ func (c *MssqlxClient) init(config *Config, opts ...Option) error {
 		slaveDsns[i] = fmt.Sprintf(tpl, address)
 	}
 	var sqlOptions []mssqlx.Option
-	if c.options.logger != nil {
+	if c.options.logger != nil || c.options.metrics != nil {
 		sqlOptions = append(sqlOptions, mssqlx.WithDBInstantiate(c.mysqlInstantiate))
 	}
 	db, errs := mssqlx.ConnectMasterSlaves("mysql", masterDsns, slaveDsns, sqlOptions...)
@@ -56,6 +62,18 @@ func (c *MssqlxClient) init(config *Config, opts ...Option) error {
 			return e
 		}
 	}
+	masters := sqlDBs(db.GetAllMasters())
+	slaves := sqlDBs(db.GetAllSlaves())
+	c.health = newPoolHealth(c.config.Name, &c.options, masters, slaves)
+	if c.options.metrics != nil {
+		pools := func() []*sql.DB {
+			return append(append([]*sql.DB{}, masters...), slaves...)
+		}
+		err := c.options.metrics.Register(newPoolCollector(c.options.metrics, c.config.Name, driverSqlx, pools))
+		if err != nil {
+			return err
+		}
+	}
 	c.db = db
 	return nil
 }
@@ -64,6 +82,11 @@ func (c *MssqlxClient) DB() *mssqlx.DBs {
 	return c.db
 }
 
+// HealthCheck pings masters and slaves, only errors of masters are returned
+func (c *MssqlxClient) HealthCheck(ctx context.Context) error {
+	return c.health.HealthCheck(ctx)
+}
+
 func NewSqlxClient(config *Config, opts ...Option) (*mssqlx.DBs, error) {
 	c := MssqlxClient{}
 	err := c.init(config, opts...)
@@ -72,3 +95,11 @@ func NewSqlxClient(config *Config, opts ...Option) (*mssqlx.DBs, error) {
 	}
 	return c.DB(), nil
 }
+
+func sqlDBs(dbs []*sqlx.DB, _ int) []*sql.DB {
+	result := make([]*sql.DB, len(dbs))
+	for i, db := range dbs {
+		result[i] = db.DB
+	}
+	return result
+}
@@ -18,5 +18,18 @@ func NewClient(config *Config, opts ...Option) (pulsarclient.Client, error) {
 	if clientOpts.logger != nil {
 		pulsarOpts.Logger = NewLoggerWithZerolog(clientOpts.logger)
 	}
+	if clientOpts.metrics != nil {
+		pulsarOpts.MetricsRegisterer = clientOpts.metrics.PrefixedRegisterer()
+		pulsarOpts.CustomMetricsLabels = map[string]string{"client": config.Name}
+		if len(config.BacklogTopics) > 0 {
+			collector, err := newBacklogCollector(clientOpts.metrics, config, clientOpts.logger)
+			if err != nil {
+				return nil, err
+			}
+			if err = clientOpts.metrics.Register(collector); err != nil {
+				return nil, err
+			}
+		}
+	}
 	return pulsarclient.NewClient(pulsarOpts)
 }
@@ -1,7 +1,20 @@
 package pulsar
 
+import (
+	"github.com/frame-go/framego/health"
+)
+
 type Config struct {
 	Name  string `json:"name"`
 	URL   string `json:"url"`
 	Token string `json:"token"`
+
+	// AdminURL is HTTP URL of pulsar admin API, e.g. "http://127.0.0.1:8080", required by BacklogTopics
+	AdminURL string `json:"admin_url" mapstructure:"admin_url"`
+
+	// BacklogTopics are topics of which backlog of subscriptions are exported if metrics are enabled
+	BacklogTopics []string `json:"backlog_topics" mapstructure:"backlog_topics"`
+
+	// HealthCheck dials brokers in service URL, the check passes if any broker is reachable
+	HealthCheck health.CheckConfig `json:"health_check" mapstructure:"health_check"`
 }
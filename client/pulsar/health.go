@@ -0,0 +1,63 @@
+package pulsar
+
+import (
+	"context"
+	"net"
+	"strings"
+
+	"github.com/frame-go/framego/errors"
+)
+
+var defaultBrokerPorts = map[string]string{
+	"pulsar":     "6650",
+	"pulsar+ssl": "6651",
+	"http":       "80",
+	"https":      "443",
+}
+
+// brokerHealth checks whether brokers in service URL are reachable
+type brokerHealth struct {
+	addresses []string
+}
+
+// newBrokerHealth parses broker addresses from service URL, e.g. "pulsar://host1:6650,host2:6650"
+func newBrokerHealth(url string) (*brokerHealth, error) {
+	scheme, hosts, ok := strings.Cut(url, "://")
+	if !ok {
+		return nil, errors.New("invalid_pulsar_url").With("url", url)
+	}
+	hosts, _, _ = strings.Cut(hosts, "/")
+	h := &brokerHealth{}
+	for _, host := range strings.Split(hosts, ",") {
+		if host == "" {
+			continue
+		}
+		if _, _, err := net.SplitHostPort(host); err != nil {
+			port, ok := defaultBrokerPorts[scheme]
+			if !ok {
+				return nil, errors.New("invalid_pulsar_url_scheme").With("url", url)
+			}
+			host = net.JoinHostPort(host, port)
+		}
+		h.addresses = append(h.addresses, host)
+	}
+	if len(h.addresses) == 0 {
+		return nil, errors.New("invalid_pulsar_url").With("url", url)
+	}
+	return h, nil
+}
+
+// HealthCheck dials brokers in order, returns nil once any broker is reachable
+func (h *brokerHealth) HealthCheck(ctx context.Context) error {
+	dialer := &net.Dialer{}
+	var err error
+	for _, address := range h.addresses {
+		var conn net.Conn
+		conn, err = dialer.DialContext(ctx, "tcp", address)
+		if err == nil {
+			_ = conn.Close()
+			return nil
+		}
+	}
+	return errors.Wrap(err, "pulsar_broker_dial_error").With("addresses", h.addresses)
+}
@@ -0,0 +1,51 @@
+package pulsar
+
+import (
+	"context"
+	"net"
+	"reflect"
+	"testing"
+)
+
+func TestNewBrokerHealth(t *testing.T) {
+	cases := map[string][]string{
+		"pulsar://localhost":                   {"localhost:6650"},
+		"pulsar+ssl://broker1:6651,broker2/":   {"broker1:6651", "broker2:6651"},
+		"http://localhost:8080/admin":          {"localhost:8080"},
+		"pulsar://10.0.0.1:6650,10.0.0.2:6650": {"10.0.0.1:6650", "10.0.0.2:6650"},
+	}
+	for url, expected := range cases {
+		h, err := newBrokerHealth(url)
+		if err != nil {
+			t.Errorf("parse %s error: %v", url, err)
+			continue
+		}
+		if !reflect.DeepEqual(h.addresses, expected) {
+			t.Errorf("addresses of %s: %v != %v", url, h.addresses, expected)
+		}
+	}
+	for _, url := range []string{"localhost:6650", "pulsar://", "unknown://localhost"} {
+		if _, err := newBrokerHealth(url); err == nil {
+			t.Errorf("parse %s should fail", url)
+		}
+	}
+}
+
+func TestBrokerHealthCheck(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	address := listener.Addr().String()
+	h, err := newBrokerHealth("pulsar://" + address)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = h.HealthCheck(context.Background()); err != nil {
+		t.Errorf("check should pass: %v", err)
+	}
+	_ = listener.Close()
+	if err = h.HealthCheck(context.Background()); err == nil {
+		t.Errorf("check should fail after broker is closed")
+	}
+}
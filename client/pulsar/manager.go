@@ -3,14 +3,21 @@ package pulsar
 import (
 	pulsarclient "github.com/apache/pulsar-client-go/pulsar"
 	"github.com/rs/zerolog"
+
+	"github.com/frame-go/framego/health"
+	"github.com/frame-go/framego/metrics"
 )
 
 type ClientManager interface {
 	GetClient(string) pulsarclient.Client
+
+	// HealthCheck returns health check of client, returns nil if client is not found
+	HealthCheck(string) health.CheckFunc
 }
 
 type options struct {
-	logger *zerolog.Logger
+	logger  *zerolog.Logger
+	metrics *metrics.Registry
 }
 
 type Option func(*options)
@@ -21,21 +28,34 @@ func WithLogger(logger *zerolog.Logger) Option {
 	}
 }
 
+// WithMetrics exports pulsar client metrics of producers and consumers in registry, labelled by client name
+func WithMetrics(registry *metrics.Registry) Option {
+	return func(o *options) {
+		o.metrics = registry
+	}
+}
+
 type clientManagerImpl struct {
-	configs []Config
-	opts    []Option
-	clients map[string]pulsarclient.Client
+	configs      []Config
+	opts         []Option
+	clients      map[string]pulsarclient.Client
+	healthChecks map[string]health.CheckFunc
 }
 
 func (c *clientManagerImpl) GetClient(name string) pulsarclient.Client {
 	return c.clients[name]
 }
 
+func (c *clientManagerImpl) HealthCheck(name string) health.CheckFunc {
+	return c.healthChecks[name]
+}
+
 func NewClientManager(configs []Config, opts ...Option) (ClientManager, error) {
 	c := &clientManagerImpl{
-		configs: configs,
-		opts:    opts,
-		clients: make(map[string]pulsarclient.Client),
+		configs:      configs,
+		opts:         opts,
+		clients:      make(map[string]pulsarclient.Client),
+		healthChecks: make(map[string]health.CheckFunc),
 	}
 	for _, config := range c.configs {
 		client, err := NewClient(&config, c.opts...)
@@ -43,6 +63,11 @@ func NewClientManager(configs []Config, opts ...Option) (ClientManager, error) {
 			return nil, err
 		}
 		c.clients[config.Name] = client
+		brokers, err := newBrokerHealth(config.URL)
+		if err != nil {
+			return nil, err
+		}
+		c.healthChecks[config.Name] = brokers.HealthCheck
 	}
 	return c, nil
 }
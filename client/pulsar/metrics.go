@@ -0,0 +1,121 @@
+package pulsar
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/rs/zerolog"
+
+	"github.com/frame-go/framego/errors"
+	"github.com/frame-go/framego/metrics"
+)
+
+const backlogStatsTimeout = 3 * time.Second
+
+// topicStats is the subset of topic stats of pulsar admin API
+type topicStats struct {
+	Subscriptions map[string]struct {
+		MsgBacklog int64 `json:"msgBacklog"`
+	} `json:"subscriptions"`
+}
+
+// backlogCollector collects message backlog of subscriptions of topics from pulsar admin API on each scrape
+type backlogCollector struct {
+	adminURL string
+	token    string
+	topics   []string
+	client   *http.Client
+	logger   *zerolog.Logger
+	backlog  *prometheus.Desc
+}
+
+func newBacklogCollector(registry *metrics.Registry, config *Config, logger *zerolog.Logger) (*backlogCollector, error) {
+	if config.AdminURL == "" {
+		return nil, errors.New("pulsar_admin_url_required").With("client", config.Name)
+	}
+	for _, topic := range config.BacklogTopics {
+		if _, err := topicPath(topic); err != nil {
+			return nil, err
+		}
+	}
+	return &backlogCollector{
+		adminURL: strings.TrimSuffix(config.AdminURL, "/"),
+		token:    config.Token,
+		topics:   config.BacklogTopics,
+		client:   &http.Client{Timeout: backlogStatsTimeout},
+		logger:   logger,
+		backlog: prometheus.NewDesc(registry.Name("pulsar_client_subscription_backlog"),
+			"Number of messages in backlog of subscription.", []string{"topic", "subscription"},
+			prometheus.Labels{"client": config.Name}),
+	}, nil
+}
+
+// topicPath converts topic name to path of admin API, e.g. "persistent://public/default/orders" or "orders"
+// to "persistent/public/default/orders"
+func topicPath(topic string) (string, error) {
+	domain, name, ok := strings.Cut(topic, "://")
+	if !ok {
+		domain, name = "persistent", topic
+	}
+	switch strings.Count(name, "/") {
+	case 0:
+		name = "public/default/" + name
+	case 2:
+	default:
+		return "", errors.New("invalid_pulsar_topic").With("topic", topic)
+	}
+	if domain != "persistent" && domain != "non-persistent" {
+		return "", errors.New("invalid_pulsar_topic").With("topic", topic)
+	}
+	return domain + "/" + name, nil
+}
+
+func (c *backlogCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.backlog
+}
+
+// Collect exports backlog of topics of which stats are fetched, failures are logged and skipped
+func (c *backlogCollector) Collect(ch chan<- prometheus.Metric) {
+	for _, topic := range c.topics {
+		stats, err := c.stats(topic)
+		if err != nil {
+			if c.logger != nil {
+				errors.LogError(c.logger.Warn(), err).Msg("get_pulsar_topic_stats_error")
+			}
+			continue
+		}
+		for subscription, s := range stats.Subscriptions {
+			ch <- prometheus.MustNewConstMetric(c.backlog, prometheus.GaugeValue, float64(s.MsgBacklog), topic, subscription)
+		}
+	}
+}
+
+func (c *backlogCollector) stats(topic string) (*topicStats, error) {
+	path, _ := topicPath(topic)
+	ctx, cancel := context.WithTimeout(context.Background(), backlogStatsTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.adminURL+"/admin/v2/"+path+"/stats", nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "new_pulsar_stats_request_error").With("topic", topic)
+	}
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, errors.Wrap(err, "pulsar_stats_request_error").With("topic", topic)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, errors.New("pulsar_stats_status_error").With("topic", topic).With("status", resp.StatusCode)
+	}
+	stats := &topicStats{}
+	if err = json.NewDecoder(resp.Body).Decode(stats); err != nil {
+		return nil, errors.Wrap(err, "decode_pulsar_stats_error").With("topic", topic)
+	}
+	return stats, nil
+}
@@ -0,0 +1,70 @@
+package pulsar
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/frame-go/framego/metrics"
+)
+
+func TestTopicPath(t *testing.T) {
+	cases := map[string]string{
+		"orders":                                "persistent/public/default/orders",
+		"persistent://tenant1/ns1/orders":       "persistent/tenant1/ns1/orders",
+		"non-persistent://public/default/tasks": "non-persistent/public/default/tasks",
+	}
+	for topic, expected := range cases {
+		path, err := topicPath(topic)
+		if err != nil || path != expected {
+			t.Errorf("path of %s: %v != %v, %v", topic, path, expected, err)
+		}
+	}
+	for _, topic := range []string{"ns1/orders", "unknown://public/default/orders"} {
+		if _, err := topicPath(topic); err == nil {
+			t.Errorf("path of %s should fail", topic)
+		}
+	}
+}
+
+func TestBacklogCollector(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer token1" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		switch r.URL.Path {
+		case "/admin/v2/persistent/public/default/orders/stats":
+			_, _ = w.Write([]byte(`{"msgRateIn":1.5,"subscriptions":{"sub1":{"msgBacklog":12},"sub2":{"msgBacklog":3}}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	registry := metrics.NewRegistry(&metrics.Options{Namespace: "test"})
+	config := &Config{Name: "main", Token: "token1", BacklogTopics: []string{"orders", "missing"}}
+	if _, err := newBacklogCollector(registry, config, nil); err == nil {
+		t.Errorf("expect admin url required error")
+	}
+	config.AdminURL = server.URL + "/"
+	collector, err := newBacklogCollector(registry, config, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = registry.Register(collector); err != nil {
+		t.Fatal(err)
+	}
+
+	value, err := registry.Value("pulsar_client_subscription_backlog",
+		prometheus.Labels{"client": "main", "topic": "orders", "subscription": "sub1"})
+	if err != nil || value != 12 {
+		t.Errorf("backlog of sub1 %v != 12: %v", value, err)
+	}
+	value, err = registry.Value("pulsar_client_subscription_backlog", prometheus.Labels{"client": "main"})
+	if err != nil || value != 15 {
+		t.Errorf("backlog of all subscriptions %v != 15: %v", value, err)
+	}
+}
@@ -0,0 +1,33 @@
+package config
+
+import (
+	"context"
+	"reflect"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+// watchInterval is interval to check changes of watched config
+const watchInterval = 10 * time.Second
+
+// Watch calls onChange when value of key in config is changed, e.g. by Apollo, until ctx is done
+func Watch(ctx context.Context, key string, onChange func()) {
+	go func() {
+		ticker := time.NewTicker(watchInterval)
+		defer ticker.Stop()
+		last := viper.Get(key)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				value := viper.Get(key)
+				if !reflect.DeepEqual(value, last) {
+					last = value
+					onChange()
+				}
+			}
+		}
+	}()
+}
@@ -98,6 +98,11 @@ func (e *Error) Code() int {
 	return e.code
 }
 
+// Detail returns custom fields in detail of error
+func (e *Error) Detail() map[string]interface{} {
+	return e.detail
+}
+
 // Cause returns error cause of error
 func (e *Error) Cause() error {
 	return e.cause
@@ -0,0 +1,156 @@
+package errors
+
+import (
+	"net/http"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	pb "github.com/frame-go/framego/errors/proto"
+)
+
+// HTTPErrorFormat is the format of error in HTTP response body
+type HTTPErrorFormat string
+
+const (
+	// HTTPErrorFormatDefault renders error as {"error": message, "detail": {...}, "cause": {...}}
+	HTTPErrorFormatDefault HTTPErrorFormat = "default"
+
+	// HTTPErrorFormatProblem renders error as RFC 7807 problem details,
+	// error message is put in "detail" and custom fields are added as extension members
+	HTTPErrorFormatProblem HTTPErrorFormat = "problem"
+)
+
+const (
+	contentTypeJSON    = "application/json"
+	contentTypeProblem = "application/problem+json"
+)
+
+// HTTPError is error rendered in HTTP response
+type HTTPError struct {
+	// Status is the HTTP status mapped from gRPC code
+	Status int
+
+	// Message is the error message
+	Message string
+
+	// Detail is the custom fields of error
+	Detail map[string]interface{}
+
+	// Cause is the error cause, only available in debug mode
+	Cause *HTTPError
+}
+
+// ToHTTPError converts error to HTTP error. Error details carried in gRPC status are restored.
+func ToHTTPError(err error) *HTTPError {
+	s := status.Convert(err)
+	var detail *pb.Error
+	var e *Error
+	if As(err, &e) {
+		detail = e.detailProto()
+	} else {
+		for _, d := range s.Details() {
+			if v, ok := d.(*pb.Error); ok {
+				detail = v
+				break
+			}
+		}
+	}
+	var httpErr *HTTPError
+	if detail != nil {
+		httpErr = httpErrorFromProto(detail)
+	} else {
+		httpErr = &HTTPError{Message: s.Message()}
+	}
+	httpErr.Status = HTTPStatusFromCode(s.Code())
+	return httpErr
+}
+
+func httpErrorFromProto(detail *pb.Error) *HTTPError {
+	e := &HTTPError{
+		Message: detail.GetError(),
+	}
+	if detail.GetDetail() != nil {
+		e.Detail = detail.GetDetail().AsMap()
+	}
+	if detail.GetCause() != nil {
+		e.Cause = httpErrorFromProto(detail.GetCause())
+	}
+	return e
+}
+
+// ContentType returns content type of error in format
+func (e *HTTPError) ContentType(format HTTPErrorFormat) string {
+	if format == HTTPErrorFormatProblem {
+		return contentTypeProblem
+	}
+	return contentTypeJSON
+}
+
+// Body returns response body of error in format, instance is the request path used by problem format
+func (e *HTTPError) Body(format HTTPErrorFormat, instance string) map[string]interface{} {
+	if format != HTTPErrorFormatProblem {
+		return e.defaultBody()
+	}
+	body := make(map[string]interface{}, len(e.Detail)+6)
+	for key, value := range e.Detail {
+		body[key] = value
+	}
+	body["type"] = "about:blank"
+	body["title"] = http.StatusText(e.Status)
+	body["status"] = e.Status
+	body["detail"] = e.Message
+	if instance != "" {
+		body["instance"] = instance
+	}
+	if e.Cause != nil {
+		body["cause"] = e.Cause.defaultBody()
+	}
+	return body
+}
+
+func (e *HTTPError) defaultBody() map[string]interface{} {
+	body := map[string]interface{}{
+		"error": e.Message,
+	}
+	if len(e.Detail) > 0 {
+		body["detail"] = e.Detail
+	}
+	if e.Cause != nil {
+		body["cause"] = e.Cause.defaultBody()
+	}
+	return body
+}
+
+// HTTPStatusFromCode maps gRPC code to HTTP status.
+// See: https://github.com/googleapis/googleapis/blob/master/google/rpc/code.proto
+func HTTPStatusFromCode(code codes.Code) int {
+	switch code {
+	case codes.OK:
+		return http.StatusOK
+	case codes.Canceled:
+		return 499
+	case codes.InvalidArgument, codes.OutOfRange:
+		return http.StatusBadRequest
+	case codes.DeadlineExceeded:
+		return http.StatusGatewayTimeout
+	case codes.NotFound:
+		return http.StatusNotFound
+	case codes.AlreadyExists, codes.Aborted:
+		return http.StatusConflict
+	case codes.PermissionDenied:
+		return http.StatusForbidden
+	case codes.Unauthenticated:
+		return http.StatusUnauthorized
+	case codes.ResourceExhausted:
+		return http.StatusTooManyRequests
+	case codes.FailedPrecondition:
+		return http.StatusBadRequest
+	case codes.Unimplemented:
+		return http.StatusNotImplemented
+	case codes.Unavailable:
+		return http.StatusServiceUnavailable
+	default:
+		return http.StatusInternalServerError
+	}
+}
@@ -0,0 +1,34 @@
+package ginex
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// RouteAccessChecker checks access of the subject in context to HTTP route, e.g. grpcex.AccessController
+type RouteAccessChecker interface {
+	CheckRouteAccess(ctx context.Context, path string, method string) error
+}
+
+// AccessControlMiddleware authorizes gin routes by access controller.
+// Requests not matching any gin route are forwarded to grpc-gateway and authorized as gRPC methods, so they are skipped.
+func AccessControlMiddleware(controller RouteAccessChecker) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.FullPath() == "" {
+			return
+		}
+		err := controller.CheckRouteAccess(c, c.Request.URL.Path, c.Request.Method)
+		if err == nil {
+			return
+		}
+		if status.Code(err) == codes.Unauthenticated {
+			AbortWithError(c, http.StatusUnauthorized, err)
+		} else {
+			AbortWithError(c, http.StatusForbidden, err)
+		}
+	}
+}
@@ -0,0 +1,65 @@
+package ginex
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/frame-go/framego/errors"
+)
+
+const contextErrorFormatKey = "_error_format"
+
+// ErrorMiddleware sets format of errors written by AbortWithError,
+// and writes the last error of request in format if handler does not write response.
+// HTTP status is mapped from gRPC code of error if handler does not set status.
+func ErrorMiddleware(format errors.HTTPErrorFormat) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Set(contextErrorFormatKey, format)
+		c.Next()
+		if len(c.Errors) == 0 || c.Writer.Written() {
+			return
+		}
+		status := 0
+		if c.Writer.Status() != http.StatusOK {
+			status = c.Writer.Status()
+		}
+		renderError(c, status, c.Errors.Last().Err)
+	}
+}
+
+// AbortWithError aborts request with HTTP status and writes error message and detail in response body.
+// HTTP status is mapped from gRPC code of error if status is 0.
+func AbortWithError(c *gin.Context, status int, err error) {
+	_ = c.Error(err)
+	c.Abort()
+	renderError(c, status, err)
+}
+
+func renderError(c *gin.Context, status int, err error) {
+	httpErr := errors.ToHTTPError(err)
+	if status != 0 {
+		httpErr.Status = status
+	}
+	format, _ := c.Value(contextErrorFormatKey).(errors.HTTPErrorFormat)
+	c.Render(httpErr.Status, errorRender{
+		contentType: httpErr.ContentType(format),
+		body:        httpErr.Body(format, c.Request.URL.Path),
+	})
+}
+
+// errorRender renders error body as JSON with content type of error format
+type errorRender struct {
+	contentType string
+	body        interface{}
+}
+
+func (r errorRender) Render(w http.ResponseWriter) error {
+	r.WriteContentType(w)
+	return json.NewEncoder(w).Encode(r.body)
+}
+
+func (r errorRender) WriteContentType(w http.ResponseWriter) {
+	w.Header().Set("Content-Type", r.contentType)
+}
@@ -0,0 +1,62 @@
+package ginex
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"google.golang.org/grpc/codes"
+
+	"github.com/frame-go/framego/errors"
+)
+
+func newErrorTestRouter(format errors.HTTPErrorFormat) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(ErrorMiddleware(format))
+	router.GET("/orders/:id", func(c *gin.Context) {
+		_ = c.Error(errors.New("order_not_found").With("id", c.Param("id")).WithGRPCCode(codes.NotFound))
+	})
+	router.GET("/abort", func(c *gin.Context) {
+		AbortWithError(c, http.StatusConflict, errors.New("conflict"))
+	})
+	return router
+}
+
+func serveError(t *testing.T, router *gin.Engine, path string) (*httptest.ResponseRecorder, map[string]interface{}) {
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+	var body map[string]interface{}
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatalf("invalid body %q: %v", w.Body.String(), err)
+	}
+	return w, body
+}
+
+func TestErrorMiddleware(t *testing.T) {
+	router := newErrorTestRouter(errors.HTTPErrorFormatDefault)
+	w, body := serveError(t, router, "/orders/1")
+	if w.Code != http.StatusNotFound || w.Header().Get("Content-Type") != "application/json" {
+		t.Errorf("unexpected response %d %s", w.Code, w.Header().Get("Content-Type"))
+	}
+	detail, _ := body["detail"].(map[string]interface{})
+	if body["error"] != "order_not_found" || detail["id"] != "1" {
+		t.Errorf("unexpected body %v", body)
+	}
+	w, body = serveError(t, router, "/abort")
+	if w.Code != http.StatusConflict || body["error"] != "conflict" {
+		t.Errorf("unexpected response %d %v", w.Code, body)
+	}
+
+	router = newErrorTestRouter(errors.HTTPErrorFormatProblem)
+	w, body = serveError(t, router, "/orders/1")
+	if w.Code != http.StatusNotFound || w.Header().Get("Content-Type") != "application/problem+json" {
+		t.Errorf("unexpected response %d %s", w.Code, w.Header().Get("Content-Type"))
+	}
+	if body["title"] != "Not Found" || body["status"] != float64(http.StatusNotFound) ||
+		body["detail"] != "order_not_found" || body["instance"] != "/orders/1" || body["id"] != "1" {
+		t.Errorf("unexpected body %v", body)
+	}
+}
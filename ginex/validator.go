@@ -0,0 +1,134 @@
+package ginex
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+
+	"github.com/frame-go/framego/errors"
+	"github.com/frame-go/framego/validation"
+)
+
+var installValidatorOnce sync.Once
+
+// structValidator validates bound objects by protoc-gen-validate for proto messages,
+// then by the wrapped validator for struct tags
+type structValidator struct {
+	binding.StructValidator
+}
+
+func (v *structValidator) ValidateStruct(obj interface{}) error {
+	if err := validation.Validate(obj); err != nil {
+		return err
+	}
+	return v.StructValidator.ValidateStruct(obj)
+}
+
+// InstallValidator replaces validator of gin binding to validate proto messages by protoc-gen-validate,
+// and reports struct tag violations by JSON or form field names. It is safe to call multiple times.
+//
+// The validator of gin binding is global, so it takes effect on all gin engines in the process, with or without
+// RequestValidationMiddleware. It should be called once in app initialization before serving requests.
+func InstallValidator() {
+	installValidatorOnce.Do(func() {
+		if engine, ok := binding.Validator.Engine().(*validator.Validate); ok {
+			engine.RegisterTagNameFunc(fieldName)
+		}
+		binding.Validator = &structValidator{StructValidator: binding.Validator}
+	})
+}
+
+// fieldName returns JSON or form name of struct field
+func fieldName(field reflect.StructField) string {
+	for _, tag := range []string{"json", "form", "uri"} {
+		name := strings.SplitN(field.Tag.Get(tag), ",", 2)[0]
+		if name == "-" {
+			continue
+		}
+		if name != "" {
+			return name
+		}
+	}
+	return field.Name
+}
+
+// ValidationError converts binding error of gin to "invalid_argument" error with field violations in detail
+func ValidationError(err error) *errors.Error {
+	var e *errors.Error
+	if errors.As(err, &e) && e.Message() == "invalid_argument" {
+		return e
+	}
+	var violations []interface{}
+	var validationErrors validator.ValidationErrors
+	var typeError *json.UnmarshalTypeError
+	switch {
+	case errors.As(err, &validationErrors):
+		for _, fieldErr := range validationErrors {
+			rule := fieldErr.Tag()
+			if fieldErr.Param() != "" {
+				rule += "=" + fieldErr.Param()
+			}
+			violations = append(violations, map[string]interface{}{
+				"field":  trimNamespace(fieldErr.Namespace()),
+				"reason": "value does not satisfy rule " + rule,
+			})
+		}
+	case errors.As(err, &typeError):
+		violations = append(violations, map[string]interface{}{
+			"field":  typeError.Field,
+			"reason": "value must be " + typeError.Type.String(),
+		})
+	}
+	return validation.NewError(err, violations)
+}
+
+// trimNamespace removes the root struct name from namespace of field
+func trimNamespace(namespace string) string {
+	if i := strings.IndexByte(namespace, '.'); i >= 0 {
+		return namespace[i+1:]
+	}
+	return namespace
+}
+
+// bindErrorWriter defers writing header of status 400 until the request is handled,
+// so that body of binding error can be written by RequestValidationMiddleware
+type bindErrorWriter struct {
+	gin.ResponseWriter
+	deferred bool
+}
+
+func (w *bindErrorWriter) WriteHeaderNow() {
+	if !w.Written() && w.Status() == http.StatusBadRequest {
+		w.deferred = true
+		return
+	}
+	w.ResponseWriter.WriteHeaderNow()
+}
+
+// RequestValidationMiddleware validates data bound by gin, and writes "invalid_argument" error with field violations
+// if handler aborts request by binding error without response body, e.g. by c.Bind or c.ShouldBindJSON.
+// Handlers using c.ShouldBind can write the same error by AbortWithError and ValidationError.
+// Proto messages are validated and violations are reported by JSON or form field names only after InstallValidator.
+func RequestValidationMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		writer := &bindErrorWriter{ResponseWriter: c.Writer}
+		c.Writer = writer
+		c.Next()
+		c.Writer = writer.ResponseWriter
+		if !writer.deferred || writer.Written() {
+			return
+		}
+		bindErr := c.Errors.ByType(gin.ErrorTypeBind).Last()
+		if bindErr == nil {
+			c.Writer.WriteHeaderNow()
+			return
+		}
+		renderError(c, http.StatusBadRequest, ValidationError(bindErr.Err))
+	}
+}
@@ -0,0 +1,84 @@
+package ginex
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+type testOrder struct {
+	Name     string `json:"name" binding:"required"`
+	Quantity int    `json:"quantity" binding:"min=1"`
+}
+
+func newValidationTestRouter() *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	InstallValidator()
+	router := gin.New()
+	router.Use(RequestValidationMiddleware())
+	router.POST("/bind", func(c *gin.Context) {
+		var order testOrder
+		if c.BindJSON(&order) != nil {
+			return
+		}
+		c.JSON(http.StatusOK, order)
+	})
+	router.POST("/should_bind", func(c *gin.Context) {
+		var order testOrder
+		if err := c.ShouldBindJSON(&order); err != nil {
+			AbortWithError(c, http.StatusBadRequest, ValidationError(err))
+			return
+		}
+		c.JSON(http.StatusOK, order)
+	})
+	return router
+}
+
+func TestRequestValidationMiddleware(t *testing.T) {
+	router := newValidationTestRouter()
+	for _, path := range []string{"/bind", "/should_bind"} {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(`{"quantity":0}`))
+		router.ServeHTTP(w, req)
+		if w.Code != http.StatusBadRequest {
+			t.Fatalf("%s: unexpected status %d", path, w.Code)
+		}
+		if !strings.HasPrefix(w.Header().Get("Content-Type"), "application/json") {
+			t.Errorf("%s: unexpected content type %q", path, w.Header().Get("Content-Type"))
+		}
+		var body struct {
+			Error  string `json:"error"`
+			Detail struct {
+				Violations []map[string]string `json:"violations"`
+			} `json:"detail"`
+		}
+		if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+			t.Fatalf("%s: invalid body %q: %v", path, w.Body.String(), err)
+		}
+		if body.Error != "invalid_argument" {
+			t.Errorf("%s: unexpected error %q", path, body.Error)
+		}
+		violations := body.Detail.Violations
+		if len(violations) != 2 || violations[0]["field"] != "name" || violations[1]["field"] != "quantity" {
+			t.Errorf("%s: unexpected violations %v", path, violations)
+		}
+	}
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/bind", strings.NewReader(`{"name":"a","quantity":"x"}`))
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusBadRequest || !strings.Contains(w.Body.String(), `"field":"quantity"`) {
+		t.Errorf("unexpected type error response %d %s", w.Code, w.Body.String())
+	}
+
+	w = httptest.NewRecorder()
+	req = httptest.NewRequest(http.MethodPost, "/bind", strings.NewReader(`{"name":"a","quantity":2}`))
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Errorf("unexpected status %d: %s", w.Code, w.Body.String())
+	}
+}
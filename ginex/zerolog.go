@@ -1,11 +1,14 @@
 package ginex
 
 import (
+	"bytes"
+	"io"
 	"time"
 
 	"github.com/gin-gonic/gin"
 
 	"github.com/frame-go/framego/log"
+	"github.com/frame-go/framego/payloadlog"
 )
 
 // ContextLoggerMiddleware add logger to Context
@@ -20,11 +23,68 @@ func ContextLoggerMiddleware() gin.HandlerFunc {
 	}
 }
 
-func LogRequestMiddleware() gin.HandlerFunc {
+type logRequestOptions struct {
+	payloadLogger *payloadlog.Logger
+}
+
+// LogRequestOption is option of request logging middleware
+type LogRequestOption func(*logRequestOptions)
+
+// WithPayloadLogger logs request and response bodies of paths enabled in payload logger
+func WithPayloadLogger(p *payloadlog.Logger) LogRequestOption {
+	return func(o *logRequestOptions) {
+		o.payloadLogger = p
+	}
+}
+
+// payloadWriter captures response body up to limit
+type payloadWriter struct {
+	gin.ResponseWriter
+	body  bytes.Buffer
+	limit int
+}
+
+func (w *payloadWriter) Write(data []byte) (int, error) {
+	w.capture(data)
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *payloadWriter) WriteString(s string) (int, error) {
+	w.capture([]byte(s))
+	return w.ResponseWriter.WriteString(s)
+}
+
+func (w *payloadWriter) capture(data []byte) {
+	if remain := w.limit - w.body.Len(); remain > 0 {
+		if len(data) > remain {
+			data = data[:remain]
+		}
+		w.body.Write(data)
+	}
+}
+
+func LogRequestMiddleware(opts ...LogRequestOption) gin.HandlerFunc {
+	o := &logRequestOptions{}
+	for _, opt := range opts {
+		opt(o)
+	}
 	return func(c *gin.Context) {
 		start := time.Now()
 		logger := log.FromContext(c)
 
+		// Capture payloads if enabled
+		var requestBody []byte
+		var writer *payloadWriter
+		if o.payloadLogger != nil && o.payloadLogger.IsEnabled(c.Request.URL.Path) {
+			limit := o.payloadLogger.CaptureSize()
+			if c.Request.Body != nil {
+				requestBody, _ = io.ReadAll(io.LimitReader(c.Request.Body, int64(limit)))
+				c.Request.Body = io.NopCloser(io.MultiReader(bytes.NewReader(requestBody), c.Request.Body))
+			}
+			writer = &payloadWriter{ResponseWriter: c.Writer, limit: limit}
+			c.Writer = writer
+		}
+
 		// Handle the panic case and still write the error log
 		defer func() {
 			if v := recover(); v != nil {
@@ -44,8 +104,12 @@ func LogRequestMiddleware() gin.HandlerFunc {
 		c.Next()
 
 		// Write request log
-		logger.Info().
-			Str("query", c.Request.URL.RawQuery).
+		e := logger.Info()
+		if writer != nil && e.Enabled() {
+			e = o.payloadLogger.AddJSON(e, "request", requestBody)
+			e = o.payloadLogger.AddJSON(e, "response", writer.body.Bytes())
+		}
+		e.Str("query", c.Request.URL.RawQuery).
 			Int64("request_size", c.Request.ContentLength).
 			Int("status", c.Writer.Status()).
 			Int("response_size", c.Writer.Size()).
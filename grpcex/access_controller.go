@@ -3,11 +3,17 @@ package grpcex
 import (
 	"context"
 	"path"
+	"path/filepath"
 	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
 
 	casbin "github.com/casbin/casbin/v2"
 	"github.com/casbin/casbin/v2/model"
+	"github.com/casbin/casbin/v2/persist"
 	fileadapter "github.com/casbin/casbin/v2/persist/file-adapter"
+	"github.com/fsnotify/fsnotify"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/credentials"
@@ -25,26 +31,87 @@ const (
 	defaultCasbinModel    = `
 [request_definition]
 r = sub, obj
+r2 = sub, obj, act
 
 [policy_definition]
 p = sub, obj
+p2 = sub, obj, act
 
 [role_definition]
 g = _, _
+g2 = _, _
 
 [policy_effect]
 e = some(where (p.eft == allow))
+e2 = some(where (p.eft == allow))
 
 [matchers]
-m = r.sub == p.sub && (g(r.obj, p.obj) || globMatch(r.obj, p.obj))
+m = (r.sub == p.sub || g2(r.sub, p.sub)) && (g(r.obj, p.obj) || globMatch(r.obj, p.obj))
+m2 = (r2.sub == p2.sub || g2(r2.sub, p2.sub)) && (g(r2.obj, p2.obj) || globMatch(r2.obj, p2.obj)) && \
+    (r2.act == p2.act || p2.act == "*")
 `
 )
 
+// watchDebounceInterval is the delay to reload policies after the last change of watched file
+const watchDebounceInterval = 100 * time.Millisecond
+
+// routeEnforceContext selects "r2/p2/e2/m2" definitions of casbin model for HTTP routes
+var routeEnforceContext = casbin.NewEnforceContext("2")
+
+// SubjectsFunc returns subjects of authenticated principals in context, e.g. JWT subject
+type SubjectsFunc func(ctx context.Context) []string
+
+// AccessControllerOption is option of AccessController
+type AccessControllerOption func(c *AccessController)
+
+// WithSubjectsFunc sets function to get principal subjects in context,
+// which are checked besides subjects of TLS certificate or anonymous client
+func WithSubjectsFunc(f SubjectsFunc) AccessControllerOption {
+	return func(c *AccessController) {
+		c.subjectsFunc = f
+	}
+}
+
+// WithReloadInterval reloads policies from adapter periodically
+func WithReloadInterval(interval time.Duration) AccessControllerOption {
+	return func(c *AccessController) {
+		c.reloadInterval = interval
+	}
+}
+
+// WithWatchFile reloads policies from adapter when the file is changed
+func WithWatchFile(file string) AccessControllerOption {
+	return func(c *AccessController) {
+		c.watchFile = filepath.Clean(file)
+	}
+}
+
+// AccessController authorizes gRPC methods and HTTP routes by casbin policies.
+// gRPC methods are checked by "p" policies (subject, method),
+// and HTTP routes are checked by "p2" policies (subject, path, HTTP method).
+// Subjects inherit permissions of roles by "g2" rules.
 type AccessController struct {
-	enforcer *casbin.Enforcer
+	enforcer       atomic.Pointer[casbin.Enforcer]
+	model          model.Model
+	adapter        persist.Adapter
+	subjectsFunc   SubjectsFunc
+	reloadInterval time.Duration
+	watchFile      string
+	watchTarget    string
+	watcher        *fsnotify.Watcher
+	stop           chan struct{}
+	stopOnce       sync.Once
 }
 
-func NewAccessController(modelFile string, policyFile string) (*AccessController, error) {
+// NewAccessController creates access controller with casbin model file and policy file,
+// use the default model if model file is empty
+func NewAccessController(modelFile string, policyFile string, opts ...AccessControllerOption) (*AccessController, error) {
+	return NewAccessControllerWithAdapter(modelFile, fileadapter.NewAdapter(policyFile), opts...)
+}
+
+// NewAccessControllerWithAdapter creates access controller with casbin model file and policy adapter,
+// use the default model if model file is empty
+func NewAccessControllerWithAdapter(modelFile string, adapter persist.Adapter, opts ...AccessControllerOption) (*AccessController, error) {
 	var err error
 	var m model.Model
 	if modelFile == "" {
@@ -58,19 +125,148 @@ func NewAccessController(modelFile string, policyFile string) (*AccessController
 			return nil, errors.Wrap(err, "new_casbin_model_from_file_error").With("model_file", modelFile)
 		}
 	}
-	a := fileadapter.NewAdapter(policyFile)
-	enforcer, err := casbin.NewEnforcer(m, a)
+	c := &AccessController{
+		model:   m,
+		adapter: adapter,
+		stop:    make(chan struct{}),
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	enforcer, err := c.newEnforcer()
 	if err != nil {
-		return nil, errors.Wrap(err, "new_casbin_enforcer_error").
-			With("model", m.ToText()).With("policy_file", policyFile)
+		return nil, err
 	}
-	enforcer.AddNamedMatchingFunc("g", "", globMatch)
-	c := &AccessController{
-		enforcer: enforcer,
+	c.enforcer.Store(enforcer)
+	if c.watchFile != "" {
+		err = c.startWatcher()
+		if err != nil {
+			return nil, err
+		}
+	}
+	if c.reloadInterval > 0 {
+		go c.reloadPeriodically()
 	}
 	return c, nil
 }
 
+func (c *AccessController) newEnforcer() (*casbin.Enforcer, error) {
+	enforcer, err := casbin.NewEnforcer(c.model.Copy(), c.adapter)
+	if err != nil {
+		return nil, errors.Wrap(err, "new_casbin_enforcer_error").With("model", c.model.ToText())
+	}
+	enforcer.AddNamedMatchingFunc("g", "", globMatch)
+	return enforcer, nil
+}
+
+// ReloadPolicy reloads policies from adapter. Requests are checked by the current policies during reloading,
+// and the current policies are kept if failed.
+func (c *AccessController) ReloadPolicy() error {
+	enforcer, err := c.newEnforcer()
+	if err != nil {
+		return err
+	}
+	c.enforcer.Store(enforcer)
+	return nil
+}
+
+func (c *AccessController) reloadPolicy() {
+	err := c.ReloadPolicy()
+	if err != nil {
+		errors.LogError(log.Logger.Error(), err).Msg("reload_access_policy_error")
+		return
+	}
+	log.Logger.Info().Msg("access_policy_reloaded")
+}
+
+func (c *AccessController) reloadPeriodically() {
+	ticker := time.NewTicker(c.reloadInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-c.stop:
+			return
+		case <-ticker.C:
+			c.reloadPolicy()
+		}
+	}
+}
+
+// startWatcher watches directory of the file, since files may be replaced by rename or symlink update
+func (c *AccessController) startWatcher() error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return errors.Wrap(err, "new_file_watcher_error")
+	}
+	err = watcher.Add(filepath.Dir(c.watchFile))
+	if err != nil {
+		_ = watcher.Close()
+		return errors.Wrap(err, "watch_policy_file_error").With("file", c.watchFile)
+	}
+	c.watcher = watcher
+	c.watchTarget, _ = filepath.EvalSymlinks(c.watchFile)
+	go c.watch()
+	return nil
+}
+
+func (c *AccessController) watch() {
+	// debounce events since one update of file may trigger several events
+	var reload <-chan time.Time
+	for {
+		select {
+		case <-c.stop:
+			return
+		case event, ok := <-c.watcher.Events:
+			if !ok {
+				return
+			}
+			if c.isWatchFileEvent(event) {
+				reload = time.After(watchDebounceInterval)
+			}
+		case err, ok := <-c.watcher.Errors:
+			if !ok {
+				return
+			}
+			log.Logger.Error().Err(err).Str("file", c.watchFile).Msg("watch_policy_file_error")
+		case <-reload:
+			reload = nil
+			c.reloadPolicy()
+		}
+	}
+}
+
+// isWatchFileEvent checks whether event of the watched directory changes the watched file, by writing, creating or
+// renaming the file itself, or by changing target of the file if it is a symlink,
+// e.g. ConfigMap volume updates files by swapping symlink "..data" in the directory
+func (c *AccessController) isWatchFileEvent(event fsnotify.Event) bool {
+	if !event.Has(fsnotify.Write) && !event.Has(fsnotify.Create) && !event.Has(fsnotify.Rename) {
+		return false
+	}
+	if filepath.Clean(event.Name) == c.watchFile {
+		c.watchTarget, _ = filepath.EvalSymlinks(c.watchFile)
+		return true
+	}
+	if event.Has(fsnotify.Write) {
+		return false
+	}
+	target, err := filepath.EvalSymlinks(c.watchFile)
+	if err != nil || target == c.watchTarget {
+		return false
+	}
+	c.watchTarget = target
+	return true
+}
+
+// Close stops reloading policies
+func (c *AccessController) Close() {
+	c.stopOnce.Do(func() {
+		close(c.stop)
+		if c.watcher != nil {
+			_ = c.watcher.Close()
+		}
+	})
+}
+
 func (c *AccessController) UnaryServerInterceptor() grpc.UnaryServerInterceptor {
 	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
 		err := c.checkAccess(ctx, info.FullMethod)
@@ -91,20 +287,39 @@ func (c *AccessController) StreamServerInterceptor() grpc.StreamServerIntercepto
 	}
 }
 
-func (c *AccessController) checkAccess(ctx context.Context, method string) error {
-	if strings.HasPrefix(method, reflectionServiceName) ||
+// IsBuiltinServiceMethod checks whether the gRPC full method belongs to built-in reflection, channelz or health service
+func IsBuiltinServiceMethod(method string) bool {
+	return strings.HasPrefix(method, reflectionServiceName) ||
 		strings.HasPrefix(method, channelzServiceName) ||
-		strings.HasPrefix(method, healthServiceName) {
+		strings.HasPrefix(method, healthServiceName)
+}
+
+func (c *AccessController) checkAccess(ctx context.Context, method string) error {
+	if IsBuiltinServiceMethod(method) {
 		return nil
 	}
 	delimiter := strings.LastIndex(method, "/")
 	if delimiter >= 0 {
 		method = method[delimiter+1:]
 	}
+	// principals in context take precedence over client certificate and anonymous access
+	subjects := c.contextSubjects(ctx)
+	for _, subject := range subjects {
+		if c.checkServiceAccess(subject, method) {
+			return nil
+		}
+	}
+	if len(subjects) > 0 {
+		return errors.New("access_denied").With("subjects", subjects).With("method", method).
+			WithGRPCCode(codes.PermissionDenied)
+	}
 	client, ok := peer.FromContext(ctx)
 	if !ok {
 		return errors.New("peer_not_found_in_context").WithGRPCCode(codes.Unauthenticated)
 	}
+	if client.AuthInfo == nil {
+		return errors.New("access_denied").With("method", method).WithGRPCCode(codes.Unauthenticated)
+	}
 	authType := client.AuthInfo.AuthType()
 	switch authType {
 	case "inproc":
@@ -144,8 +359,51 @@ func (c *AccessController) checkAccess(ctx context.Context, method string) error
 	}
 }
 
+// CheckRouteAccess checks whether principals in context or anonymous client can access HTTP route.
+// Returns error with codes.Unauthenticated if no principal in context, or codes.PermissionDenied if denied.
+func (c *AccessController) CheckRouteAccess(ctx context.Context, path string, method string) error {
+	subjects := c.contextSubjects(ctx)
+	for _, subject := range subjects {
+		if c.checkRouteAccess(subject, path, method) {
+			return nil
+		}
+	}
+	if c.checkRouteAccess(anonymousServiceName, path, method) {
+		return nil
+	}
+	if len(subjects) == 0 {
+		return errors.New("access_denied").With("path", path).With("method", method).
+			WithGRPCCode(codes.Unauthenticated)
+	}
+	return errors.New("access_denied").With("subjects", subjects).With("path", path).
+		With("method", method).WithGRPCCode(codes.PermissionDenied)
+}
+
+// HasRouteModel checks whether casbin model has "r2" definitions to authorize HTTP routes
+func (c *AccessController) HasRouteModel() bool {
+	_, ok := c.model["r"]["r2"]
+	return ok
+}
+
+func (c *AccessController) contextSubjects(ctx context.Context) []string {
+	if c.subjectsFunc == nil {
+		return nil
+	}
+	return c.subjectsFunc(ctx)
+}
+
+func (c *AccessController) checkRouteAccess(subject string, path string, method string) bool {
+	ok, err := c.enforcer.Load().Enforce(routeEnforceContext, subject, path, method)
+	if err != nil {
+		log.Logger.Error().Err(err).Str("client", subject).Str("path", path).Str("method", method).
+			Msg("enforce_route_check_error")
+		return false
+	}
+	return ok
+}
+
 func (c *AccessController) checkServiceAccess(service string, method string) bool {
-	ok, err := c.enforcer.Enforce(service, method)
+	ok, err := c.enforcer.Load().Enforce(service, method)
 	if err != nil {
 		log.Logger.Error().Err(err).Str("client", service).Str("method", method).Msg("enforce_check_error")
 		return false
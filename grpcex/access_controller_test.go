@@ -1,13 +1,27 @@
 package grpcex
 
 import (
+	"context"
+	"os"
+	"path/filepath"
 	"testing"
+	"time"
 
 	casbin "github.com/casbin/casbin/v2"
 	"github.com/casbin/casbin/v2/model"
 	stringadapter "github.com/casbin/casbin/v2/persist/string-adapter"
+	"github.com/fsnotify/fsnotify"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/frame-go/framego/log"
 )
 
+func TestMain(m *testing.M) {
+	log.Init("fatal", false, false)
+	os.Exit(m.Run())
+}
+
 const testPolicy = `
 p, ServiceA, Cat1Method1
 p, ServiceA, Cat1Method2
@@ -28,8 +42,17 @@ g, Cat2*, Group2
 g, *Method*, Group3
 g, Cat1Method1, Group4
 g, Cat2*, Group4
+
+p, admin, Cat9*
+p2, admin, /v1/*/*, *
+p2, user1, /v1/users/*, GET
+p2, <anonymous>, /public/*, GET
+
+g2, user2, admin
 `
 
+type testSubjectsKey struct{}
+
 func initAccessController() (*AccessController, error) {
 	m, err := model.NewModelFromString(defaultCasbinModel)
 	if err != nil {
@@ -42,8 +65,12 @@ func initAccessController() (*AccessController, error) {
 	}
 	enforcer.AddNamedMatchingFunc("g", "", globMatch)
 	c := &AccessController{
-		enforcer: enforcer,
+		subjectsFunc: func(ctx context.Context) []string {
+			subjects, _ := ctx.Value(testSubjectsKey{}).([]string)
+			return subjects
+		},
 	}
+	c.enforcer.Store(enforcer)
 	return c, nil
 }
 
@@ -77,6 +104,9 @@ func TestAccessController(t *testing.T) {
 		{"ServiceH", "Cat2Method1", true},
 		{"ServiceH", "Cat3Method1", true},
 		{"ServiceH", "Cat3Method2", false},
+		{"admin", "Cat9Method1", true},
+		{"user2", "Cat9Method1", true},
+		{"user1", "Cat9Method1", false},
 	}
 	c, err := initAccessController()
 	if err != nil {
@@ -90,6 +120,97 @@ func TestAccessController(t *testing.T) {
 	}
 }
 
+func TestCheckRouteAccess(t *testing.T) {
+	var tests = []struct {
+		subjects []string
+		path     string
+		method   string
+		code     codes.Code
+	}{
+		{nil, "/public/info", "GET", codes.OK},
+		{nil, "/public/info", "POST", codes.Unauthenticated},
+		{nil, "/v1/users/1", "GET", codes.Unauthenticated},
+		{[]string{"user1"}, "/v1/users/1", "GET", codes.OK},
+		{[]string{"user1"}, "/v1/users/1", "DELETE", codes.PermissionDenied},
+		{[]string{"user1"}, "/v1/orders/1", "GET", codes.PermissionDenied},
+		{[]string{"user1"}, "/public/info", "GET", codes.OK},
+		{[]string{"admin"}, "/v1/orders/1", "DELETE", codes.OK},
+		{[]string{"user2"}, "/v1/orders/1", "POST", codes.OK},
+		{[]string{"user3"}, "/v1/orders/1", "GET", codes.PermissionDenied},
+	}
+	c, err := initAccessController()
+	if err != nil {
+		t.Errorf("init_access_controller_error: %v", err)
+		return
+	}
+	for _, test := range tests {
+		ctx := context.WithValue(context.Background(), testSubjectsKey{}, test.subjects)
+		err = c.CheckRouteAccess(ctx, test.path, test.method)
+		if status.Code(err) != test.code {
+			t.Errorf("check_route_access_error: %v, %v", test, err)
+		}
+	}
+}
+
+func TestCheckAccessWithContextSubjects(t *testing.T) {
+	c, err := initAccessController()
+	if err != nil {
+		t.Errorf("init_access_controller_error: %v", err)
+		return
+	}
+	ctx := context.WithValue(context.Background(), testSubjectsKey{}, []string{"user2"})
+	err = c.checkAccess(ctx, "/test.Service/Cat9Method1")
+	if err != nil {
+		t.Errorf("expect_access_allowed: %v", err)
+	}
+	ctx = context.WithValue(context.Background(), testSubjectsKey{}, []string{"user1"})
+	err = c.checkAccess(ctx, "/test.Service/Cat9Method1")
+	if status.Code(err) != codes.PermissionDenied {
+		t.Errorf("expect_permission_denied: %v", err)
+	}
+}
+
+func TestReloadPolicy(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "acl.csv")
+	err := os.WriteFile(file, []byte("p, ServiceA, Method1\n"), 0600)
+	if err != nil {
+		t.Fatalf("write_policy_error: %v", err)
+	}
+	c, err := NewAccessController("", file, WithWatchFile(file))
+	if err != nil {
+		t.Fatalf("new_access_controller_error: %v", err)
+	}
+	defer c.Close()
+	if !c.checkServiceAccess("ServiceA", "Method1") || c.checkServiceAccess("ServiceA", "Method2") {
+		t.Errorf("unexpected_initial_policy")
+	}
+
+	err = os.WriteFile(file, []byte("p, ServiceA, Method2\n"), 0600)
+	if err != nil {
+		t.Fatalf("write_policy_error: %v", err)
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for !c.checkServiceAccess("ServiceA", "Method2") && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if !c.checkServiceAccess("ServiceA", "Method2") || c.checkServiceAccess("ServiceA", "Method1") {
+		t.Errorf("expect_watched_policy_reloaded")
+	}
+
+	c.Close()
+	err = os.WriteFile(file, []byte("p, ServiceA\n"), 0600)
+	if err != nil {
+		t.Fatalf("write_policy_error: %v", err)
+	}
+	err = c.ReloadPolicy()
+	if err == nil {
+		t.Errorf("expect_reload_invalid_policy_error")
+	}
+	if !c.checkServiceAccess("ServiceA", "Method2") {
+		t.Errorf("expect_last_good_policy_kept")
+	}
+}
+
 func BenchmarkAccessController(b *testing.B) {
 	c, err := initAccessController()
 	if err != nil {
@@ -107,3 +228,86 @@ func BenchmarkAccessController(b *testing.B) {
 		}
 	})
 }
+
+func TestHasRouteModel(t *testing.T) {
+	c, err := NewAccessControllerWithAdapter("", stringadapter.NewAdapter(testPolicy))
+	if err != nil {
+		t.Fatalf("new_access_controller_error: %v", err)
+	}
+	if !c.HasRouteModel() {
+		t.Errorf("expect_default_model_with_route_definition")
+	}
+	modelFile := filepath.Join(t.TempDir(), "acl.conf")
+	err = os.WriteFile(modelFile, []byte(`
+[request_definition]
+r = sub, obj
+
+[policy_definition]
+p = sub, obj
+
+[policy_effect]
+e = some(where (p.eft == allow))
+
+[matchers]
+m = r.sub == p.sub && r.obj == p.obj
+`), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, err = NewAccessControllerWithAdapter(modelFile, stringadapter.NewAdapter("p, ServiceA, Method1"))
+	if err != nil {
+		t.Fatalf("new_access_controller_error: %v", err)
+	}
+	if c.HasRouteModel() {
+		t.Errorf("expect_model_without_route_definition")
+	}
+}
+
+func TestIsWatchFileEvent(t *testing.T) {
+	// layout of ConfigMap volume: acl.csv -> ..data/acl.csv, ..data -> v1
+	dir := t.TempDir()
+	for _, version := range []string{"v1", "v2"} {
+		err := os.Mkdir(filepath.Join(dir, version), 0700)
+		if err != nil {
+			t.Fatalf("mkdir_error: %v", err)
+		}
+		err = os.WriteFile(filepath.Join(dir, version, "acl.csv"), []byte("p, ServiceA, Method1\n"), 0600)
+		if err != nil {
+			t.Fatalf("write_policy_error: %v", err)
+		}
+	}
+	file := filepath.Join(dir, "acl.csv")
+	if os.Symlink("v1", filepath.Join(dir, "..data")) != nil || os.Symlink("..data/acl.csv", file) != nil {
+		t.Fatalf("symlink_error")
+	}
+	c := &AccessController{}
+	WithWatchFile(file)(c)
+	c.watchTarget, _ = filepath.EvalSymlinks(file)
+
+	tests := []struct {
+		event  fsnotify.Event
+		expect bool
+	}{
+		{fsnotify.Event{Name: file, Op: fsnotify.Write}, true},
+		{fsnotify.Event{Name: file, Op: fsnotify.Chmod}, false},
+		{fsnotify.Event{Name: filepath.Join(dir, "other.csv"), Op: fsnotify.Write}, false},
+		{fsnotify.Event{Name: filepath.Join(dir, "other.csv"), Op: fsnotify.Create}, false},
+	}
+	for _, test := range tests {
+		if c.isWatchFileEvent(test.event) != test.expect {
+			t.Errorf("unexpected_event_result: %v, expect %v", test.event, test.expect)
+		}
+	}
+
+	// swap symlink as ConfigMap volume does
+	if os.Symlink("v2", filepath.Join(dir, "..data_tmp")) != nil ||
+		os.Rename(filepath.Join(dir, "..data_tmp"), filepath.Join(dir, "..data")) != nil {
+		t.Fatalf("swap_symlink_error")
+	}
+	if !c.isWatchFileEvent(fsnotify.Event{Name: filepath.Join(dir, "..data"), Op: fsnotify.Create}) {
+		t.Errorf("expect_symlink_swap_event_matched")
+	}
+	if c.isWatchFileEvent(fsnotify.Event{Name: filepath.Join(dir, "..data_tmp"), Op: fsnotify.Rename}) {
+		t.Errorf("expect_same_target_event_skipped")
+	}
+}
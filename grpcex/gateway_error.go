@@ -0,0 +1,48 @@
+package grpcex
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/frame-go/framego/errors"
+	"github.com/frame-go/framego/log"
+)
+
+// GatewayErrorHandler returns error handler of grpc-gateway, which writes error in format of errors.HTTPError.
+// Header metadata of gRPC response is forwarded with runtime.MetadataHeaderPrefix.
+func GatewayErrorHandler(format errors.HTTPErrorFormat) runtime.ErrorHandlerFunc {
+	return func(ctx context.Context, mux *runtime.ServeMux, marshaler runtime.Marshaler, w http.ResponseWriter, r *http.Request, err error) {
+		var customStatus *runtime.HTTPStatusError
+		if errors.As(err, &customStatus) {
+			err = customStatus.Err
+		}
+		httpErr := errors.ToHTTPError(err)
+		if customStatus != nil {
+			httpErr.Status = customStatus.HTTPStatus
+		}
+		w.Header().Del("Trailer")
+		w.Header().Del("Transfer-Encoding")
+		w.Header().Set("Content-Type", httpErr.ContentType(format))
+		if status.Code(err) == codes.Unauthenticated {
+			w.Header().Set("WWW-Authenticate", httpErr.Message)
+		}
+		if md, ok := runtime.ServerMetadataFromContext(ctx); ok {
+			for key, values := range md.HeaderMD {
+				for _, value := range values {
+					w.Header().Add(fmt.Sprintf("%s%s", runtime.MetadataHeaderPrefix, key), value)
+				}
+			}
+		}
+		w.WriteHeader(httpErr.Status)
+		err = json.NewEncoder(w).Encode(httpErr.Body(format, r.URL.Path))
+		if err != nil {
+			log.Logger.Debug().Err(err).Msg("write_gateway_error_response_error")
+		}
+	}
+}
@@ -0,0 +1,42 @@
+package grpcex
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/frame-go/framego/errors"
+)
+
+func TestGatewayErrorHandler(t *testing.T) {
+	// errors from gRPC handlers reach gateway as status with error detail
+	e := errors.New("access_denied").With("method", "/sample.Sample/Ping").WithGRPCCode(codes.PermissionDenied)
+	err := status.ErrorProto(e.GRPCStatus().Proto())
+
+	handler := GatewayErrorHandler(errors.HTTPErrorFormatDefault)
+	w := httptest.NewRecorder()
+	handler(context.Background(), nil, nil, w, httptest.NewRequest(http.MethodGet, "/v1/ping", nil), err)
+	if w.Code != http.StatusForbidden {
+		t.Errorf("unexpected status %d", w.Code)
+	}
+	var body map[string]interface{}
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatalf("invalid body %q: %v", w.Body.String(), err)
+	}
+	detail, _ := body["detail"].(map[string]interface{})
+	if body["error"] != "access_denied" || detail["method"] != "/sample.Sample/Ping" {
+		t.Errorf("unexpected body %v", body)
+	}
+
+	w = httptest.NewRecorder()
+	handler(context.Background(), nil, nil, w, httptest.NewRequest(http.MethodGet, "/v1/ping", nil),
+		status.Error(codes.Unavailable, "unavailable"))
+	if w.Code != http.StatusServiceUnavailable || w.Body.String() != "{\"error\":\"unavailable\"}\n" {
+		t.Errorf("unexpected response %d %s", w.Code, w.Body.String())
+	}
+}
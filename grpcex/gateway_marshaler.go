@@ -0,0 +1,182 @@
+package grpcex
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"mime"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"github.com/vmihailenco/msgpack"
+
+	"github.com/frame-go/framego/errors"
+)
+
+const (
+	// MIMEProtobuf is the content type of binary protobuf in grpc-gateway
+	MIMEProtobuf = "application/x-protobuf"
+
+	// MIMEMsgpack is the content type of msgpack in grpc-gateway
+	MIMEMsgpack = "application/msgpack"
+)
+
+// NegotiateAccept rewrites Accept header of request to the most preferred content type in supported types,
+// since grpc-gateway only matches whole values of Accept header. Accept header is removed if any type is accepted
+// or no type is supported, then response is encoded as content type of request.
+func NegotiateAccept(r *http.Request, supported []string) {
+	accept := r.Header.Values("Accept")
+	if len(accept) == 0 {
+		return
+	}
+	type acceptType struct {
+		mediaType string
+		q         float64
+	}
+	var types []acceptType
+	for _, value := range accept {
+		for _, item := range strings.Split(value, ",") {
+			mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(item))
+			if err != nil {
+				continue
+			}
+			q := 1.0
+			if v, ok := params["q"]; ok {
+				q, err = strconv.ParseFloat(v, 64)
+				if err != nil {
+					continue
+				}
+			}
+			if q > 0 {
+				types = append(types, acceptType{mediaType: mediaType, q: q})
+			}
+		}
+	}
+	sort.SliceStable(types, func(i, j int) bool {
+		return types[i].q > types[j].q
+	})
+	r.Header.Del("Accept")
+	for _, t := range types {
+		if t.mediaType == "*/*" {
+			return
+		}
+		for _, s := range supported {
+			if t.mediaType == s {
+				r.Header.Set("Accept", s)
+				return
+			}
+		}
+	}
+}
+
+// protoMarshaler marshals binary protobuf with content type MIMEProtobuf
+type protoMarshaler struct {
+	runtime.ProtoMarshaller
+}
+
+// NewProtoMarshaler creates grpc-gateway marshaler of binary protobuf
+func NewProtoMarshaler() runtime.Marshaler {
+	return &protoMarshaler{}
+}
+
+func (m *protoMarshaler) ContentType(_ interface{}) string {
+	return MIMEProtobuf
+}
+
+// msgpackMarshaler marshals messages to msgpack through JSON marshaler,
+// so field names and well-known types are encoded as same as JSON
+type msgpackMarshaler struct {
+	json runtime.Marshaler
+}
+
+// NewMsgpackMarshaler creates grpc-gateway marshaler of msgpack, messages are converted by JSON marshaler
+func NewMsgpackMarshaler(jsonMarshaler runtime.Marshaler) runtime.Marshaler {
+	return &msgpackMarshaler{json: jsonMarshaler}
+}
+
+func (m *msgpackMarshaler) ContentType(_ interface{}) string {
+	return MIMEMsgpack
+}
+
+func (m *msgpackMarshaler) Marshal(v interface{}) ([]byte, error) {
+	data, err := m.json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var value interface{}
+	err = decoder.Decode(&value)
+	if err != nil {
+		return nil, errors.Wrap(err, "decode_json_error")
+	}
+	return msgpack.Marshal(fromJSONNumber(value))
+}
+
+func (m *msgpackMarshaler) Unmarshal(data []byte, v interface{}) error {
+	var value interface{}
+	err := msgpack.Unmarshal(data, &value)
+	if err != nil {
+		return errors.Wrap(err, "decode_msgpack_error")
+	}
+	return m.unmarshalValue(value, v)
+}
+
+func (m *msgpackMarshaler) unmarshalValue(value interface{}, v interface{}) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return errors.Wrap(err, "encode_json_error")
+	}
+	return m.json.Unmarshal(data, v)
+}
+
+func (m *msgpackMarshaler) NewDecoder(r io.Reader) runtime.Decoder {
+	decoder := msgpack.NewDecoder(r)
+	return runtime.DecoderFunc(func(v interface{}) error {
+		value, err := decoder.DecodeInterface()
+		if err != nil {
+			return err
+		}
+		return m.unmarshalValue(value, v)
+	})
+}
+
+func (m *msgpackMarshaler) NewEncoder(w io.Writer) runtime.Encoder {
+	return runtime.EncoderFunc(func(v interface{}) error {
+		data, err := m.Marshal(v)
+		if err != nil {
+			return err
+		}
+		_, err = w.Write(data)
+		return err
+	})
+}
+
+// Delimiter returns empty delimiter of stream, since msgpack values are self-delimiting
+func (m *msgpackMarshaler) Delimiter() []byte {
+	return nil
+}
+
+// fromJSONNumber converts JSON numbers to integers if possible, otherwise floats
+func fromJSONNumber(v interface{}) interface{} {
+	switch value := v.(type) {
+	case json.Number:
+		if i, err := value.Int64(); err == nil {
+			return i
+		}
+		f, _ := value.Float64()
+		return f
+	case map[string]interface{}:
+		for k, item := range value {
+			value[k] = fromJSONNumber(item)
+		}
+	case []interface{}:
+		for i, item := range value {
+			value[i] = fromJSONNumber(item)
+		}
+	}
+	return v
+}
@@ -0,0 +1,83 @@
+package grpcex
+
+import (
+	"bytes"
+	"fmt"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"github.com/vmihailenco/msgpack"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/structpb"
+
+	pb "github.com/frame-go/framego/errors/proto"
+)
+
+func TestMsgpackMarshaler(t *testing.T) {
+	detail, _ := structpb.NewStruct(map[string]interface{}{"count": 3})
+	msg := &pb.Error{Error: "not_found", Detail: detail}
+	m := NewMsgpackMarshaler(&runtime.JSONPb{MarshalOptions: protojson.MarshalOptions{UseProtoNames: true}})
+	if m.ContentType(msg) != MIMEMsgpack {
+		t.Errorf("unexpected content type %s", m.ContentType(msg))
+	}
+
+	data, err := m.Marshal(msg)
+	if err != nil {
+		t.Fatalf("marshal error: %v", err)
+	}
+	var value map[string]interface{}
+	if err = msgpack.Unmarshal(data, &value); err != nil {
+		t.Fatalf("decode msgpack error: %v", err)
+	}
+	if value["error"] != "not_found" || fmt.Sprint(value["detail"].(map[string]interface{})["count"]) != "3" {
+		t.Errorf("unexpected msgpack value %v", value)
+	}
+
+	result := &pb.Error{}
+	if err = m.Unmarshal(data, result); err != nil {
+		t.Fatalf("unmarshal error: %v", err)
+	}
+	if !proto.Equal(msg, result) {
+		t.Errorf("unexpected unmarshaled message %v", result)
+	}
+
+	// stream of messages
+	buf := &bytes.Buffer{}
+	encoder := m.NewEncoder(buf)
+	for i := 0; i < 2; i++ {
+		if err = encoder.Encode(msg); err != nil {
+			t.Fatalf("encode error: %v", err)
+		}
+	}
+	decoder := m.NewDecoder(buf)
+	for i := 0; i < 2; i++ {
+		result = &pb.Error{}
+		if err = decoder.Decode(result); err != nil || !proto.Equal(msg, result) {
+			t.Fatalf("unexpected decoded message %v: %v", result, err)
+		}
+	}
+}
+
+func TestNegotiateAccept(t *testing.T) {
+	supported := []string{"application/json", MIMEProtobuf, MIMEMsgpack}
+	cases := []struct {
+		accept   string
+		expected string
+	}{
+		{"application/msgpack", MIMEMsgpack},
+		{"text/html, application/msgpack;q=0.9, application/json;q=0.5", MIMEMsgpack},
+		{"application/json;q=0.5, application/x-protobuf", MIMEProtobuf},
+		{"text/html, */*;q=0.8", ""},
+		{"application/msgpack;q=0, application/xml", ""},
+	}
+	for _, c := range cases {
+		r := httptest.NewRequest("GET", "/", nil)
+		r.Header.Set("Accept", c.accept)
+		NegotiateAccept(r, supported)
+		if r.Header.Get("Accept") != c.expected {
+			t.Errorf("unexpected accept %q of %q", r.Header.Get("Accept"), c.accept)
+		}
+	}
+}
@@ -0,0 +1,76 @@
+package grpcex
+
+import (
+	"github.com/casbin/casbin/v2/model"
+	"github.com/casbin/casbin/v2/persist"
+	"gorm.io/gorm"
+
+	"github.com/frame-go/framego/errors"
+)
+
+// DefaultPolicyTable is the default table name of casbin policies
+const DefaultPolicyTable = "casbin_rule"
+
+// PolicyRule is a row of casbin policy table, compatible with table schema of casbin gorm-adapter
+type PolicyRule struct {
+	ID    uint   `gorm:"primaryKey;autoIncrement"`
+	Ptype string `gorm:"size:100"`
+	V0    string `gorm:"size:100"`
+	V1    string `gorm:"size:100"`
+	V2    string `gorm:"size:100"`
+	V3    string `gorm:"size:100"`
+	V4    string `gorm:"size:100"`
+	V5    string `gorm:"size:100"`
+}
+
+// gormPolicyAdapter is a read-only casbin adapter loading policies from database table
+type gormPolicyAdapter struct {
+	db    *gorm.DB
+	table string
+}
+
+// NewGormPolicyAdapter creates read-only casbin adapter loading policies from database table,
+// use DefaultPolicyTable if table is empty
+func NewGormPolicyAdapter(db *gorm.DB, table string) persist.Adapter {
+	if table == "" {
+		table = DefaultPolicyTable
+	}
+	return &gormPolicyAdapter{db: db, table: table}
+}
+
+func (a *gormPolicyAdapter) LoadPolicy(m model.Model) error {
+	var rules []*PolicyRule
+	err := a.db.Table(a.table).Order("id").Find(&rules).Error
+	if err != nil {
+		return errors.Wrap(err, "load_policy_from_db_error").With("table", a.table)
+	}
+	for _, rule := range rules {
+		values := []string{rule.Ptype, rule.V0, rule.V1, rule.V2, rule.V3, rule.V4, rule.V5}
+		// trim trailing empty fields
+		n := len(values)
+		for n > 1 && values[n-1] == "" {
+			n--
+		}
+		err = persist.LoadPolicyArray(values[:n], m)
+		if err != nil {
+			return errors.Wrap(err, "load_policy_rule_error").With("table", a.table).With("id", rule.ID)
+		}
+	}
+	return nil
+}
+
+func (a *gormPolicyAdapter) SavePolicy(m model.Model) error {
+	return errors.New("policy_adapter_read_only")
+}
+
+func (a *gormPolicyAdapter) AddPolicy(sec string, ptype string, rule []string) error {
+	return errors.New("policy_adapter_read_only")
+}
+
+func (a *gormPolicyAdapter) RemovePolicy(sec string, ptype string, rule []string) error {
+	return errors.New("policy_adapter_read_only")
+}
+
+func (a *gormPolicyAdapter) RemoveFilteredPolicy(sec string, ptype string, fieldIndex int, fieldValues ...string) error {
+	return errors.New("policy_adapter_read_only")
+}
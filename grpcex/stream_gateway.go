@@ -0,0 +1,343 @@
+package grpcex
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"github.com/grpc-ecosystem/grpc-gateway/v2/utilities"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+
+	"github.com/frame-go/framego/errors"
+)
+
+const (
+	// MIMEEventStream is the content type of Server-Sent Events
+	MIMEEventStream = "text/event-stream"
+
+	// streamMaxMessageSize is the max size of WebSocket message in bytes
+	streamMaxMessageSize = 10 * 1024 * 1024
+
+	// streamCloseTimeout is the timeout to write WebSocket close message
+	streamCloseTimeout = time.Second
+)
+
+// ServiceRegistry queries registered gRPC services, e.g. grpchan.HandlerMap
+type ServiceRegistry interface {
+	QueryService(name string) (*grpc.ServiceDesc, interface{})
+}
+
+// StreamGateway serves streaming RPCs on HTTP path of gRPC full method.
+// Server streaming RPCs are served as Server-Sent Events if request accepts MIMEEventStream,
+// and all streaming RPCs are served over WebSocket for upgrade requests. Messages are encoded by JSON marshaler.
+type StreamGateway struct {
+	mux         *runtime.ServeMux
+	conn        grpc.ClientConnInterface
+	registry    ServiceRegistry
+	marshaler   runtime.Marshaler
+	errorFormat errors.HTTPErrorFormat
+	upgrader    websocket.Upgrader
+}
+
+// NewStreamGateway creates stream gateway calling services in registry by conn, e.g. inprocgrpc.Channel.
+// Request metadata and errors are handled by grpc-gateway mux.
+func NewStreamGateway(mux *runtime.ServeMux, conn grpc.ClientConnInterface, registry ServiceRegistry,
+	marshaler runtime.Marshaler, errorFormat errors.HTTPErrorFormat) *StreamGateway {
+	return &StreamGateway{
+		mux:         mux,
+		conn:        conn,
+		registry:    registry,
+		marshaler:   marshaler,
+		errorFormat: errorFormat,
+	}
+}
+
+// Handle serves request if it is Server-Sent Events or WebSocket request of streaming method,
+// returns false if request is not handled
+func (g *StreamGateway) Handle(w http.ResponseWriter, r *http.Request) bool {
+	method := r.URL.Path
+	desc := g.streamDesc(method)
+	if desc == nil {
+		return false
+	}
+	isWebSocket := websocket.IsWebSocketUpgrade(r)
+	isEventStream := !isWebSocket && desc.ServerStreams && !desc.ClientStreams && acceptsEventStream(r)
+	if !isWebSocket && !isEventStream {
+		return false
+	}
+	input, output, err := streamMessageTypes(method)
+	if err != nil {
+		return false
+	}
+	ctx, err := runtime.AnnotateContext(r.Context(), g.mux, r, method)
+	if err != nil {
+		runtime.HTTPError(ctx, g.mux, g.marshaler, w, r, err)
+		return true
+	}
+	if isWebSocket {
+		g.serveWebSocket(ctx, w, r, method, desc, input, output)
+	} else {
+		g.serveEventStream(ctx, w, r, method, input, output)
+	}
+	return true
+}
+
+func (g *StreamGateway) streamDesc(method string) *grpc.StreamDesc {
+	service, name, ok := splitFullMethod(method)
+	if !ok {
+		return nil
+	}
+	serviceDesc, _ := g.registry.QueryService(service)
+	if serviceDesc == nil {
+		return nil
+	}
+	for i := range serviceDesc.Streams {
+		if serviceDesc.Streams[i].StreamName == name {
+			return &serviceDesc.Streams[i]
+		}
+	}
+	return nil
+}
+
+func (g *StreamGateway) serveEventStream(ctx context.Context, w http.ResponseWriter, r *http.Request, method string,
+	input protoreflect.MessageType, output protoreflect.MessageType) {
+	req := input.New().Interface()
+	var err error
+	if r.Method == http.MethodGet {
+		err = runtime.PopulateQueryParameters(req, r.URL.Query(), utilities.NewDoubleArray(nil))
+	} else {
+		inbound, _ := runtime.MarshalerForRequest(g.mux, r)
+		err = inbound.NewDecoder(r.Body).Decode(req)
+		if err == io.EOF {
+			err = nil
+		}
+	}
+	if err != nil {
+		err = errors.Wrap(err, "invalid_argument").WithGRPCCode(codes.InvalidArgument)
+		runtime.HTTPError(ctx, g.mux, g.marshaler, w, r, err)
+		return
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	stream, err := g.conn.NewStream(ctx, &grpc.StreamDesc{ServerStreams: true}, method)
+	if err == nil {
+		err = stream.SendMsg(req)
+	}
+	if err == nil {
+		err = stream.CloseSend()
+	}
+	if err != nil {
+		runtime.HTTPError(ctx, g.mux, g.marshaler, w, r, err)
+		return
+	}
+
+	flusher, _ := w.(http.Flusher)
+	wroteHeader := false
+	for {
+		resp := output.New().Interface()
+		err = stream.RecvMsg(resp)
+		if err != nil && err != io.EOF && !wroteHeader {
+			// report error before any event by HTTP status, so clients will not reconnect
+			runtime.HTTPError(ctx, g.mux, g.marshaler, w, r, err)
+			return
+		}
+		if !wroteHeader {
+			w.Header().Set("Content-Type", MIMEEventStream)
+			w.Header().Set("Cache-Control", "no-cache")
+			w.Header().Set("X-Accel-Buffering", "no")
+			w.WriteHeader(http.StatusOK)
+			wroteHeader = true
+		}
+		if err == io.EOF {
+			return
+		}
+		if err != nil {
+			if ctx.Err() != nil {
+				// client disconnected
+				return
+			}
+			data, _ := g.marshaler.Marshal(errors.ToHTTPError(err).Body(g.errorFormat, r.URL.Path))
+			_ = writeEvent(w, "error", data)
+			return
+		}
+		data, err := g.marshaler.Marshal(resp)
+		if err == nil {
+			err = writeEvent(w, "", data)
+		}
+		if err != nil {
+			return
+		}
+		if flusher != nil {
+			flusher.Flush()
+		}
+	}
+}
+
+func (g *StreamGateway) serveWebSocket(ctx context.Context, w http.ResponseWriter, r *http.Request, method string,
+	desc *grpc.StreamDesc, input protoreflect.MessageType, output protoreflect.MessageType) {
+	conn, err := g.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		// upgrader has written error response
+		return
+	}
+	defer conn.Close()
+	conn.SetReadLimit(streamMaxMessageSize)
+
+	ctx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+	stream, err := g.conn.NewStream(ctx, desc, method)
+	if err != nil {
+		closeWebSocket(conn, err)
+		return
+	}
+	go g.forwardWebSocketRequests(conn, stream, desc.ClientStreams, input, cancel)
+	for {
+		resp := output.New().Interface()
+		err = stream.RecvMsg(resp)
+		if err == io.EOF {
+			closeWebSocket(conn, nil)
+			return
+		}
+		if err != nil {
+			if cause := context.Cause(ctx); cause != nil && cause != context.Canceled {
+				err = cause
+			}
+			closeWebSocket(conn, err)
+			return
+		}
+		data, err := g.marshaler.Marshal(resp)
+		if err != nil {
+			closeWebSocket(conn, err)
+			return
+		}
+		// blocks on slow client, so that server is blocked on sending by flow control
+		if conn.WriteMessage(websocket.TextMessage, data) != nil {
+			return
+		}
+	}
+}
+
+// forwardWebSocketRequests sends WebSocket messages to stream. An empty message closes sending of stream,
+// and stream is closed after the first message if method is not client streaming.
+// Stream is canceled once WebSocket is closed.
+func (g *StreamGateway) forwardWebSocketRequests(conn *websocket.Conn, stream grpc.ClientStream, clientStreams bool,
+	input protoreflect.MessageType, cancel context.CancelCauseFunc) {
+	closed := false
+	for {
+		_, data, err := conn.ReadMessage()
+		if err != nil {
+			cancel(nil)
+			return
+		}
+		if closed {
+			continue
+		}
+		if len(data) == 0 {
+			closed = true
+			_ = stream.CloseSend()
+			continue
+		}
+		req := input.New().Interface()
+		err = g.marshaler.Unmarshal(data, req)
+		if err != nil {
+			cancel(errors.Wrap(err, "invalid_argument").WithGRPCCode(codes.InvalidArgument))
+			return
+		}
+		// blocks until server receives, so that client is blocked on sending by flow control
+		if stream.SendMsg(req) != nil {
+			// stream is finished, error is received by RecvMsg
+			return
+		}
+		if !clientStreams {
+			closed = true
+			_ = stream.CloseSend()
+		}
+	}
+}
+
+// closeWebSocket writes close message with error. Close code is 4000 plus HTTP status of error, and reason is error message.
+func closeWebSocket(conn *websocket.Conn, err error) {
+	code := websocket.CloseNormalClosure
+	reason := ""
+	if err != nil {
+		httpErr := errors.ToHTTPError(err)
+		code = 4000 + httpErr.Status
+		reason = httpErr.Message
+		// reason of close message is limited to 123 bytes
+		if len(reason) > 123 {
+			reason = reason[:123]
+		}
+	}
+	message := websocket.FormatCloseMessage(code, reason)
+	_ = conn.WriteControl(websocket.CloseMessage, message, time.Now().Add(streamCloseTimeout))
+}
+
+func writeEvent(w io.Writer, event string, data []byte) error {
+	buf := &bytes.Buffer{}
+	if event != "" {
+		buf.WriteString("event: " + event + "\n")
+	}
+	for _, line := range bytes.Split(data, []byte("\n")) {
+		buf.WriteString("data: ")
+		buf.Write(line)
+		buf.WriteString("\n")
+	}
+	buf.WriteString("\n")
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+func acceptsEventStream(r *http.Request) bool {
+	for _, value := range r.Header.Values("Accept") {
+		if strings.Contains(value, MIMEEventStream) {
+			return true
+		}
+	}
+	return false
+}
+
+// splitFullMethod splits gRPC full method "/package.Service/Method" to service and method names
+func splitFullMethod(method string) (string, string, bool) {
+	if !strings.HasPrefix(method, "/") {
+		return "", "", false
+	}
+	i := strings.LastIndex(method, "/")
+	if i <= 1 || i == len(method)-1 {
+		return "", "", false
+	}
+	return method[1:i], method[i+1:], true
+}
+
+func streamMessageTypes(method string) (protoreflect.MessageType, protoreflect.MessageType, error) {
+	service, name, _ := splitFullMethod(method)
+	d, err := protoregistry.GlobalFiles.FindDescriptorByName(protoreflect.FullName(service))
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "find_service_descriptor_error").With("method", method)
+	}
+	serviceDesc, ok := d.(protoreflect.ServiceDescriptor)
+	if !ok {
+		return nil, nil, errors.New("invalid_service_descriptor").With("method", method)
+	}
+	methodDesc := serviceDesc.Methods().ByName(protoreflect.Name(name))
+	if methodDesc == nil {
+		return nil, nil, errors.New("method_descriptor_not_found").With("method", method)
+	}
+	input, err := protoregistry.GlobalTypes.FindMessageByName(methodDesc.Input().FullName())
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "find_input_type_error").With("method", method)
+	}
+	output, err := protoregistry.GlobalTypes.FindMessageByName(methodDesc.Output().FullName())
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "find_output_type_error").With("method", method)
+	}
+	return input, output, nil
+}
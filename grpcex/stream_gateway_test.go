@@ -0,0 +1,127 @@
+package grpcex
+
+import (
+	"bufio"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fullstorydev/grpchan"
+	"github.com/fullstorydev/grpchan/inprocgrpc"
+	"github.com/gorilla/websocket"
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+
+	"github.com/frame-go/framego/errors"
+)
+
+func newStreamGatewayTestServer(t *testing.T) (*httptest.Server, *health.Server, *int) {
+	healthServer := health.NewServer()
+	handlers := grpchan.HandlerMap{}
+	handlers.RegisterService(&healthpb.Health_ServiceDesc, healthServer)
+	channel := &inprocgrpc.Channel{}
+	intercepted := 0
+	channel.WithServerStreamInterceptor(func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		intercepted++
+		return handler(srv, ss)
+	})
+	handlers.ForEach(channel.RegisterService)
+
+	mux := runtime.NewServeMux()
+	gateway := NewStreamGateway(mux, channel, handlers, &runtime.JSONPb{}, errors.HTTPErrorFormatDefault)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !gateway.Handle(w, r) {
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server, healthServer, &intercepted
+}
+
+func TestStreamGatewayEventStream(t *testing.T) {
+	server, healthServer, intercepted := newStreamGatewayTestServer(t)
+
+	// not accepting event stream
+	resp, err := http.Get(server.URL + "/grpc.health.v1.Health/Watch")
+	if err != nil {
+		t.Fatalf("request error: %v", err)
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("unexpected status %d", resp.StatusCode)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, server.URL+"/grpc.health.v1.Health/Watch?service=", nil)
+	req.Header.Set("Accept", MIMEEventStream)
+	resp, err = http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("request error: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || resp.Header.Get("Content-Type") != MIMEEventStream {
+		t.Fatalf("unexpected response %d %s", resp.StatusCode, resp.Header.Get("Content-Type"))
+	}
+	reader := bufio.NewReader(resp.Body)
+	readEvent := func() string {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			t.Fatalf("read event error: %v", err)
+		}
+		_, _ = reader.ReadString('\n')
+		return strings.TrimSpace(line)
+	}
+	if event := readEvent(); event != `data: {"status":"SERVING"}` {
+		t.Errorf("unexpected event %q", event)
+	}
+	healthServer.SetServingStatus("", healthpb.HealthCheckResponse_NOT_SERVING)
+	if event := readEvent(); event != `data: {"status":"NOT_SERVING"}` {
+		t.Errorf("unexpected event %q", event)
+	}
+	if *intercepted != 1 {
+		t.Errorf("stream is not intercepted")
+	}
+}
+
+func TestStreamGatewayWebSocket(t *testing.T) {
+	server, healthServer, _ := newStreamGatewayTestServer(t)
+	url := "ws" + strings.TrimPrefix(server.URL, "http") + "/grpc.health.v1.Health/Watch"
+
+	conn, _, err := websocket.DefaultDialer.Dial(url, nil)
+	if err != nil {
+		t.Fatalf("dial error: %v", err)
+	}
+	defer conn.Close()
+	_ = conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	if err = conn.WriteMessage(websocket.TextMessage, []byte(`{"service":""}`)); err != nil {
+		t.Fatalf("write error: %v", err)
+	}
+	_, data, err := conn.ReadMessage()
+	if err != nil || string(data) != `{"status":"SERVING"}` {
+		t.Fatalf("unexpected message %s: %v", data, err)
+	}
+	healthServer.SetServingStatus("", healthpb.HealthCheckResponse_NOT_SERVING)
+	_, data, err = conn.ReadMessage()
+	if err != nil || string(data) != `{"status":"NOT_SERVING"}` {
+		t.Fatalf("unexpected message %s: %v", data, err)
+	}
+
+	// invalid request is reported by close code
+	conn2, _, err := websocket.DefaultDialer.Dial(url, nil)
+	if err != nil {
+		t.Fatalf("dial error: %v", err)
+	}
+	defer conn2.Close()
+	_ = conn2.SetReadDeadline(time.Now().Add(5 * time.Second))
+	_ = conn2.WriteMessage(websocket.TextMessage, []byte(`{"service":`))
+	_, _, err = conn2.ReadMessage()
+	if !websocket.IsCloseError(err, 4000+http.StatusBadRequest) {
+		t.Errorf("unexpected close error %v", err)
+	}
+}
@@ -4,42 +4,16 @@ import (
 	"context"
 
 	"google.golang.org/grpc"
-	"google.golang.org/grpc/codes"
 
-	"github.com/frame-go/framego/errors"
+	"github.com/frame-go/framego/validation"
 )
 
-// The validate interface starting with protoc-gen-validate v0.6.0.
-// See https://github.com/envoyproxy/protoc-gen-validate/pull/455.
-type validator interface {
-	Validate(all bool) error
-}
-
-// The validate interface prior to protoc-gen-validate v0.6.0.
-type validatorLegacy interface {
-	Validate() error
-}
-
-func validate(req interface{}) error {
-	switch v := req.(type) {
-	case validatorLegacy:
-		if err := v.Validate(); err != nil {
-			return errors.Wrap(err, "invalid_argument").WithGRPCCode(codes.InvalidArgument)
-		}
-	case validator:
-		if err := v.Validate(false); err != nil {
-			return errors.Wrap(err, "invalid_argument").WithGRPCCode(codes.InvalidArgument)
-		}
-	}
-	return nil
-}
-
 // ValidatorUnaryServerInterceptor returns a new unary server interceptor that validates incoming messages.
 //
 // Invalid messages will be rejected with `InvalidArgument` before reaching any userspace handlers.
 func ValidatorUnaryServerInterceptor() grpc.UnaryServerInterceptor {
 	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
-		if err := validate(req); err != nil {
+		if err := validation.Validate(req); err != nil {
 			return nil, err
 		}
 		return handler(ctx, req)
@@ -51,7 +25,7 @@ func ValidatorUnaryServerInterceptor() grpc.UnaryServerInterceptor {
 // Invalid messages will be rejected with `InvalidArgument` before sending the request to server.
 func ValidatorUnaryClientInterceptor() grpc.UnaryClientInterceptor {
 	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
-		if err := validate(req); err != nil {
+		if err := validation.Validate(req); err != nil {
 			return err
 		}
 		return invoker(ctx, method, req, reply, cc, opts...)
@@ -80,7 +54,7 @@ func (s *validatorRecvWrapper) RecvMsg(m interface{}) error {
 		return err
 	}
 
-	if err := validate(m); err != nil {
+	if err := validation.Validate(m); err != nil {
 		return err
 	}
 
@@ -16,16 +16,27 @@ import (
 
 	ferrors "github.com/frame-go/framego/errors"
 	"github.com/frame-go/framego/log"
+	"github.com/frame-go/framego/payloadlog"
 )
 
 func SetZeroLogger() {
-	grpclog.SetLoggerV2(grpczerolog.NewGrpcZeroLogger(*log.Logger))
+	grpclog.SetLoggerV2(grpczerolog.NewGrpcZeroLogger(*log.Component(log.ComponentGrpc)))
+}
+
+const contextRequestIDKey = "_request_id"
+
+// RequestIDFromContext gets request ID put by context logger interceptor, returns empty string if not exists
+func RequestIDFromContext(ctx context.Context) string {
+	requestID, _ := ctx.Value(contextRequestIDKey).(string)
+	return requestID
 }
 
 func ContextLoggerUnaryServerInterceptor() grpc.UnaryServerInterceptor {
 	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (_ interface{}, err error) {
-		ctxLogger := getContextLogger(ctx, info.FullMethod)
+		requestID := getRequestIdFromContext(ctx)
+		ctxLogger := getContextLogger(ctx, info.FullMethod, requestID)
 		ctx = log.SetContextLogger(ctx, ctxLogger)
+		ctx = context.WithValue(ctx, contextRequestIDKey, requestID)
 		resp, err := handler(ctx, req)
 		return resp, err
 	}
@@ -34,15 +45,35 @@ func ContextLoggerUnaryServerInterceptor() grpc.UnaryServerInterceptor {
 func ContextLoggerStreamServerInterceptor() grpc.StreamServerInterceptor {
 	return func(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
 		ctx := stream.Context()
-		ctxLogger := getContextLogger(ctx, info.FullMethod)
+		requestID := getRequestIdFromContext(ctx)
+		ctxLogger := getContextLogger(ctx, info.FullMethod, requestID)
 		ctx = log.SetContextLogger(ctx, ctxLogger)
+		ctx = context.WithValue(ctx, contextRequestIDKey, requestID)
 		wrappedStream := grpc_middleware.WrapServerStream(stream)
 		wrappedStream.WrappedContext = ctx
 		return handler(srv, wrappedStream)
 	}
 }
 
-func LogRequestUnaryServerInterceptor() grpc.UnaryServerInterceptor {
+type logRequestOptions struct {
+	payloadLogger *payloadlog.Logger
+}
+
+// LogRequestOption is option of request logging interceptors
+type LogRequestOption func(*logRequestOptions)
+
+// WithPayloadLogger logs request and response payloads of unary methods enabled in payload logger
+func WithPayloadLogger(p *payloadlog.Logger) LogRequestOption {
+	return func(o *logRequestOptions) {
+		o.payloadLogger = p
+	}
+}
+
+func LogRequestUnaryServerInterceptor(opts ...LogRequestOption) grpc.UnaryServerInterceptor {
+	o := &logRequestOptions{}
+	for _, opt := range opts {
+		opt(o)
+	}
 	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (_ interface{}, err error) {
 		logger := log.FromContext(ctx)
 		startTime := time.Now()
@@ -51,10 +82,23 @@ func LogRequestUnaryServerInterceptor() grpc.UnaryServerInterceptor {
 
 		resp, err := handler(ctx, req)
 		latency := time.Since(startTime)
+		var e *zerolog.Event
+		if err == nil {
+			e = logger.Info()
+		} else {
+			e = ferrors.LogError(logger.Warn(), err)
+		}
+		if o.payloadLogger != nil && e.Enabled() && o.payloadLogger.IsEnabled(info.FullMethod) {
+			e = o.payloadLogger.AddProto(e, "request", req)
+			if err == nil {
+				e = o.payloadLogger.AddProto(e, "response", resp)
+			}
+		}
+		e = e.Dur("latency", latency)
 		if err == nil {
-			logger.Info().Dur("latency", latency).Msg("grpc_request")
+			e.Msg("grpc_request")
 		} else {
-			ferrors.LogError(logger.Warn(), err).Dur("latency", latency).Msg("grpc_request_with_error")
+			e.Msg("grpc_request_with_error")
 		}
 		return resp, err
 	}
@@ -85,9 +129,9 @@ func getRequestIdFromContext(ctx context.Context) string {
 	return uuid.New().String()
 }
 
-func getContextLogger(ctx context.Context, method string) *zerolog.Logger {
+func getContextLogger(ctx context.Context, method string, requestID string) *zerolog.Logger {
 	l := log.Logger.With().
-		Str("request_id", getRequestIdFromContext(ctx)).
+		Str("request_id", requestID).
 		Str("method", method).
 		Str("ip", GetClientIP(ctx)).
 		Logger()
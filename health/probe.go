@@ -0,0 +1,108 @@
+package health
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/frame-go/framego/errors"
+)
+
+// Probe is kind of health probe, health checks can be used by multiple probes
+type Probe uint8
+
+const (
+	// ProbeLiveness indicates whether process is alive, process should be restarted if it fails
+	ProbeLiveness Probe = 1 << iota
+
+	// ProbeReadiness indicates whether service can serve requests
+	ProbeReadiness
+
+	// ProbeStartup indicates whether service has started, it keeps healthy once service started
+	ProbeStartup
+
+	// DefaultProbes are probes of health checks if not specified
+	DefaultProbes = ProbeReadiness | ProbeStartup
+)
+
+var probeNames = []struct {
+	probe Probe
+	name  string
+}{
+	{ProbeLiveness, "liveness"},
+	{ProbeReadiness, "readiness"},
+	{ProbeStartup, "startup"},
+}
+
+func (p Probe) MarshalText() ([]byte, error) {
+	return []byte(p.String()), nil
+}
+
+func (p Probe) String() string {
+	var names []string
+	for _, item := range probeNames {
+		if p&item.probe != 0 {
+			names = append(names, item.name)
+		}
+	}
+	return strings.Join(names, ",")
+}
+
+// ParseProbes parses probe names: "liveness", "readiness" and "startup". Returns DefaultProbes if names is empty.
+func ParseProbes(names []string) (Probe, error) {
+	if len(names) == 0 {
+		return DefaultProbes, nil
+	}
+	var probes Probe
+	for _, name := range names {
+		found := false
+		for _, item := range probeNames {
+			if strings.EqualFold(name, item.name) {
+				probes |= item.probe
+				found = true
+				break
+			}
+		}
+		if !found {
+			return 0, errors.New("unknown_health_probe").With("probe", name)
+		}
+	}
+	return probes, nil
+}
+
+func (s State) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+func (s State) String() string {
+	switch s {
+	case StateHealthy:
+		return "healthy"
+	case StateUnhealthy:
+		return "unhealthy"
+	case StateDegraded:
+		return "degraded"
+	default:
+		return "unknown"
+	}
+}
+
+type probeResponse struct {
+	Status string `json:"status"`
+	Source string `json:"source,omitempty"`
+}
+
+// NewProbeHandler creates HTTP handler of probe. Status 200 is returned if probe of all reporters are healthy
+// or degraded, otherwise status 503 is returned with the failed source.
+func NewProbeHandler(probe Probe, reporters ...Reporter) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		status := Status{State: StateHealthy}
+		for _, reporter := range reporters {
+			status = worseStatus(status, reporter.ProbeStatus(probe))
+		}
+		resp := probeResponse{Status: status.State.String()}
+		if status.State != StateHealthy {
+			resp.Source = status.Source
+		}
+		writeJSON(w, status.State, resp)
+	})
+}
@@ -0,0 +1,73 @@
+package health
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/frame-go/framego/errors"
+)
+
+func TestParseProbes(t *testing.T) {
+	probes, err := ParseProbes(nil)
+	if err != nil || probes != DefaultProbes {
+		t.Errorf("default probes %v != %v, error: %v", probes, DefaultProbes, err)
+	}
+	probes, err = ParseProbes([]string{"liveness", "Startup"})
+	if err != nil || probes != ProbeLiveness|ProbeStartup {
+		t.Errorf("probes %v != liveness,startup, error: %v", probes, err)
+	}
+	_, err = ParseProbes([]string{"unknown"})
+	if err == nil {
+		t.Errorf("unknown probe should fail")
+	}
+}
+
+func TestProbeHandler(t *testing.T) {
+	var ready atomic.Bool
+	var attempts atomic.Int32
+	r := NewRunner(WithName("test"), WithStartupTimeout(5*time.Second))
+	r.AddCheck("warmup", func(ctx context.Context) error {
+		if attempts.Add(1) < 2 {
+			return errors.New("warming_up")
+		}
+		return nil
+	}, WithProbes(ProbeStartup))
+	r.AddCheck("database", func(ctx context.Context) error {
+		if !ready.Load() {
+			return errors.New("connection_error")
+		}
+		return nil
+	}, WithProbes(ProbeReadiness))
+
+	probe := func(probe Probe) int {
+		w := httptest.NewRecorder()
+		NewProbeHandler(probe, r).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+		return w.Code
+	}
+	if code := probe(ProbeStartup); code != http.StatusServiceUnavailable {
+		t.Errorf("startup probe before start %v != 503", code)
+	}
+
+	status := r.Start()
+	defer r.Stop()
+	if attempts.Load() != 2 {
+		t.Errorf("startup attempts %v != 2", attempts.Load())
+	}
+	if status.State != StateUnhealthy || status.Source != "database" {
+		t.Errorf("status %v of %v != unhealthy of database", status.State, status.Source)
+	}
+	expected := map[Probe]int{
+		ProbeLiveness:  http.StatusOK,
+		ProbeReadiness: http.StatusServiceUnavailable,
+		ProbeStartup:   http.StatusOK,
+	}
+	for p, code := range expected {
+		if c := probe(p); c != code {
+			t.Errorf("%v probe %v != %v", p, c, code)
+		}
+	}
+}
@@ -0,0 +1,8 @@
+package proto
+
+import "embed"
+
+// OpenAPI is OpenAPI document of health gateway
+//
+//go:embed health.swagger.json
+var OpenAPI embed.FS
@@ -0,0 +1,90 @@
+package health
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+)
+
+// Report is detailed health status of runner
+type Report struct {
+	// Name is name of runner
+	Name string `json:"name"`
+
+	// State is the overall health state
+	State State `json:"status"`
+
+	// Checks are reports of all health checks
+	Checks []CheckReport `json:"checks"`
+}
+
+// CheckReport is detailed health status of health check
+type CheckReport struct {
+	Name               string        `json:"name"`
+	State              State         `json:"status"`
+	Critical           bool          `json:"critical"`
+	Probes             Probe         `json:"probes"`
+	Error              error         `json:"-"`
+	Latency            time.Duration `json:"-"`
+	Interval           time.Duration `json:"-"`
+	Timeout            time.Duration `json:"-"`
+	LastCheckTime      time.Time     `json:"last_check_time"`
+	LastTransitionTime time.Time     `json:"last_transition_time"`
+}
+
+type checkReportJSON CheckReport
+
+// MarshalJSON encodes error as message, and durations in seconds
+func (r CheckReport) MarshalJSON() ([]byte, error) {
+	v := struct {
+		checkReportJSON
+		Error           string  `json:"error,omitempty"`
+		LatencySeconds  float64 `json:"latency_seconds"`
+		IntervalSeconds float64 `json:"interval_seconds"`
+		TimeoutSeconds  float64 `json:"timeout_seconds"`
+	}{
+		checkReportJSON: checkReportJSON(r),
+		LatencySeconds:  r.Latency.Seconds(),
+		IntervalSeconds: r.Interval.Seconds(),
+		TimeoutSeconds:  r.Timeout.Seconds(),
+	}
+	if r.Error != nil {
+		v.Error = r.Error.Error()
+	}
+	return json.Marshal(v)
+}
+
+type reportResponse struct {
+	Status   State    `json:"status"`
+	Services []Report `json:"services"`
+}
+
+// NewReportHandler creates HTTP handler of detailed health status of reporters, e.g. services.
+// Status 200 is returned if all reporters are healthy or degraded, otherwise status 503 is returned.
+func NewReportHandler(reporters ...Reporter) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		resp := reportResponse{
+			Status:   StateHealthy,
+			Services: make([]Report, 0, len(reporters)),
+		}
+		for _, reporter := range reporters {
+			report := reporter.Report()
+			if report.State.severity() > resp.Status.severity() {
+				resp.Status = report.State
+			}
+			resp.Services = append(resp.Services, report)
+		}
+		writeJSON(w, resp.Status, resp)
+	})
+}
+
+func writeJSON(w http.ResponseWriter, state State, v interface{}) {
+	code := http.StatusOK
+	if !state.IsAvailable() {
+		code = http.StatusServiceUnavailable
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(code)
+	_ = json.NewEncoder(w).Encode(v)
+}
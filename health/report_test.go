@@ -0,0 +1,66 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/frame-go/framego/errors"
+)
+
+func TestReportHandler(t *testing.T) {
+	r := NewRunner(WithName("test"))
+	r.AddCheck("healthy", func(ctx context.Context) error {
+		return nil
+	})
+	r.AddCheck("cache", func(ctx context.Context) error {
+		return errors.New("connection_error")
+	}, WithCritical(false))
+	r.Start()
+	defer r.Stop()
+
+	w := httptest.NewRecorder()
+	NewReportHandler(r).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/health", nil))
+	if w.Code != http.StatusOK {
+		t.Errorf("status code %v != 200", w.Code)
+	}
+
+	var resp struct {
+		Status   string `json:"status"`
+		Services []struct {
+			Name   string                   `json:"name"`
+			Status string                   `json:"status"`
+			Checks []map[string]interface{} `json:"checks"`
+		} `json:"services"`
+	}
+	err := json.Unmarshal(w.Body.Bytes(), &resp)
+	if err != nil {
+		t.Fatalf("unmarshal report error: %v", err)
+	}
+	if resp.Status != "degraded" || len(resp.Services) != 1 {
+		t.Fatalf("unexpected report %s", w.Body.String())
+	}
+	service := resp.Services[0]
+	if service.Name != "test" || service.Status != "degraded" || len(service.Checks) != 2 {
+		t.Fatalf("unexpected service report %s", w.Body.String())
+	}
+	for _, check := range service.Checks {
+		switch check["name"] {
+		case "healthy":
+			if check["status"] != "healthy" || check["error"] != nil {
+				t.Errorf("unexpected check report %v", check)
+			}
+		case "cache":
+			if check["status"] != "unhealthy" || check["error"] == nil || check["critical"] != false {
+				t.Errorf("unexpected check report %v", check)
+			}
+		default:
+			t.Errorf("unexpected check %v", check["name"])
+		}
+		if _, ok := check["latency_seconds"]; !ok {
+			t.Errorf("latency_seconds not found in %v", check)
+		}
+	}
+}
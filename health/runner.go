@@ -13,13 +13,15 @@ import (
 
 const (
 	// DefaultInterval defines how often health check will run.
-	// If DefaultTimeout > DefaultInterval, the next health check will be
-	// dropped because of unfinished health check.
+	// Each health check runs in its own loop, and the next run waits for unfinished health check.
 	DefaultInterval = 5 * time.Second
 
 	// DefaultTimeout defines how long health check will run.
 	// If the check function cannot be finished in time, a timeout error will be returned.
 	DefaultTimeout = time.Second
+
+	// startupRetryInterval defines how often health check will retry before startup checks are healthy.
+	startupRetryInterval = time.Second
 )
 
 // State is health state code
@@ -27,15 +29,40 @@ type State int
 
 const (
 	StateUnknown   State = 0
-	StateHealthy         = 1
-	StateUnhealthy       = 2
+	StateHealthy   State = 1
+	StateUnhealthy State = 2
+
+	// StateDegraded indicates non-critical health checks fail, but service is still available
+	StateDegraded State = 3
 )
 
+// severity returns order of states to select the worse status
+func (s State) severity() int {
+	switch s {
+	case StateHealthy:
+		return 0
+	case StateDegraded:
+		return 1
+	case StateUnknown:
+		return 2
+	default:
+		return 3
+	}
+}
+
+// IsAvailable returns whether service can serve requests in state, i.e. healthy or degraded
+func (s State) IsAvailable() bool {
+	return s == StateHealthy || s == StateDegraded
+}
+
 // Status is health status
 type Status struct {
 	State  State
 	Error  error
 	Source string // Source of error, usually is health check name
+
+	// Failures are status of all failed health checks, including the one of Source
+	Failures []Status
 }
 
 func (s *Status) Equal(d *Status) bool {
@@ -54,16 +81,64 @@ type Checker interface {
 	HealthCheck(ctx context.Context) error
 }
 
-type checkController interface {
-	// Check checks health status
-	Check(ctx context.Context) Status
+// CheckConfig is config of built-in health check of client
+type CheckConfig struct {
+	// Enabled adds health check of client to services using the client
+	Enabled bool `json:"enabled" mapstructure:"enabled"`
+
+	// Services are names of services using the client, which the health check is added to.
+	// The health check is added to all services if it is empty.
+	Services []string `json:"services" mapstructure:"services"`
+
+	// Critical makes services unhealthy if health check of client fails, otherwise services are degraded.
+	// Default is true, same as WithCritical.
+	Critical *bool `json:"critical" mapstructure:"critical"`
+
+	// Probes using health check of client, refer to ParseProbes. Default is readiness and startup.
+	Probes []string `json:"probes" mapstructure:"probes"`
+
+	// Interval of health check in seconds, default is interval of runner
+	Interval int64 `json:"interval" mapstructure:"interval"`
+
+	// Timeout of health check in seconds, default is timeout of runner
+	Timeout int64 `json:"timeout" mapstructure:"timeout"`
+}
+
+// Options returns options of health check by config
+func (c *CheckConfig) Options() ([]CheckOption, error) {
+	probes, err := ParseProbes(c.Probes)
+	if err != nil {
+		return nil, err
+	}
+	options := []CheckOption{WithProbes(probes)}
+	if c.Critical != nil {
+		options = append(options, WithCritical(*c.Critical))
+	}
+	if c.Interval > 0 {
+		options = append(options, WithInterval(time.Duration(c.Interval)*time.Second))
+	}
+	if c.Timeout > 0 {
+		options = append(options, WithTimeout(time.Duration(c.Timeout)*time.Second))
+	}
+	return options, nil
 }
 
 type Reporter interface {
 	// LastStatus gets last health status
 	LastStatus() Status
 
+	// CheckStatus gets last health status of health checks with name, returns false if name is not found
+	CheckStatus(name string) (Status, bool)
+
+	// ProbeStatus gets last health status of health checks used by probe.
+	// Liveness is healthy if there is no liveness check, and startup keeps healthy once runner started.
+	ProbeStatus(probe Probe) Status
+
+	// Report gets detailed health status of all health checks
+	Report() Report
+
 	// StatusReportChan returns health status report channel
+	// Status is reported if health status or state of any health check is changed
 	// There is only one channel for each reporter instance
 	// The message in channel should be consumed immediately, otherwise the message will be dropped
 	StatusReportChan() chan Status
@@ -73,39 +148,147 @@ type Runner interface {
 	Reporter
 
 	// AddCheck adds health check callback
-	AddCheck(name string, cf CheckFunc)
+	AddCheck(name string, cf CheckFunc, options ...CheckOption)
 
-	// Start starts health check runner and returns first result
+	// Start starts health check runner. Health check is retried until startup checks are healthy or
+	// startup timeout is reached, then the last result is returned.
 	Start() Status
 
 	// Stop stops health check runner
 	Stop()
 }
 
-type unaryChecker struct {
-	checkController
+type checkOptions struct {
+	critical bool
+	probes   Probe
+	interval time.Duration
+	timeout  time.Duration
+}
 
-	name string
-	cf   CheckFunc
+// CheckOption is used by a health check added to runner.
+type CheckOption func(*checkOptions)
+
+// WithCritical sets whether health check is critical, default is true.
+// Failure of non-critical check makes runner degraded instead of unhealthy.
+func WithCritical(critical bool) CheckOption {
+	return func(options *checkOptions) {
+		options.critical = critical
+	}
+}
+
+// WithProbes sets probes using health check, default is DefaultProbes.
+func WithProbes(probes Probe) CheckOption {
+	return func(options *checkOptions) {
+		options.probes = probes
+	}
 }
 
-func newUnaryCheck(name string, cf CheckFunc) *unaryChecker {
-	return &unaryChecker{
+// WithInterval sets the period of health check, default is interval of runner.
+func WithInterval(interval time.Duration) CheckOption {
+	return func(options *checkOptions) {
+		options.interval = interval
+	}
+}
+
+// WithTimeout sets the timeout of health check, default is timeout of runner.
+func WithTimeout(d time.Duration) CheckOption {
+	return func(options *checkOptions) {
+		options.timeout = d
+	}
+}
+
+type unaryChecker struct {
+	name               string
+	cf                 CheckFunc
+	opts               checkOptions
+	lock               sync.RWMutex
+	lastStatus         Status
+	lastLatency        time.Duration
+	lastCheckTime      time.Time
+	lastTransitionTime time.Time
+}
+
+func newUnaryCheck(name string, cf CheckFunc, runOpts *runOptions, options ...CheckOption) *unaryChecker {
+	c := &unaryChecker{
 		name: name,
 		cf:   cf,
+		opts: checkOptions{
+			critical: true,
+			probes:   DefaultProbes,
+			interval: runOpts.interval,
+			timeout:  runOpts.timeout,
+		},
+		lastStatus: Status{State: StateUnknown, Source: name},
 	}
+	for _, o := range options {
+		o(&c.opts)
+	}
+	return c
 }
 
-// Check method of checker will run the CheckFunc with context.
-func (c *unaryChecker) Check(ctx context.Context) Status {
-	var newCtx context.Context
-	var cancel context.CancelFunc
-	if _, ok := ctx.Deadline(); !ok {
-		newCtx, cancel = context.WithTimeout(ctx, DefaultTimeout)
-		defer cancel()
-	} else {
-		newCtx = ctx
+// Check method of checker will run the CheckFunc with timeout of checker, and record the status.
+// Returns whether state is changed.
+func (c *unaryChecker) Check(ctx context.Context) (Status, bool) {
+	start := time.Now()
+	status := c.check(ctx)
+	latency := time.Since(start)
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	changed := c.lastCheckTime.IsZero() || status.State != c.lastStatus.State
+	if changed {
+		c.lastTransitionTime = start
+		if !c.opts.critical {
+			if status.State != StateHealthy {
+				log.Logger.Warn().Err(status.Error).Str("source", c.name).Int("state", int(status.State)).
+					Msg("health_check_non_critical_failed")
+			} else if c.lastStatus.State != StateUnknown {
+				log.Logger.Info().Str("source", c.name).Msg("health_check_non_critical_recovered")
+			}
+		}
+	}
+	c.lastStatus = status
+	c.lastLatency = latency
+	c.lastCheckTime = start
+	return status, changed
+}
+
+// LastStatus returns status of last check
+func (c *unaryChecker) LastStatus() Status {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	return c.lastStatus
+}
+
+// effectiveStatus returns status of last check affecting runner, failure of non-critical check is degraded
+func (c *unaryChecker) effectiveStatus() Status {
+	status := c.LastStatus()
+	if !c.opts.critical && status.State != StateHealthy {
+		status.State = StateDegraded
 	}
+	return status
+}
+
+// Report returns detailed status of last check
+func (c *unaryChecker) Report() CheckReport {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	return CheckReport{
+		Name:               c.name,
+		State:              c.lastStatus.State,
+		Critical:           c.opts.critical,
+		Probes:             c.opts.probes,
+		Error:              c.lastStatus.Error,
+		Latency:            c.lastLatency,
+		Interval:           c.opts.interval,
+		Timeout:            c.opts.timeout,
+		LastCheckTime:      c.lastCheckTime,
+		LastTransitionTime: c.lastTransitionTime,
+	}
+}
+
+func (c *unaryChecker) check(ctx context.Context) Status {
+	newCtx, cancel := context.WithTimeout(ctx, c.opts.timeout)
+	defer cancel()
 
 	ch := make(chan Status, 1)
 	go func() {
@@ -154,6 +337,7 @@ func (c *unaryChecker) Check(ctx context.Context) Status {
 	case <-newCtx.Done():
 		return Status{
 			State:  StateUnknown,
+			Error:  errors.Wrap(newCtx.Err(), "health_check_timeout"),
 			Source: c.name,
 		}
 	}
@@ -161,47 +345,109 @@ func (c *unaryChecker) Check(ctx context.Context) Status {
 
 func newCompositeCheck() *compositeChecker {
 	return &compositeChecker{
-		cl: make([]checkController, 0),
+		cl: make([]*unaryChecker, 0),
 	}
 }
 
 type compositeChecker struct {
-	checkController
-
-	cl []checkController
+	lock    sync.RWMutex
+	cl      []*unaryChecker
+	running bool
 }
 
-func (c *compositeChecker) AddChecker(checker checkController) {
+// AddChecker adds checker, returns whether checker loops are running
+func (c *compositeChecker) AddChecker(checker *unaryChecker) bool {
+	c.lock.Lock()
+	defer c.lock.Unlock()
 	c.cl = append(c.cl, checker)
+	return c.running
 }
 
-// Check runs underlying checkers concurrently. Wait until all checker are finished.
-func (c *compositeChecker) Check(ctx context.Context) Status {
+// start marks checker loops are running, returns all checkers to run
+func (c *compositeChecker) start() []*unaryChecker {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.running = true
+	return c.cl
+}
+
+func (c *compositeChecker) checkers() []*unaryChecker {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	return c.cl
+}
+
+// CheckAll runs underlying checkers concurrently. Wait until all checker are finished.
+func (c *compositeChecker) CheckAll(ctx context.Context) {
 	wg := &sync.WaitGroup{}
-	finalStatus := Status{
-		State: StateHealthy,
-	}
-	for _, c := range c.cl {
+	for _, c := range c.checkers() {
 		wg.Add(1)
-		go func(c checkController) {
+		go func(c *unaryChecker) {
 			defer wg.Done()
-			status := c.Check(ctx)
-			if status.State != StateHealthy {
-				if status.State == StateUnhealthy || finalStatus.State == StateHealthy {
-					finalStatus = status
-				}
-			}
+			c.Check(ctx)
 		}(c)
 	}
 	wg.Wait()
+}
+
+// Status returns last status of checkers. The worst status is returned, with all failures.
+func (c *compositeChecker) Status() Status {
+	return c.filterStatus(func(*unaryChecker) bool { return true })
+}
+
+// ProbeStatus returns last status of checkers used by probe
+func (c *compositeChecker) ProbeStatus(probe Probe) Status {
+	return c.filterStatus(func(c *unaryChecker) bool { return c.opts.probes&probe != 0 })
+}
+
+func (c *compositeChecker) filterStatus(filter func(*unaryChecker) bool) Status {
+	finalStatus := Status{State: StateHealthy}
+	var failures []Status
+	for _, c := range c.checkers() {
+		if !filter(c) {
+			continue
+		}
+		status := c.effectiveStatus()
+		if status.State != StateHealthy {
+			failures = append(failures, status)
+		}
+		finalStatus = worseStatus(finalStatus, status)
+	}
+	finalStatus.Failures = failures
 	return finalStatus
 }
 
+// CheckStatus returns last status of checkers with name.
+// If there are multiple checkers with same name, the worst status is returned.
+func (c *compositeChecker) CheckStatus(name string) (Status, bool) {
+	found := false
+	finalStatus := Status{State: StateHealthy}
+	for _, c := range c.checkers() {
+		if c.name != name {
+			continue
+		}
+		found = true
+		finalStatus = worseStatus(finalStatus, c.LastStatus())
+	}
+	return finalStatus, found
+}
+
+// Reports returns detailed status of all checkers
+func (c *compositeChecker) Reports() []CheckReport {
+	checkers := c.checkers()
+	reports := make([]CheckReport, len(checkers))
+	for i, c := range checkers {
+		reports[i] = c.Report()
+	}
+	return reports
+}
+
 type runOptions struct {
-	name         string
-	interval     time.Duration
-	timeout      time.Duration
-	panicOnError bool
+	name           string
+	interval       time.Duration
+	timeout        time.Duration
+	startupTimeout time.Duration
+	panicOnError   bool
 }
 
 // RunOption is used by a health runner. A runner will apply these options
@@ -215,24 +461,33 @@ func WithName(name string) RunOption {
 	}
 }
 
-// WithCheckInterval sets the period of health check interval.
+// WithCheckInterval sets the default period of health check interval.
 func WithCheckInterval(interval time.Duration) RunOption {
 	return func(options *runOptions) {
 		options.interval = interval
 	}
 }
 
-// WithCheckTimeout sets the timeout of health check.
+// WithCheckTimeout sets the default timeout of health check.
 func WithCheckTimeout(d time.Duration) RunOption {
 	return func(options *runOptions) {
 		options.timeout = d
 	}
 }
 
+// WithStartupTimeout sets how long health check is retried until startup checks are healthy.
+// Health check runs only once on start by default.
+func WithStartupTimeout(d time.Duration) RunOption {
+	return func(options *runOptions) {
+		options.startupTimeout = d
+	}
+}
+
 // WithPanicOnError indicates if we need to panic and crash the process when there is
 // any error during health check.
 //
 // If there is no error during health check, this option doesn't have any affect.
+// Prefer liveness checks, so that the process is restarted by orchestrator.
 func WithPanicOnError(b bool) RunOption {
 	return func(options *runOptions) {
 		options.panicOnError = b
@@ -250,93 +505,130 @@ func applyOptions(options ...RunOption) *runOptions {
 	return opts
 }
 
-// uint32 boolean values
-const (
-	uFalse = iota
-	uTrue
-)
-
 type runnerImpl struct {
 	Runner
 
-	opts              *runOptions
-	stop              chan struct{}
-	c                 *compositeChecker
-	once              sync.Once
-	lastStatus        Status
-	runningCheckCycle uint32
-	reportChan        chan Status
+	opts       *runOptions
+	stop       chan struct{}
+	c          *compositeChecker
+	updateLock sync.Mutex
+	statusLock sync.RWMutex
+	lastStatus Status
+	started    atomic.Bool
+	reportChan chan Status
 }
 
 // NewRunner creates a health runner.
 func NewRunner(options ...RunOption) Runner {
 	opts := applyOptions(options...)
 	return &runnerImpl{
-		opts:              opts,
-		stop:              make(chan struct{}),
-		c:                 newCompositeCheck(),
-		lastStatus:        Status{State: StateUnknown},
-		runningCheckCycle: uFalse,
-		reportChan:        make(chan Status, 1),
+		opts:       opts,
+		stop:       make(chan struct{}),
+		c:          newCompositeCheck(),
+		lastStatus: Status{State: StateUnknown},
+		reportChan: make(chan Status, 1),
 	}
 }
 
 func (r *runnerImpl) LastStatus() Status {
+	r.statusLock.RLock()
+	defer r.statusLock.RUnlock()
 	return r.lastStatus
 }
 
+func (r *runnerImpl) CheckStatus(name string) (Status, bool) {
+	return r.c.CheckStatus(name)
+}
+
+func (r *runnerImpl) ProbeStatus(probe Probe) Status {
+	if probe == ProbeStartup && r.started.Load() {
+		return Status{State: StateHealthy}
+	}
+	return r.c.ProbeStatus(probe)
+}
+
+func (r *runnerImpl) Report() Report {
+	return Report{
+		Name:   r.opts.name,
+		State:  r.LastStatus().State,
+		Checks: r.c.Reports(),
+	}
+}
+
 func (r *runnerImpl) StatusReportChan() chan Status {
 	return r.reportChan
 }
 
-func (r *runnerImpl) AddCheck(name string, cf CheckFunc) {
-	checker := newUnaryCheck(name, cf)
-	r.c.AddChecker(checker)
+func (r *runnerImpl) AddCheck(name string, cf CheckFunc, options ...CheckOption) {
+	checker := newUnaryCheck(name, cf, r.opts, options...)
+	if r.c.AddChecker(checker) {
+		go r.runChecker(checker)
+	}
 }
 
 func (r *runnerImpl) Start() Status {
-	// Run health check immediately, wait for result
-	r.runHealthCheck()
+	// Run health check immediately, wait for result and retry until startup checks are available
+	deadline := time.Now().Add(r.opts.startupTimeout)
+	for {
+		r.c.CheckAll(context.Background())
+		r.updateStatus(true)
+		status := r.c.ProbeStatus(ProbeStartup)
+		if status.State.IsAvailable() {
+			r.started.Store(true)
+			break
+		}
+		if time.Now().Add(startupRetryInterval).After(deadline) {
+			break
+		}
+		log.Logger.Warn().Str("name", r.opts.name).Str("source", status.Source).Err(status.Error).
+			Msg("health_check_startup_retry")
+		select {
+		case <-time.After(startupRetryInterval):
+		case <-r.stop:
+			return r.LastStatus()
+		}
+	}
 
-	// Run health check loop in new go routine
-	go r.run()
+	// Run health check loops in new go routines
+	for _, checker := range r.c.start() {
+		go r.runChecker(checker)
+	}
 
 	// Return health check result in first round
-	return r.lastStatus
+	return r.LastStatus()
 }
 
 func (r *runnerImpl) Stop() {
 	close(r.stop)
 }
 
-// run starts an infinite loop to check the health status periodically.
-func (r *runnerImpl) run() {
-	r.once.Do(func() {
-		tick := time.Tick(r.opts.interval)
-		for {
-			select {
-			case <-tick:
-				go r.runHealthCheck()
-			case <-r.stop:
-				return
-			}
+// runChecker starts an infinite loop to check the health status of checker periodically.
+func (r *runnerImpl) runChecker(checker *unaryChecker) {
+	ticker := time.NewTicker(checker.opts.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			_, changed := checker.Check(context.Background())
+			r.updateStatus(changed)
+		case <-r.stop:
+			return
 		}
-	})
-}
-
-// runHealthCheck runs single health check cycle
-func (r *runnerImpl) runHealthCheck() {
-	if !atomic.CompareAndSwapUint32(&r.runningCheckCycle, uFalse, uTrue) {
-		log.Logger.Warn().Str("name", r.opts.name).Msg("health_check_busy")
-		return
 	}
-	defer atomic.CompareAndSwapUint32(&r.runningCheckCycle, uTrue, uFalse)
+}
 
-	var status Status
-	ctx, cancel := context.WithTimeout(context.Background(), r.opts.timeout)
-	defer cancel()
-	status = r.c.Check(ctx)
-	if status.Equal(&r.lastStatus) {
+// updateStatus updates status of runner by last status of checkers.
+// Status is reported if it is changed, or checksChanged is true.
+func (r *runnerImpl) updateStatus(checksChanged bool) {
+	r.updateLock.Lock()
+	defer r.updateLock.Unlock()
+
+	status := r.c.Status()
+	lastStatus := r.LastStatus()
+	if status.Equal(&lastStatus) {
+		if checksChanged {
+			r.report(status)
+		}
 		return
 	}
 
@@ -346,10 +638,17 @@ func (r *runnerImpl) runHealthCheck() {
 		if status.Error != nil {
 			ctxLogger = ctxLogger.Err(status.Error)
 		}
+		sources := make([]string, len(status.Failures))
+		for i, failure := range status.Failures {
+			sources[i] = failure.Source
+		}
+		ctxLogger = ctxLogger.Strs("failures", sources)
 	}
 	l := ctxLogger.Logger()
 	if status.State == StateHealthy {
 		l.Info().Msg("health_check_healthy")
+	} else if status.State == StateDegraded {
+		l.Warn().Msg("health_check_degraded")
 	} else if status.State == StateUnknown {
 		l.Error().Msg("health_check_unknown_state")
 	} else {
@@ -362,11 +661,25 @@ func (r *runnerImpl) runHealthCheck() {
 		}
 	}
 
+	r.statusLock.Lock()
 	r.lastStatus = status
+	r.statusLock.Unlock()
+	r.report(status)
+}
+
+func (r *runnerImpl) report(status Status) {
 	select {
 	case r.reportChan <- status:
 		// success
 	default:
-		l.Error().Msg("health_check_status_report_dropped_for_blocking")
+		log.Logger.Error().Str("name", r.opts.name).Msg("health_check_status_report_dropped_for_blocking")
+	}
+}
+
+// worseStatus returns the status with worse state: unhealthy, unknown, degraded, then healthy
+func worseStatus(a Status, b Status) Status {
+	if b.State.severity() > a.State.severity() {
+		return b
 	}
+	return a
 }
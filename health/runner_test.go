@@ -0,0 +1,106 @@
+package health
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/frame-go/framego/errors"
+	"github.com/frame-go/framego/log"
+)
+
+func TestMain(m *testing.M) {
+	log.Init("fatal", false, false)
+	os.Exit(m.Run())
+}
+
+func TestRunnerCriticalCheck(t *testing.T) {
+	failing := func(ctx context.Context) error {
+		return errors.New("connection_error")
+	}
+	healthy := func(ctx context.Context) error {
+		return nil
+	}
+
+	r := NewRunner(WithName("test"))
+	r.AddCheck("healthy", healthy)
+	r.AddCheck("non_critical", failing, WithCritical(false))
+	status := r.Start()
+	r.Stop()
+	if status.State != StateDegraded {
+		t.Errorf("state %v != degraded, error: %v", status.State, status.Error)
+	}
+	if status.Source != "non_critical" {
+		t.Errorf("source %v != non_critical", status.Source)
+	}
+
+	r = NewRunner(WithName("test"))
+	r.AddCheck("non_critical", failing, WithCritical(false))
+	r.AddCheck("critical", failing)
+	status = r.Start()
+	r.Stop()
+	if status.State != StateUnhealthy {
+		t.Errorf("state %v != unhealthy", status.State)
+	}
+	if status.Source != "critical" {
+		t.Errorf("source %v != critical", status.Source)
+	}
+	if len(status.Failures) != 2 {
+		t.Errorf("failures %v != 2", len(status.Failures))
+	}
+}
+
+func TestCheckConfigCritical(t *testing.T) {
+	failing := func(ctx context.Context) error {
+		return errors.New("connection_error")
+	}
+	nonCritical := false
+	var tests = []struct {
+		config CheckConfig
+		state  State
+	}{
+		{CheckConfig{Enabled: true}, StateUnhealthy},
+		{CheckConfig{Enabled: true, Critical: &nonCritical}, StateDegraded},
+	}
+	for _, test := range tests {
+		options, err := test.config.Options()
+		if err != nil {
+			t.Fatal(err)
+		}
+		r := NewRunner(WithName("test"))
+		r.AddCheck("client", failing, options...)
+		status := r.Start()
+		r.Stop()
+		if status.State != test.state {
+			t.Errorf("state of critical %v: %v != %v", test.config.Critical, status.State, test.state)
+		}
+	}
+}
+
+func TestRunnerCheckTimeout(t *testing.T) {
+	slow := func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}
+
+	r := NewRunner(WithName("test"), WithCheckTimeout(time.Hour))
+	r.AddCheck("slow", slow, WithTimeout(10*time.Millisecond), WithInterval(time.Hour))
+	status := r.Start()
+	r.Stop()
+	if status.State != StateUnknown {
+		t.Errorf("state %v != unknown", status.State)
+	}
+
+	report := r.Report()
+	if len(report.Checks) != 1 {
+		t.Fatalf("checks %v != 1", len(report.Checks))
+	}
+	check := report.Checks[0]
+	if check.Timeout != 10*time.Millisecond || check.Interval != time.Hour {
+		t.Errorf("timeout %v, interval %v", check.Timeout, check.Interval)
+	}
+	if check.Error == nil || check.LastCheckTime.IsZero() || check.LastTransitionTime.IsZero() {
+		t.Errorf("unexpected report %+v", check)
+	}
+}
@@ -2,54 +2,99 @@ package health
 
 import (
 	"context"
+	"io/fs"
 	"sync"
 
 	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
 
 	"github.com/frame-go/framego/health/proto"
 )
 
 type Server = grpc_health_v1.HealthServer
 
+type serverOptions struct {
+	serviceLookup func(name string) bool
+}
+
+// ServerOption is used by health server.
+type ServerOption func(*serverOptions)
+
+// WithServiceLookup sets function to check whether gRPC service is registered.
+// Registered services without their own health checks report the overall health status.
+func WithServiceLookup(lookup func(name string) bool) ServerOption {
+	return func(options *serverOptions) {
+		options.serviceLookup = lookup
+	}
+}
+
 type healthServer struct {
 	grpc_health_v1.UnimplementedHealthServer
 
 	reporter         Reporter
+	opts             serverOptions
 	statusReportLock sync.Mutex
 	statusChans      []chan Status
 }
 
-func fillProtoStatus(resp *grpc_health_v1.HealthCheckResponse, status Status) {
+func toProtoStatus(status Status) grpc_health_v1.HealthCheckResponse_ServingStatus {
 	switch status.State {
-	case StateHealthy:
-		resp.Status = grpc_health_v1.HealthCheckResponse_SERVING
+	case StateHealthy, StateDegraded:
+		return grpc_health_v1.HealthCheckResponse_SERVING
 	case StateUnhealthy:
-		resp.Status = grpc_health_v1.HealthCheckResponse_NOT_SERVING
+		return grpc_health_v1.HealthCheckResponse_NOT_SERVING
 	default:
-		resp.Status = grpc_health_v1.HealthCheckResponse_SERVICE_UNKNOWN
+		return grpc_health_v1.HealthCheckResponse_UNKNOWN
 	}
 }
 
+// serviceStatus returns health status of service name. Empty name is the overall health status.
+// Returns false if service is unknown.
+func (s *healthServer) serviceStatus(name string) (grpc_health_v1.HealthCheckResponse_ServingStatus, bool) {
+	if name == "" {
+		return toProtoStatus(s.reporter.LastStatus()), true
+	}
+	if checkStatus, ok := s.reporter.CheckStatus(name); ok {
+		return toProtoStatus(checkStatus), true
+	}
+	if s.opts.serviceLookup != nil && s.opts.serviceLookup(name) {
+		return toProtoStatus(s.reporter.LastStatus()), true
+	}
+	return grpc_health_v1.HealthCheckResponse_SERVICE_UNKNOWN, false
+}
+
 func (s *healthServer) Check(ctx context.Context, req *grpc_health_v1.HealthCheckRequest) (*grpc_health_v1.HealthCheckResponse, error) {
-	resp := &grpc_health_v1.HealthCheckResponse{}
-	fillProtoStatus(resp, s.reporter.LastStatus())
-	return resp, nil
+	servingStatus, ok := s.serviceStatus(req.GetService())
+	if !ok {
+		return nil, status.Error(codes.NotFound, "unknown service")
+	}
+	return &grpc_health_v1.HealthCheckResponse{Status: servingStatus}, nil
 }
 
+// Watch sends health status of service once it is changed. SERVICE_UNKNOWN is sent for unknown service.
 func (s *healthServer) Watch(request *grpc_health_v1.HealthCheckRequest, stream grpc_health_v1.Health_WatchServer) error {
-	resp := &grpc_health_v1.HealthCheckResponse{}
 	ch := s.newStatusChan()
 	defer s.removeStatusChan(ch)
-	for status := range ch {
-		fillProtoStatus(resp, status)
-		err := stream.Send(resp)
+	lastServingStatus := grpc_health_v1.HealthCheckResponse_ServingStatus(-1)
+	for {
+		select {
+		case <-ch:
+		case <-stream.Context().Done():
+			return stream.Context().Err()
+		}
+		servingStatus, _ := s.serviceStatus(request.GetService())
+		if servingStatus == lastServingStatus {
+			continue
+		}
+		lastServingStatus = servingStatus
+		err := stream.Send(&grpc_health_v1.HealthCheckResponse{Status: servingStatus})
 		if err != nil {
 			return err
 		}
 	}
-	return nil
 }
 
 func (s *healthServer) newStatusChan() chan Status {
@@ -67,26 +112,33 @@ func (s *healthServer) removeStatusChan(ch chan Status) {
 	for i := range s.statusChans {
 		if s.statusChans[i] == ch {
 			s.statusChans = append(s.statusChans[:i], s.statusChans[i+1:]...)
+			break
 		}
 	}
-	close(ch)
 }
 
 func (s *healthServer) broadcastStatus() {
 	for status := range s.reporter.StatusReportChan() {
 		s.statusReportLock.Lock()
 		for _, ch := range s.statusChans {
-			ch <- status
+			// status is only used as signal, the pending one is enough if watcher is busy
+			select {
+			case ch <- status:
+			default:
+			}
 		}
 		s.statusReportLock.Unlock()
 	}
 }
 
-func NewServer(healthReporter Reporter) Server {
+func NewServer(healthReporter Reporter, options ...ServerOption) Server {
 	s := &healthServer{
 		reporter:    healthReporter,
 		statusChans: make([]chan Status, 0),
 	}
+	for _, o := range options {
+		o(&s.opts)
+	}
 	go s.broadcastStatus()
 	return s
 }
@@ -98,3 +150,8 @@ func RegisterServer(s grpc.ServiceRegistrar, srv Server) {
 func RegisterHandlerClient(ctx context.Context, mux *runtime.ServeMux, conn grpc.ClientConnInterface) error {
 	return proto.RegisterHealthHandlerClient(ctx, mux, grpc_health_v1.NewHealthClient(conn))
 }
+
+// OpenAPI returns OpenAPI document of health gateway registered by RegisterHandlerClient
+func OpenAPI() fs.FS {
+	return proto.OpenAPI
+}
@@ -0,0 +1,93 @@
+package health
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+
+	"github.com/frame-go/framego/errors"
+)
+
+type watchStream struct {
+	grpc.ServerStream
+
+	ctx       context.Context
+	responses chan grpc_health_v1.HealthCheckResponse_ServingStatus
+}
+
+func (s *watchStream) Context() context.Context {
+	return s.ctx
+}
+
+func (s *watchStream) Send(resp *grpc_health_v1.HealthCheckResponse) error {
+	s.responses <- resp.GetStatus()
+	return nil
+}
+
+func TestServerServiceStatus(t *testing.T) {
+	var failed atomic.Bool
+	r := NewRunner(WithName("test"), WithCheckInterval(10*time.Millisecond))
+	r.AddCheck("sample.Sample", func(ctx context.Context) error {
+		if failed.Load() {
+			return errors.New("sample_error")
+		}
+		return nil
+	})
+	r.AddCheck("cache.main", func(ctx context.Context) error {
+		return errors.New("connection_error")
+	}, WithCritical(false))
+	s := NewServer(r, WithServiceLookup(func(name string) bool {
+		return name == "sample.Other"
+	}))
+	r.Start()
+	defer r.Stop()
+
+	ctx := context.Background()
+	expected := map[string]grpc_health_v1.HealthCheckResponse_ServingStatus{
+		"":              grpc_health_v1.HealthCheckResponse_SERVING,
+		"sample.Sample": grpc_health_v1.HealthCheckResponse_SERVING,
+		"sample.Other":  grpc_health_v1.HealthCheckResponse_SERVING,
+		"cache.main":    grpc_health_v1.HealthCheckResponse_NOT_SERVING,
+	}
+	for name, servingStatus := range expected {
+		resp, err := s.Check(ctx, &grpc_health_v1.HealthCheckRequest{Service: name})
+		if err != nil {
+			t.Errorf("check %q error: %v", name, err)
+			continue
+		}
+		if resp.GetStatus() != servingStatus {
+			t.Errorf("status of %q: %v != %v", name, resp.GetStatus(), servingStatus)
+		}
+	}
+	_, err := s.Check(ctx, &grpc_health_v1.HealthCheckRequest{Service: "unknown"})
+	if status.Code(err) != codes.NotFound {
+		t.Errorf("check unknown service error %v != NotFound", err)
+	}
+
+	watchCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	stream := &watchStream{ctx: watchCtx, responses: make(chan grpc_health_v1.HealthCheckResponse_ServingStatus, 10)}
+	unknownStream := &watchStream{ctx: watchCtx, responses: make(chan grpc_health_v1.HealthCheckResponse_ServingStatus, 10)}
+	go func() { _ = s.Watch(&grpc_health_v1.HealthCheckRequest{Service: "sample.Sample"}, stream) }()
+	go func() { _ = s.Watch(&grpc_health_v1.HealthCheckRequest{Service: "unknown"}, unknownStream) }()
+	expectWatch := func(stream *watchStream, servingStatus grpc_health_v1.HealthCheckResponse_ServingStatus) {
+		select {
+		case v := <-stream.responses:
+			if v != servingStatus {
+				t.Errorf("watch status %v != %v", v, servingStatus)
+			}
+		case <-time.After(time.Second):
+			t.Errorf("watch status %v timeout", servingStatus)
+		}
+	}
+	expectWatch(stream, grpc_health_v1.HealthCheckResponse_SERVING)
+	expectWatch(unknownStream, grpc_health_v1.HealthCheckResponse_SERVICE_UNKNOWN)
+	failed.Store(true)
+	expectWatch(stream, grpc_health_v1.HealthCheckResponse_NOT_SERVING)
+}
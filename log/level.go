@@ -0,0 +1,239 @@
+package log
+
+import (
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/rs/zerolog"
+
+	"github.com/frame-go/framego/errors"
+)
+
+// Components with log level changeable at runtime
+const (
+	ComponentGrpc   = "grpc"
+	ComponentGorm   = "gorm"
+	ComponentRedis  = "redis"
+	ComponentPulsar = "pulsar"
+	ComponentGin    = "gin"
+	ComponentAppmgr = "appmgr"
+)
+
+// Levels is log levels of global and components.
+// Empty global level is not changed, and empty level of component follows global level.
+type Levels struct {
+	Global     string            `json:"global" mapstructure:"global"`
+	Components map[string]string `json:"components" mapstructure:"components"`
+}
+
+// levelSnapshot is immutable levels read by hooks of loggers
+type levelSnapshot struct {
+	global     zerolog.Level
+	components map[string]zerolog.Level
+}
+
+func (s *levelSnapshot) level(component string) zerolog.Level {
+	level, ok := s.components[component]
+	if !ok {
+		return s.global
+	}
+	return level
+}
+
+// levelHook discards events below level of component
+type levelHook struct {
+	component string
+}
+
+func (h levelHook) Run(e *zerolog.Event, level zerolog.Level, _ string) {
+	if level < levels.Load().(*levelSnapshot).level(h.component) {
+		e.Discard()
+	}
+}
+
+var levels atomic.Value
+
+var levelLock sync.Mutex
+var revertTimer *time.Timer
+var revertLevels *Levels
+
+var componentLock sync.Mutex
+var baseLogger *zerolog.Logger
+var componentLoggers = map[string]*zerolog.Logger{
+	ComponentGrpc:   {},
+	ComponentGorm:   {},
+	ComponentRedis:  {},
+	ComponentPulsar: {},
+	ComponentGin:    {},
+	ComponentAppmgr: {},
+}
+
+func init() {
+	levels.Store(&levelSnapshot{global: zerolog.InfoLevel, components: map[string]zerolog.Level{}})
+}
+
+// Component gets logger of component, whose level can be changed by SetLevels.
+// The logger is available after Init, and keeps the same pointer after Init.
+func Component(name string) *zerolog.Logger {
+	componentLock.Lock()
+	defer componentLock.Unlock()
+	logger, ok := componentLoggers[name]
+	if !ok {
+		logger = &zerolog.Logger{}
+		if baseLogger != nil {
+			*logger = baseLogger.Hook(levelHook{component: name})
+		}
+		componentLoggers[name] = logger
+	}
+	return logger
+}
+
+// initLevels sets global level, and rebuilds loggers of components by base logger
+func initLevels(base zerolog.Logger, level zerolog.Level) {
+	levelLock.Lock()
+	levels.Store(&levelSnapshot{global: level, components: map[string]zerolog.Level{}})
+	applyGlobalLevel()
+	levelLock.Unlock()
+
+	componentLock.Lock()
+	defer componentLock.Unlock()
+	baseLogger = &base
+	for name, logger := range componentLoggers {
+		*logger = base.Hook(levelHook{component: name})
+	}
+}
+
+// GetLevels gets current log levels of global and components with level set
+func GetLevels() Levels {
+	snapshot := levels.Load().(*levelSnapshot)
+	result := Levels{
+		Global:     snapshot.global.String(),
+		Components: make(map[string]string, len(snapshot.components)),
+	}
+	for name, level := range snapshot.components {
+		result.Components[name] = level.String()
+	}
+	return result
+}
+
+// GetComponents gets names of components sorted
+func GetComponents() []string {
+	componentLock.Lock()
+	defer componentLock.Unlock()
+	names := make([]string, 0, len(componentLoggers))
+	for name := range componentLoggers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// SetLevels changes log levels of global and components at runtime, the change is logged with source.
+// If ttl is positive, levels are reverted after ttl to levels before the first change not reverted yet.
+// Change without ttl cancels pending revert.
+func SetLevels(newLevels Levels, ttl time.Duration, source string) error {
+	levelLock.Lock()
+	defer levelLock.Unlock()
+	previous := GetLevels()
+	err := setLevels(&newLevels)
+	if err != nil {
+		return err
+	}
+	if revertTimer != nil {
+		revertTimer.Stop()
+		revertTimer = nil
+	}
+	if ttl > 0 {
+		if revertLevels == nil {
+			revertLevels = fullLevels(&previous)
+		}
+		revertTo := revertLevels
+		revertTimer = time.AfterFunc(ttl, func() {
+			revert(revertTo)
+		})
+	} else {
+		revertLevels = nil
+	}
+	logLevelsChanged(source, ttl)
+	return nil
+}
+
+// fullLevels returns levels resetting all components not set in levels
+func fullLevels(l *Levels) *Levels {
+	result := &Levels{Global: l.Global, Components: make(map[string]string)}
+	for _, name := range GetComponents() {
+		result.Components[name] = l.Components[name]
+	}
+	return result
+}
+
+func revert(revertTo *Levels) {
+	levelLock.Lock()
+	defer levelLock.Unlock()
+	if revertLevels != revertTo {
+		// reverted or changed by later calls
+		return
+	}
+	_ = setLevels(revertTo)
+	revertTimer = nil
+	revertLevels = nil
+	logLevelsChanged("ttl_revert", 0)
+}
+
+func setLevels(newLevels *Levels) error {
+	snapshot := levels.Load().(*levelSnapshot)
+	result := &levelSnapshot{global: snapshot.global, components: make(map[string]zerolog.Level)}
+	for name, level := range snapshot.components {
+		result.components[name] = level
+	}
+	if newLevels.Global != "" {
+		level, err := zerolog.ParseLevel(newLevels.Global)
+		if err != nil {
+			return errors.Wrap(err, "parse_log_level_error").With("level", newLevels.Global)
+		}
+		result.global = level
+	}
+	for name, levelName := range newLevels.Components {
+		componentLock.Lock()
+		_, ok := componentLoggers[name]
+		componentLock.Unlock()
+		if !ok {
+			return errors.New("unknown_log_component").With("component", name)
+		}
+		if levelName == "" {
+			delete(result.components, name)
+			continue
+		}
+		level, err := zerolog.ParseLevel(levelName)
+		if err != nil {
+			return errors.Wrap(err, "parse_log_level_error").With("component", name).With("level", levelName)
+		}
+		result.components[name] = level
+	}
+	levels.Store(result)
+	applyGlobalLevel()
+	return nil
+}
+
+// applyGlobalLevel sets zerolog global level to the lowest level, so that loggers filter events by hooks
+func applyGlobalLevel() {
+	snapshot := levels.Load().(*levelSnapshot)
+	lowest := snapshot.global
+	for _, level := range snapshot.components {
+		if level < lowest {
+			lowest = level
+		}
+	}
+	zerolog.SetGlobalLevel(lowest)
+}
+
+func logLevelsChanged(source string, ttl time.Duration) {
+	if Logger == nil {
+		return
+	}
+	current := GetLevels()
+	Logger.Log().Str("source", source).Str("global", current.Global).Interface("components", current.Components).
+		Dur("ttl", ttl).Msg("log_level_changed")
+}
@@ -0,0 +1,54 @@
+package log
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+func TestSetLevels(t *testing.T) {
+	var buf bytes.Buffer
+	initLevels(zerolog.New(&buf), zerolog.InfoLevel)
+	gorm := Component(ComponentGorm)
+	redis := Component(ComponentRedis)
+
+	gorm.Debug().Msg("gorm_debug")
+	if buf.Len() != 0 {
+		t.Errorf("debug log should be discarded: %s", buf.String())
+	}
+
+	err := SetLevels(Levels{Components: map[string]string{ComponentGorm: "debug"}}, 50*time.Millisecond, "test")
+	if err != nil {
+		t.Fatalf("set levels error: %v", err)
+	}
+	gorm.Debug().Msg("gorm_debug")
+	if !bytes.Contains(buf.Bytes(), []byte("gorm_debug")) {
+		t.Errorf("debug log of gorm should be written")
+	}
+	buf.Reset()
+	redis.Debug().Msg("redis_debug")
+	if buf.Len() != 0 {
+		t.Errorf("debug log of redis should be discarded: %s", buf.String())
+	}
+
+	err = SetLevels(Levels{Components: map[string]string{"unknown": "debug"}}, 0, "test")
+	if err == nil {
+		t.Errorf("unknown component should fail")
+	}
+	err = SetLevels(Levels{Global: "verbose"}, 0, "test")
+	if err == nil {
+		t.Errorf("unknown level should fail")
+	}
+
+	time.Sleep(200 * time.Millisecond)
+	levels := GetLevels()
+	if levels.Global != "info" || len(levels.Components) != 0 {
+		t.Errorf("levels %+v are not reverted", levels)
+	}
+	gorm.Debug().Msg("gorm_debug")
+	if buf.Len() != 0 {
+		t.Errorf("debug log should be discarded after revert: %s", buf.String())
+	}
+}
@@ -28,7 +28,6 @@ func Init(level string, debug bool, beauty bool) {
 		}
 	}
 
-	zerolog.SetGlobalLevel(logLevel)
 	zerolog.TimeFieldFormat = time.RFC3339Nano
 	zerolog.DurationFieldUnit = time.Nanosecond
 	zerolog.DurationFieldInteger = true
@@ -40,6 +39,8 @@ func Init(level string, debug bool, beauty bool) {
 	} else {
 		output = os.Stdout
 	}
-	zlog.Logger = zerolog.New(output).With().Timestamp().Logger()
+	base := zerolog.New(output).With().Timestamp().Logger()
+	initLevels(base, logLevel)
+	zlog.Logger = base.Hook(levelHook{})
 	Logger = &zlog.Logger
 }
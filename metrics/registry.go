@@ -0,0 +1,160 @@
+package metrics
+
+import (
+	"strings"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/frame-go/framego/errors"
+	"github.com/frame-go/framego/log"
+)
+
+// Options of metrics registry
+type Options struct {
+	// Namespace is the prefix of metric names, invalid characters are replaced by "_"
+	Namespace string
+
+	// ConstLabels are labels added to all metrics
+	ConstLabels prometheus.Labels
+
+	// Registerer registers metrics, a new registry is used if nil
+	Registerer prometheus.Registerer
+
+	// Gatherer gathers registered metrics, required if Registerer is set
+	Gatherer prometheus.Gatherer
+}
+
+// Registry registers metrics with namespace and constant labels
+type Registry struct {
+	namespace  string
+	registerer prometheus.Registerer
+	gatherer   prometheus.Gatherer
+}
+
+// NewRegistry creates metrics registry by options
+func NewRegistry(opts *Options) *Registry {
+	r := &Registry{
+		namespace:  SanitizeName(opts.Namespace),
+		registerer: opts.Registerer,
+		gatherer:   opts.Gatherer,
+	}
+	if r.registerer == nil {
+		registry := prometheus.NewRegistry()
+		r.registerer = registry
+		r.gatherer = registry
+	}
+	if len(opts.ConstLabels) > 0 {
+		r.registerer = prometheus.WrapRegistererWith(opts.ConstLabels, r.registerer)
+	}
+	return r
+}
+
+// Counter creates and registers counter with label names, or returns the registered counter with same name
+func (r *Registry) Counter(name string, help string, labelNames ...string) *prometheus.CounterVec {
+	counter := prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: r.namespace,
+		Name:      name,
+		Help:      help,
+	}, labelNames)
+	return register(r, counter)
+}
+
+// Gauge creates and registers gauge with label names, or returns the registered gauge with same name
+func (r *Registry) Gauge(name string, help string, labelNames ...string) *prometheus.GaugeVec {
+	gauge := prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: r.namespace,
+		Name:      name,
+		Help:      help,
+	}, labelNames)
+	return register(r, gauge)
+}
+
+// Histogram creates and registers histogram with buckets and label names,
+// or returns the registered histogram with same name. Use prometheus.DefBuckets if buckets is nil.
+func (r *Registry) Histogram(name string, help string, buckets []float64, labelNames ...string) *prometheus.HistogramVec {
+	histogram := prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: r.namespace,
+		Name:      name,
+		Help:      help,
+		Buckets:   buckets,
+	}, labelNames)
+	return register(r, histogram)
+}
+
+// Register registers custom collector, metric names of collector are not prefixed by namespace
+func (r *Registry) Register(collector prometheus.Collector) error {
+	err := r.registerer.Register(collector)
+	if err != nil {
+		return errors.Wrap(err, "register_metric_error")
+	}
+	return nil
+}
+
+// Registerer returns registerer adding constant labels
+func (r *Registry) Registerer() prometheus.Registerer {
+	return r.registerer
+}
+
+// PrefixedRegisterer returns registerer adding constant labels and prefixing metric names of collectors by namespace,
+// for libraries registering built-in metrics
+func (r *Registry) PrefixedRegisterer() prometheus.Registerer {
+	if r.namespace == "" {
+		return r.registerer
+	}
+	return prometheus.WrapRegistererWithPrefix(r.namespace+"_", r.registerer)
+}
+
+// Gatherer returns gatherer of registered metrics
+func (r *Registry) Gatherer() prometheus.Gatherer {
+	return r.gatherer
+}
+
+// Name returns full name of metric with namespace
+func (r *Registry) Name(name string) string {
+	return prometheus.BuildFQName(r.namespace, "", name)
+}
+
+// Value returns sum of counter or gauge values matching labels, refer to Value
+func (r *Registry) Value(name string, labels prometheus.Labels) (float64, error) {
+	return Value(r.gatherer, r.Name(name), labels)
+}
+
+// HistogramValue returns sum of histogram sample counts and sums matching labels, refer to HistogramValue
+func (r *Registry) HistogramValue(name string, labels prometheus.Labels) (uint64, float64, error) {
+	return HistogramValue(r.gatherer, r.Name(name), labels)
+}
+
+func register[T prometheus.Collector](r *Registry, collector T) T {
+	err := r.registerer.Register(collector)
+	if err == nil {
+		return collector
+	}
+	var registeredErr prometheus.AlreadyRegisteredError
+	if errors.As(err, &registeredErr) {
+		if existing, ok := registeredErr.ExistingCollector.(T); ok {
+			return existing
+		}
+	}
+	// collector still works without being exported
+	errors.LogError(log.Logger.Error(), errors.Wrap(err, "register_metric_error")).Msg("metrics_registry_error")
+	return collector
+}
+
+// SanitizeName replaces invalid characters of metric name by "_"
+func SanitizeName(name string) string {
+	var b strings.Builder
+	for i, c := range name {
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c == '_', c == ':':
+			b.WriteRune(c)
+		case c >= '0' && c <= '9':
+			if i == 0 {
+				b.WriteByte('_')
+			}
+			b.WriteRune(c)
+		default:
+			b.WriteByte('_')
+		}
+	}
+	return b.String()
+}
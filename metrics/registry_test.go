@@ -0,0 +1,76 @@
+package metrics
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func TestRegistry(t *testing.T) {
+	r := NewRegistry(&Options{
+		Namespace:   "sample-app",
+		ConstLabels: prometheus.Labels{"app": "sample", "hostname": "host1"},
+	})
+	if r.Name("orders_total") != "sample_app_orders_total" {
+		t.Errorf("unexpected name %s", r.Name("orders_total"))
+	}
+
+	counter := r.Counter("orders_total", "Total orders.", "status")
+	counter.WithLabelValues("ok").Add(2)
+	counter.WithLabelValues("error").Inc()
+	// registering same metric returns the registered one
+	r.Counter("orders_total", "Total orders.", "status").WithLabelValues("ok").Inc()
+
+	value, err := r.Value("orders_total", prometheus.Labels{"status": "ok"})
+	if err != nil || value != 3 {
+		t.Errorf("unexpected value %v: %v", value, err)
+	}
+	value, err = r.Value("orders_total", prometheus.Labels{"app": "sample", "hostname": "host1"})
+	if err != nil || value != 4 {
+		t.Errorf("unexpected value with constant labels %v: %v", value, err)
+	}
+	if _, err = r.Value("orders_total", prometheus.Labels{"status": "unknown"}); err == nil {
+		t.Errorf("expect not found error")
+	}
+
+	r.Gauge("queue_size", "Size of queue.").WithLabelValues().Set(5)
+	value, err = r.Value("queue_size", nil)
+	if err != nil || value != 5 {
+		t.Errorf("unexpected gauge value %v: %v", value, err)
+	}
+
+	histogram := r.Histogram("latency_seconds", "Latency.", []float64{0.1, 1}, "method")
+	histogram.WithLabelValues("get").Observe(0.5)
+	histogram.WithLabelValues("get").Observe(1.5)
+	count, sum, err := r.HistogramValue("latency_seconds", prometheus.Labels{"method": "get"})
+	if err != nil || count != 2 || sum != 2 {
+		t.Errorf("unexpected histogram value %d %v: %v", count, sum, err)
+	}
+	if _, err = r.Value("latency_seconds", nil); err == nil {
+		t.Errorf("expect type error")
+	}
+
+	builtin := prometheus.NewCounter(prometheus.CounterOpts{Name: "lib_calls_total", Help: "Total calls."})
+	if err = r.PrefixedRegisterer().Register(builtin); err != nil {
+		t.Fatal(err)
+	}
+	builtin.Inc()
+	value, err = r.Value("lib_calls_total", prometheus.Labels{"app": "sample"})
+	if err != nil || value != 1 {
+		t.Errorf("unexpected value of prefixed collector %v: %v", value, err)
+	}
+}
+
+func TestSanitizeName(t *testing.T) {
+	cases := map[string]string{
+		"sample":      "sample",
+		"sample-app":  "sample_app",
+		"1app":        "_1app",
+		"app.v2:test": "app_v2:test",
+	}
+	for name, expected := range cases {
+		if SanitizeName(name) != expected {
+			t.Errorf("unexpected sanitized name %s of %s", SanitizeName(name), name)
+		}
+	}
+}
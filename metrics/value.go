@@ -0,0 +1,88 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+
+	"github.com/frame-go/framego/errors"
+)
+
+// Value gathers metrics and returns sum of counter or gauge values with full name matching labels.
+// A metric matches if it has all of the labels, other labels are ignored. It is used to check metrics in tests.
+func Value(gatherer prometheus.Gatherer, name string, labels prometheus.Labels) (float64, error) {
+	metrics, err := findMetrics(gatherer, name, labels)
+	if err != nil {
+		return 0, err
+	}
+	var value float64
+	for _, m := range metrics {
+		switch {
+		case m.GetCounter() != nil:
+			value += m.GetCounter().GetValue()
+		case m.GetGauge() != nil:
+			value += m.GetGauge().GetValue()
+		case m.GetUntyped() != nil:
+			value += m.GetUntyped().GetValue()
+		default:
+			return 0, errors.New("metric_not_counter_or_gauge").With("name", name)
+		}
+	}
+	return value, nil
+}
+
+// HistogramValue gathers metrics and returns sum of histogram sample counts and sums with full name matching labels.
+// A metric matches if it has all of the labels, other labels are ignored. It is used to check metrics in tests.
+func HistogramValue(gatherer prometheus.Gatherer, name string, labels prometheus.Labels) (uint64, float64, error) {
+	metrics, err := findMetrics(gatherer, name, labels)
+	if err != nil {
+		return 0, 0, err
+	}
+	var count uint64
+	var sum float64
+	for _, m := range metrics {
+		if m.GetHistogram() == nil {
+			return 0, 0, errors.New("metric_not_histogram").With("name", name)
+		}
+		count += m.GetHistogram().GetSampleCount()
+		sum += m.GetHistogram().GetSampleSum()
+	}
+	return count, sum, nil
+}
+
+func findMetrics(gatherer prometheus.Gatherer, name string, labels prometheus.Labels) ([]*dto.Metric, error) {
+	families, err := gatherer.Gather()
+	if err != nil {
+		return nil, errors.Wrap(err, "gather_metrics_error")
+	}
+	for _, family := range families {
+		if family.GetName() != name {
+			continue
+		}
+		var metrics []*dto.Metric
+		for _, m := range family.GetMetric() {
+			if matchLabels(m, labels) {
+				metrics = append(metrics, m)
+			}
+		}
+		if len(metrics) == 0 {
+			break
+		}
+		return metrics, nil
+	}
+	return nil, errors.New("metric_not_found").With("name", name).With("labels", labels)
+}
+
+func matchLabels(m *dto.Metric, labels prometheus.Labels) bool {
+	matched := 0
+	for _, pair := range m.GetLabel() {
+		value, ok := labels[pair.GetName()]
+		if !ok {
+			continue
+		}
+		if value != pair.GetValue() {
+			return false
+		}
+		matched++
+	}
+	return matched == len(labels)
+}
@@ -0,0 +1,242 @@
+// Package payloadlog provides request and response payload logging with redaction for gRPC and HTTP.
+package payloadlog
+
+import (
+	"bytes"
+	"encoding/json"
+	"path"
+	"strings"
+
+	"github.com/rs/zerolog"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+
+	"github.com/frame-go/framego/encoding/jsonpb"
+	"github.com/frame-go/framego/errors"
+)
+
+const (
+	// DefaultMaxSize is the default max size of logged payload in bytes
+	DefaultMaxSize = 4096
+
+	// RedactedValue replaces values of redacted fields
+	RedactedValue = "[REDACTED]"
+
+	// minCaptureSize is the min size of captured payload to parse for redaction
+	minCaptureSize = 1 << 20
+)
+
+// Config is configuration of request and response payload logging
+type Config struct {
+	// PayloadMethods are glob patterns of gRPC full method names or HTTP paths to log payloads.
+	// Payloads are not logged if empty.
+	PayloadMethods []string `json:"payload_methods"`
+
+	// MaxPayloadSize is the max size of logged payload in bytes, default is DefaultMaxSize.
+	// Payloads exceeding the size are truncated.
+	MaxPayloadSize int `json:"max_payload_size"`
+
+	// RedactFields are field names redacted at any depth, case-insensitive
+	RedactFields []string `json:"redact_fields"`
+
+	// RedactPaths are JSON paths of redacted fields from root, separated by ".".
+	// Paths pass through arrays, e.g. "users.password" redacts password of every user.
+	RedactPaths []string `json:"redact_paths"`
+}
+
+// Logger marshals request and response payloads for logging with sensitive fields redacted.
+// Proto fields with "debug_redact" option are always redacted.
+type Logger struct {
+	methods      []string
+	maxSize      int
+	redactFields map[string]struct{}
+	redactPaths  [][]string
+}
+
+// NewLogger creates payload logger by config
+func NewLogger(config *Config) (*Logger, error) {
+	for _, pattern := range config.PayloadMethods {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, errors.Wrap(err, "invalid_payload_method_pattern").With("pattern", pattern)
+		}
+	}
+	p := &Logger{
+		methods:      config.PayloadMethods,
+		maxSize:      config.MaxPayloadSize,
+		redactFields: make(map[string]struct{}, len(config.RedactFields)),
+		redactPaths:  make([][]string, 0, len(config.RedactPaths)),
+	}
+	if p.maxSize <= 0 {
+		p.maxSize = DefaultMaxSize
+	}
+	for _, field := range config.RedactFields {
+		p.redactFields[strings.ToLower(field)] = struct{}{}
+	}
+	for _, redactPath := range config.RedactPaths {
+		p.redactPaths = append(p.redactPaths, strings.Split(redactPath, "."))
+	}
+	return p, nil
+}
+
+// IsEnabled checks whether payloads of the gRPC full method or HTTP path are logged
+func (p *Logger) IsEnabled(method string) bool {
+	for _, pattern := range p.methods {
+		if ok, _ := path.Match(pattern, method); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// CaptureSize returns the size of payload should be captured in bytes.
+// Payloads are parsed up to capture size for redaction, then truncated to max size.
+func (p *Logger) CaptureSize() int {
+	if p.maxSize > minCaptureSize {
+		return p.maxSize + 1
+	}
+	return minCaptureSize + 1
+}
+
+// AddProto adds redacted JSON of proto message to log event
+func (p *Logger) AddProto(e *zerolog.Event, key string, msg interface{}) *zerolog.Event {
+	pm, ok := msg.(proto.Message)
+	if !ok || pm == nil {
+		return e
+	}
+	data, err := jsonpb.Marshal(pm)
+	if err != nil {
+		return e.Str(key+"_error", err.Error())
+	}
+	var v interface{}
+	if decodeJSON(data, &v) != nil {
+		return p.addString(e, key, data)
+	}
+	redactProto(pm.ProtoReflect().Descriptor(), v)
+	return p.addValue(e, key, v)
+}
+
+// AddJSON adds redacted JSON data to log event. Non-JSON data is added as string if no redaction configured,
+// otherwise only its size is added since it can not be redacted.
+func (p *Logger) AddJSON(e *zerolog.Event, key string, data []byte) *zerolog.Event {
+	if len(data) == 0 {
+		return e
+	}
+	var v interface{}
+	if decodeJSON(data, &v) != nil {
+		if len(p.redactFields) > 0 || len(p.redactPaths) > 0 {
+			return e.Int(key+"_size", len(data)).Bool(key+"_redacted", true)
+		}
+		return p.addString(e, key, data)
+	}
+	return p.addValue(e, key, v)
+}
+
+func (p *Logger) addValue(e *zerolog.Event, key string, v interface{}) *zerolog.Event {
+	if len(p.redactFields) > 0 {
+		v = p.redactByName(v)
+	}
+	for _, redactPath := range p.redactPaths {
+		redactByPath(v, redactPath)
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return e.Str(key+"_error", err.Error())
+	}
+	if len(data) > p.maxSize {
+		return p.addString(e, key, data)
+	}
+	return e.RawJSON(key, data)
+}
+
+// addString adds data as string, truncated if exceeds max size
+func (p *Logger) addString(e *zerolog.Event, key string, data []byte) *zerolog.Event {
+	if len(data) > p.maxSize {
+		return e.Bytes(key, data[:p.maxSize]).Bool(key+"_truncated", true)
+	}
+	return e.Bytes(key, data)
+}
+
+func (p *Logger) redactByName(v interface{}) interface{} {
+	switch value := v.(type) {
+	case map[string]interface{}:
+		for k, item := range value {
+			if _, ok := p.redactFields[strings.ToLower(k)]; ok {
+				value[k] = RedactedValue
+			} else {
+				value[k] = p.redactByName(item)
+			}
+		}
+	case []interface{}:
+		for i, item := range value {
+			value[i] = p.redactByName(item)
+		}
+	}
+	return v
+}
+
+func redactByPath(v interface{}, redactPath []string) {
+	switch value := v.(type) {
+	case map[string]interface{}:
+		item, ok := value[redactPath[0]]
+		if !ok {
+			return
+		}
+		if len(redactPath) == 1 {
+			value[redactPath[0]] = RedactedValue
+		} else {
+			redactByPath(item, redactPath[1:])
+		}
+	case []interface{}:
+		for _, item := range value {
+			redactByPath(item, redactPath)
+		}
+	}
+}
+
+// redactProto redacts JSON value of proto message by "debug_redact" option of fields
+func redactProto(md protoreflect.MessageDescriptor, v interface{}) {
+	data, ok := v.(map[string]interface{})
+	if !ok {
+		return
+	}
+	fields := md.Fields()
+	for i := 0; i < fields.Len(); i++ {
+		fd := fields.Get(i)
+		name := string(fd.Name())
+		value, ok := data[name]
+		if !ok {
+			continue
+		}
+		opts, _ := fd.Options().(*descriptorpb.FieldOptions)
+		if opts.GetDebugRedact() {
+			data[name] = RedactedValue
+			continue
+		}
+		switch {
+		case fd.IsMap():
+			if fd.MapValue().Message() == nil {
+				continue
+			}
+			if items, ok := value.(map[string]interface{}); ok {
+				for _, item := range items {
+					redactProto(fd.MapValue().Message(), item)
+				}
+			}
+		case fd.Message() != nil:
+			if items, ok := value.([]interface{}); ok {
+				for _, item := range items {
+					redactProto(fd.Message(), item)
+				}
+			} else {
+				redactProto(fd.Message(), value)
+			}
+		}
+	}
+}
+
+func decodeJSON(data []byte, v interface{}) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	return decoder.Decode(v)
+}
@@ -0,0 +1,119 @@
+package payloadlog
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/rs/zerolog"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+// newTestMessage creates message "test.User{name, secret [debug_redact = true], friends}"
+func newTestMessage(t *testing.T) *dynamicpb.Message {
+	fdp := &descriptorpb.FileDescriptorProto{
+		Name:    proto.String("test_user.proto"),
+		Package: proto.String("test"),
+		Syntax:  proto.String("proto3"),
+		MessageType: []*descriptorpb.DescriptorProto{{
+			Name: proto.String("User"),
+			Field: []*descriptorpb.FieldDescriptorProto{
+				{Name: proto.String("name"), Number: proto.Int32(1), Type: descriptorpb.FieldDescriptorProto_TYPE_STRING.Enum(),
+					Label: descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum()},
+				{Name: proto.String("secret"), Number: proto.Int32(2), Type: descriptorpb.FieldDescriptorProto_TYPE_STRING.Enum(),
+					Label: descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(), Options: &descriptorpb.FieldOptions{DebugRedact: proto.Bool(true)}},
+				{Name: proto.String("friends"), Number: proto.Int32(3), Type: descriptorpb.FieldDescriptorProto_TYPE_MESSAGE.Enum(),
+					Label: descriptorpb.FieldDescriptorProto_LABEL_REPEATED.Enum(), TypeName: proto.String(".test.User")},
+			},
+		}},
+	}
+	fd, err := protodesc.NewFile(fdp, nil)
+	if err != nil {
+		t.Fatalf("new_file_descriptor_error: %v", err)
+	}
+	md := fd.Messages().ByName("User")
+	newUser := func(name string, secret string) *dynamicpb.Message {
+		m := dynamicpb.NewMessage(md)
+		m.Set(md.Fields().ByName("name"), protoreflect.ValueOfString(name))
+		m.Set(md.Fields().ByName("secret"), protoreflect.ValueOfString(secret))
+		return m
+	}
+	user := newUser("user1", "secret1")
+	friends := user.Mutable(md.Fields().ByName("friends")).List()
+	friends.Append(protoreflect.ValueOfMessage(newUser("user2", "secret2")))
+	return user
+}
+
+func logPayload(t *testing.T, add func(e *zerolog.Event) *zerolog.Event) map[string]interface{} {
+	var buf bytes.Buffer
+	logger := zerolog.New(&buf)
+	add(logger.Log()).Msg("test")
+	var record map[string]interface{}
+	err := json.Unmarshal(buf.Bytes(), &record)
+	if err != nil {
+		t.Fatalf("unmarshal_log_error: %v, %s", err, buf.String())
+	}
+	return record
+}
+
+func TestLoggerAddProto(t *testing.T) {
+	p, err := NewLogger(&Config{PayloadMethods: []string{"/test.User/*"}})
+	if err != nil {
+		t.Fatalf("new_logger_error: %v", err)
+	}
+	if !p.IsEnabled("/test.User/Get") || p.IsEnabled("/test.Order/Get") {
+		t.Errorf("unexpected_enabled_methods")
+	}
+	msg := newTestMessage(t)
+	record := logPayload(t, func(e *zerolog.Event) *zerolog.Event {
+		return p.AddProto(e, "request", msg)
+	})
+	request := record["request"].(map[string]interface{})
+	friend := request["friends"].([]interface{})[0].(map[string]interface{})
+	if request["name"] != "user1" || request["secret"] != RedactedValue ||
+		friend["name"] != "user2" || friend["secret"] != RedactedValue {
+		t.Errorf("unexpected_request_payload: %v", request)
+	}
+}
+
+func TestLoggerAddJSON(t *testing.T) {
+	p, err := NewLogger(&Config{
+		PayloadMethods: []string{"/v1/*"},
+		MaxPayloadSize: 150,
+		RedactFields:   []string{"Password"},
+		RedactPaths:    []string{"users.email", "token"},
+	})
+	if err != nil {
+		t.Fatalf("new_logger_error: %v", err)
+	}
+	data := []byte(`{"password":"p1","token":"t1","users":[{"email":"a@b.c","name":"n1","PASSWORD":"p2"}],"email":"x@y.z"}`)
+	record := logPayload(t, func(e *zerolog.Event) *zerolog.Event {
+		return p.AddJSON(e, "request", data)
+	})
+	request := record["request"].(map[string]interface{})
+	user := request["users"].([]interface{})[0].(map[string]interface{})
+	if request["password"] != RedactedValue || request["token"] != RedactedValue || request["email"] != "x@y.z" ||
+		user["email"] != RedactedValue || user["PASSWORD"] != RedactedValue || user["name"] != "n1" {
+		t.Errorf("unexpected_request_payload: %v", request)
+	}
+
+	large := []byte(`{"name":"` + string(bytes.Repeat([]byte("a"), 200)) + `","password":"p1"}`)
+	record = logPayload(t, func(e *zerolog.Event) *zerolog.Event {
+		return p.AddJSON(e, "request", large)
+	})
+	truncated, _ := record["request"].(string)
+	if len(truncated) != 150 || record["request_truncated"] != true {
+		t.Errorf("expect_truncated_payload: %v", record)
+	}
+
+	record = logPayload(t, func(e *zerolog.Event) *zerolog.Event {
+		return p.AddJSON(e, "request", large[:50])
+	})
+	if _, ok := record["request"]; ok || record["request_redacted"] != true {
+		t.Errorf("expect_unparsed_payload_withheld: %v", record)
+	}
+}
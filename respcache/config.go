@@ -0,0 +1,39 @@
+package respcache
+
+const (
+	// DefaultKeyPrefix is the prefix of all cache keys if not configured
+	DefaultKeyPrefix = "response_cache:"
+
+	// DefaultBypassHeader is the request header to skip cached response if not configured
+	DefaultBypassHeader = "x-cache-bypass"
+)
+
+// MethodConfig defines cache rule of methods
+type MethodConfig struct {
+	// Method is gRPC full method name (/package.Service/Method) or HTTP path of GET route.
+	// Glob pattern is supported, e.g. /package.Service/Get*
+	Method string `json:"method"`
+
+	// TTL is cache expiration in seconds
+	TTL int64 `json:"ttl"`
+}
+
+// Config is configuration of response cache
+type Config struct {
+	// Cache is name of cache client to store responses.
+	// Use local in-memory data cache if empty.
+	Cache string `json:"cache"`
+
+	// KeyPrefix is prefix of cache keys, default is DefaultKeyPrefix
+	KeyPrefix string `json:"key_prefix"`
+
+	// Headers are request headers included in cache key
+	Headers []string `json:"headers"`
+
+	// BypassHeader is request header to skip cached response, default is DefaultBypassHeader.
+	// Responses of bypassed requests are still stored into cache.
+	BypassHeader string `json:"bypass_header"`
+
+	// Methods are cache rules of methods, the first matched rule is applied
+	Methods []MethodConfig `json:"methods"`
+}
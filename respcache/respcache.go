@@ -0,0 +1,412 @@
+// Package respcache provides response caching for read-only gRPC methods and HTTP GET routes.
+package respcache
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"net/http"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+
+	"github.com/frame-go/framego/auth"
+	"github.com/frame-go/framego/client/cache"
+	"github.com/frame-go/framego/crypto"
+	"github.com/frame-go/framego/encoding/json"
+	"github.com/frame-go/framego/errors"
+	"github.com/frame-go/framego/grpcex"
+	"github.com/frame-go/framego/log"
+)
+
+const (
+	contextCacheKey   = "_response_cache"
+	cacheStatusHeader = "X-Cache"
+)
+
+type IContextGetter interface {
+	Value(key interface{}) interface{}
+}
+
+type rule struct {
+	pattern string
+	ttl     time.Duration
+}
+
+func (r *rule) match(method string) bool {
+	if r.pattern == method {
+		return true
+	}
+	ok, _ := path.Match(r.pattern, method)
+	return ok
+}
+
+// httpResponse is cached data of HTTP response
+type httpResponse struct {
+	Status int         `json:"status"`
+	Header http.Header `json:"header"`
+	Body   []byte      `json:"body"`
+}
+
+// grpcResponse is cached data of gRPC response
+type grpcResponse struct {
+	Header  metadata.MD `json:"header,omitempty"`
+	Trailer metadata.MD `json:"trailer,omitempty"`
+	Message []byte      `json:"message"` // binary of response message in anypb.Any
+}
+
+// Cache caches responses of matched methods.
+// Cache keys include the authenticated principal, so it should be placed after authentication middlewares.
+type Cache struct {
+	rules        []*rule
+	headers      []string
+	bypassHeader string
+	keyPrefix    string
+	store        store
+}
+
+// New creates response cache by config.
+// Responses are stored in client if it is not nil, otherwise in local memory until Close.
+func New(config *Config, client cache.Client) (*Cache, error) {
+	c := &Cache{
+		rules:        make([]*rule, 0, len(config.Methods)),
+		headers:      make([]string, 0, len(config.Headers)),
+		bypassHeader: strings.ToLower(config.BypassHeader),
+		keyPrefix:    config.KeyPrefix,
+	}
+	if c.bypassHeader == "" {
+		c.bypassHeader = DefaultBypassHeader
+	}
+	if c.keyPrefix == "" {
+		c.keyPrefix = DefaultKeyPrefix
+	}
+	for _, header := range config.Headers {
+		c.headers = append(c.headers, strings.ToLower(header))
+	}
+	sort.Strings(c.headers)
+	for _, methodConfig := range config.Methods {
+		if methodConfig.Method == "" {
+			return nil, errors.New("response_cache_empty_method")
+		}
+		if _, err := path.Match(methodConfig.Method, ""); err != nil {
+			return nil, errors.Wrap(err, "response_cache_invalid_method_pattern").With("method", methodConfig.Method)
+		}
+		if methodConfig.TTL <= 0 {
+			return nil, errors.New("response_cache_invalid_ttl").With("method", methodConfig.Method).
+				With("ttl", methodConfig.TTL)
+		}
+		c.rules = append(c.rules, &rule{
+			pattern: methodConfig.Method,
+			ttl:     time.Duration(methodConfig.TTL) * time.Second,
+		})
+	}
+	if client == nil {
+		c.store = newLocalStore(c.rules)
+	} else {
+		c.store = newRemoteStore(client, c.keyPrefix)
+	}
+	return c, nil
+}
+
+// Close stops removing expired responses from local memory, it is safe to call multiple times
+func (c *Cache) Close() {
+	c.store.close()
+}
+
+func (c *Cache) matchRule(method string) *rule {
+	for _, r := range c.rules {
+		if r.match(method) {
+			return r
+		}
+	}
+	return nil
+}
+
+// buildKey generates cache key from method, rule generation, payload, authenticated principal and selected header values,
+// so that cached responses are never shared between principals
+func (c *Cache) buildKey(ctx context.Context, r *rule, method string, payload []byte, getHeader func(string) string) string {
+	buf := bytes.NewBuffer(make([]byte, 0, len(payload)+64))
+	buf.Write(payload)
+	for _, subject := range auth.SubjectsFromContext(ctx) {
+		buf.WriteByte(0)
+		buf.WriteString(subject)
+	}
+	for _, header := range c.headers {
+		buf.WriteByte(0)
+		buf.WriteString(header)
+		buf.WriteByte('=')
+		buf.WriteString(getHeader(header))
+	}
+	gen := c.store.generation(ctx, r)
+	return c.keyPrefix + method + ":" + strconv.FormatInt(gen, 10) + ":" + hex.EncodeToString(crypto.Sha2Sum256(buf.Bytes()))
+}
+
+func (c *Cache) grpcKey(ctx context.Context, r *rule, method string, req interface{}) (string, error) {
+	msg, ok := req.(proto.Message)
+	if !ok {
+		return "", errors.New("response_cache_request_not_proto_message").With("method", method)
+	}
+	payload, err := proto.MarshalOptions{Deterministic: true}.Marshal(msg)
+	if err != nil {
+		return "", errors.Wrap(err, "response_cache_marshal_request_error").With("method", method)
+	}
+	return c.buildKey(ctx, r, method, payload, func(header string) string {
+		return grpcex.GetHeader(ctx, header)
+	}), nil
+}
+
+// Invalidate removes cached response of gRPC request.
+// Principal and header values in key are read from incoming context, the same as the cached request.
+func (c *Cache) Invalidate(ctx context.Context, method string, req proto.Message) error {
+	r := c.matchRule(method)
+	if r == nil {
+		return nil
+	}
+	key, err := c.grpcKey(ctx, r, method, req)
+	if err != nil {
+		return err
+	}
+	err = c.store.delete(ctx, r, key)
+	if err != nil {
+		return errors.Wrap(err, "response_cache_invalidate_error").With("method", method)
+	}
+	return nil
+}
+
+// InvalidateMethod removes all cached responses of the rule matching gRPC method or HTTP path.
+func (c *Cache) InvalidateMethod(ctx context.Context, method string) error {
+	r := c.matchRule(method)
+	if r == nil {
+		return nil
+	}
+	err := c.store.nextGeneration(ctx, r)
+	if err != nil {
+		return errors.Wrap(err, "response_cache_invalidate_method_error").With("method", method)
+	}
+	return nil
+}
+
+// UnaryServerInterceptor returns a unary server interceptor that caches responses of matched methods
+func (c *Cache) UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		ctx = SetContextCache(ctx, c)
+		r := c.matchRule(info.FullMethod)
+		if r == nil {
+			return handler(ctx, req)
+		}
+		logger := log.FromContext(ctx)
+		key, err := c.grpcKey(ctx, r, info.FullMethod, req)
+		if err != nil {
+			errors.LogError(logger.Warn(), err).Msg("response_cache_build_key_error")
+			return handler(ctx, req)
+		}
+		if grpcex.GetHeader(ctx, c.bypassHeader) == "" {
+			data, ok := c.store.get(ctx, r, key)
+			if ok {
+				resp, err := unmarshalGrpcResponse(data)
+				if err == nil {
+					// no transport stream in context if interceptor is called directly
+					if len(resp.Header) > 0 {
+						_ = grpc.SetHeader(ctx, resp.Header)
+					}
+					if len(resp.Trailer) > 0 {
+						_ = grpc.SetTrailer(ctx, resp.Trailer)
+					}
+					return resp.msg, nil
+				}
+				errors.LogError(logger.Warn(), err).Str("key", key).Msg("response_cache_unmarshal_response_error")
+			}
+		}
+		recorder := &headerRecorder{method: info.FullMethod, stream: grpc.ServerTransportStreamFromContext(ctx)}
+		resp, err := handler(grpc.NewContextWithServerTransportStream(ctx, recorder), req)
+		if err != nil {
+			return resp, err
+		}
+		data, marshalErr := marshalGrpcResponse(resp, recorder.header, recorder.trailer)
+		if marshalErr != nil {
+			errors.LogError(logger.Warn(), marshalErr).Str("key", key).Msg("response_cache_marshal_response_error")
+			return resp, err
+		}
+		c.store.set(ctx, r, key, data)
+		return resp, err
+	}
+}
+
+// headerRecorder records header and trailer set by handler, and passes them to the transport stream if exists
+type headerRecorder struct {
+	method  string
+	stream  grpc.ServerTransportStream
+	header  metadata.MD
+	trailer metadata.MD
+}
+
+func (r *headerRecorder) Method() string {
+	return r.method
+}
+
+func (r *headerRecorder) SetHeader(md metadata.MD) error {
+	r.header = metadata.Join(r.header, md)
+	if r.stream == nil {
+		return nil
+	}
+	return r.stream.SetHeader(md)
+}
+
+func (r *headerRecorder) SendHeader(md metadata.MD) error {
+	r.header = metadata.Join(r.header, md)
+	if r.stream == nil {
+		return nil
+	}
+	return r.stream.SendHeader(md)
+}
+
+func (r *headerRecorder) SetTrailer(md metadata.MD) error {
+	r.trailer = metadata.Join(r.trailer, md)
+	if r.stream == nil {
+		return nil
+	}
+	return r.stream.SetTrailer(md)
+}
+
+func marshalGrpcResponse(resp interface{}, header metadata.MD, trailer metadata.MD) ([]byte, error) {
+	msg, ok := resp.(proto.Message)
+	if !ok {
+		return nil, errors.New("response_not_proto_message")
+	}
+	anyResp, err := anypb.New(msg)
+	if err != nil {
+		return nil, err
+	}
+	data, err := proto.Marshal(anyResp)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(&grpcResponse{Header: header, Trailer: trailer, Message: data})
+}
+
+// cachedGrpcResponse is unmarshaled gRPC response with its header and trailer
+type cachedGrpcResponse struct {
+	grpcResponse
+	msg proto.Message
+}
+
+func unmarshalGrpcResponse(data []byte) (*cachedGrpcResponse, error) {
+	resp := &cachedGrpcResponse{}
+	err := json.Unmarshal(data, &resp.grpcResponse)
+	if err != nil {
+		return nil, err
+	}
+	anyResp := &anypb.Any{}
+	err = proto.Unmarshal(resp.Message, anyResp)
+	if err != nil {
+		return nil, err
+	}
+	resp.msg, err = anyResp.UnmarshalNew()
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+type responseBodyWriter struct {
+	gin.ResponseWriter
+	body *bytes.Buffer
+}
+
+func (w *responseBodyWriter) Write(b []byte) (int, error) {
+	w.body.Write(b)
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *responseBodyWriter) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
+
+// GinHandler returns a gin middleware that caches responses of matched GET routes.
+// Only responses with status 200 are cached.
+func (c *Cache) GinHandler() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		SetGinContextCache(ctx, c)
+		if ctx.Request.Method != http.MethodGet {
+			return
+		}
+		method := ctx.Request.URL.Path
+		r := c.matchRule(method)
+		if r == nil {
+			return
+		}
+		logger := log.FromContext(ctx)
+		query := ctx.Request.URL.Query().Encode() // encoded in sorted order by key
+		key := c.buildKey(ctx, r, method, []byte(query), ctx.GetHeader)
+		if ctx.GetHeader(c.bypassHeader) == "" {
+			data, ok := c.store.get(ctx, r, key)
+			if ok {
+				resp := &httpResponse{}
+				err := json.Unmarshal(data, resp)
+				if err == nil {
+					header := ctx.Writer.Header()
+					for k, v := range resp.Header {
+						header[k] = v
+					}
+					header.Set(cacheStatusHeader, "HIT")
+					ctx.Status(resp.Status)
+					_, _ = ctx.Writer.Write(resp.Body)
+					ctx.Abort()
+					return
+				}
+				errors.LogError(logger.Warn(), err).Str("key", key).Msg("response_cache_unmarshal_response_error")
+			}
+		}
+		writer := &responseBodyWriter{ResponseWriter: ctx.Writer, body: &bytes.Buffer{}}
+		ctx.Writer = writer
+		ctx.Writer.Header().Set(cacheStatusHeader, "MISS")
+		ctx.Next()
+		if writer.Status() != http.StatusOK {
+			return
+		}
+		header := writer.Header().Clone()
+		header.Del(cacheStatusHeader)
+		data, err := json.Marshal(&httpResponse{
+			Status: writer.Status(),
+			Header: header,
+			Body:   writer.body.Bytes(),
+		})
+		if err != nil {
+			errors.LogError(logger.Warn(), err).Str("key", key).Msg("response_cache_marshal_response_error")
+			return
+		}
+		c.store.set(ctx, r, key, data)
+	}
+}
+
+// FromContext gets response cache from context, returns nil if not exists
+func FromContext(ctx IContextGetter) *Cache {
+	v := ctx.Value(contextCacheKey)
+	if v == nil {
+		return nil
+	}
+	c, ok := v.(*Cache)
+	if !ok {
+		return nil
+	}
+	return c
+}
+
+// SetContextCache puts response cache into context
+func SetContextCache(ctx context.Context, c *Cache) context.Context {
+	return context.WithValue(ctx, contextCacheKey, c)
+}
+
+// SetGinContextCache puts response cache into gin context
+func SetGinContextCache(ctx *gin.Context, c *Cache) {
+	ctx.Set(contextCacheKey, c)
+}
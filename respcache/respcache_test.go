@@ -0,0 +1,322 @@
+package respcache
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+
+	"github.com/frame-go/framego/auth"
+	"github.com/frame-go/framego/client/cache"
+)
+
+const testMethod = "/test.Service/GetValue"
+
+func newTestCache(t *testing.T) *Cache {
+	c, err := New(&Config{
+		Headers: []string{"x-tenant"},
+		Methods: []MethodConfig{
+			{Method: "/test.Service/Get*", TTL: 60},
+		},
+	}, nil)
+	if err != nil {
+		t.Fatalf("new_response_cache_error: %v", err)
+	}
+	return c
+}
+
+func newTestHandler(counter *int) grpc.UnaryHandler {
+	return func(ctx context.Context, req interface{}) (interface{}, error) {
+		*counter++
+		return wrapperspb.String(req.(*wrapperspb.StringValue).GetValue()), nil
+	}
+}
+
+func callTest(t *testing.T, c *Cache, ctx context.Context, method string, value string, handler grpc.UnaryHandler) {
+	interceptor := c.UnaryServerInterceptor()
+	resp, err := interceptor(ctx, wrapperspb.String(value), &grpc.UnaryServerInfo{FullMethod: method}, handler)
+	if err != nil {
+		t.Fatalf("call_error: %v", err)
+	}
+	if resp.(*wrapperspb.StringValue).GetValue() != value {
+		t.Errorf("unexpected_response: %v", resp)
+	}
+}
+
+func TestUnaryServerInterceptor(t *testing.T) {
+	c := newTestCache(t)
+	ctx := context.Background()
+	counter := 0
+	handler := newTestHandler(&counter)
+
+	callTest(t, c, ctx, testMethod, "a", handler)
+	callTest(t, c, ctx, testMethod, "a", handler)
+	if counter != 1 {
+		t.Errorf("expect_cache_hit: counter=%d", counter)
+	}
+
+	callTest(t, c, ctx, testMethod, "b", handler)
+	if counter != 2 {
+		t.Errorf("expect_cache_miss_for_new_request: counter=%d", counter)
+	}
+
+	tenantCtx := metadata.NewIncomingContext(ctx, metadata.Pairs("x-tenant", "t1"))
+	callTest(t, c, tenantCtx, testMethod, "a", handler)
+	if counter != 3 {
+		t.Errorf("expect_cache_miss_for_new_header: counter=%d", counter)
+	}
+
+	bypassCtx := metadata.NewIncomingContext(ctx, metadata.Pairs(DefaultBypassHeader, "1"))
+	callTest(t, c, bypassCtx, testMethod, "a", handler)
+	if counter != 4 {
+		t.Errorf("expect_cache_bypass: counter=%d", counter)
+	}
+
+	callTest(t, c, ctx, "/test.Service/ListValues", "a", handler)
+	callTest(t, c, ctx, "/test.Service/ListValues", "a", handler)
+	if counter != 6 {
+		t.Errorf("expect_no_cache_for_unmatched_method: counter=%d", counter)
+	}
+
+	principalCtx := auth.SetContextPrincipal(ctx, &auth.Principal{Type: auth.PrincipalTypeAPIKey, Subject: "p1"})
+	callTest(t, c, principalCtx, testMethod, "a", handler)
+	if counter != 7 {
+		t.Errorf("expect_cache_miss_for_new_principal: counter=%d", counter)
+	}
+}
+
+// testStream is grpc.ServerTransportStream recording header and trailer
+type testStream struct {
+	header  metadata.MD
+	trailer metadata.MD
+}
+
+func (s *testStream) Method() string { return testMethod }
+
+func (s *testStream) SetHeader(md metadata.MD) error {
+	s.header = metadata.Join(s.header, md)
+	return nil
+}
+
+func (s *testStream) SendHeader(md metadata.MD) error { return s.SetHeader(md) }
+
+func (s *testStream) SetTrailer(md metadata.MD) error {
+	s.trailer = metadata.Join(s.trailer, md)
+	return nil
+}
+
+func TestUnaryServerInterceptorMetadata(t *testing.T) {
+	c := newTestCache(t)
+	counter := 0
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		_ = grpc.SetHeader(ctx, metadata.Pairs("x-version", "1"))
+		_ = grpc.SetTrailer(ctx, metadata.Pairs("x-total", "2"))
+		return newTestHandler(&counter)(ctx, req)
+	}
+	for i := 0; i < 2; i++ {
+		stream := &testStream{}
+		ctx := grpc.NewContextWithServerTransportStream(context.Background(), stream)
+		callTest(t, c, ctx, testMethod, "a", handler)
+		if !reflect.DeepEqual(stream.header, metadata.Pairs("x-version", "1")) {
+			t.Errorf("unexpected_header: call=%d header=%v", i, stream.header)
+		}
+		if !reflect.DeepEqual(stream.trailer, metadata.Pairs("x-total", "2")) {
+			t.Errorf("unexpected_trailer: call=%d trailer=%v", i, stream.trailer)
+		}
+	}
+	if counter != 1 {
+		t.Errorf("expect_cache_hit: counter=%d", counter)
+	}
+}
+
+func TestInvalidate(t *testing.T) {
+	c := newTestCache(t)
+	ctx := context.Background()
+	counter := 0
+	handler := newTestHandler(&counter)
+
+	callTest(t, c, ctx, testMethod, "a", handler)
+	callTest(t, c, ctx, testMethod, "b", handler)
+	err := c.Invalidate(ctx, testMethod, wrapperspb.String("a"))
+	if err != nil {
+		t.Fatalf("invalidate_error: %v", err)
+	}
+	callTest(t, c, ctx, testMethod, "a", handler)
+	callTest(t, c, ctx, testMethod, "b", handler)
+	if counter != 3 {
+		t.Errorf("expect_invalidated_request_only: counter=%d", counter)
+	}
+
+	err = c.InvalidateMethod(ctx, testMethod)
+	if err != nil {
+		t.Fatalf("invalidate_method_error: %v", err)
+	}
+	callTest(t, c, ctx, testMethod, "a", handler)
+	callTest(t, c, ctx, testMethod, "b", handler)
+	if counter != 5 {
+		t.Errorf("expect_invalidated_method: counter=%d", counter)
+	}
+}
+
+func TestNewWithInvalidConfig(t *testing.T) {
+	_, err := New(&Config{Methods: []MethodConfig{{Method: testMethod}}}, nil)
+	if err == nil {
+		t.Errorf("expect_invalid_ttl_error")
+	}
+	_, err = New(&Config{Methods: []MethodConfig{{Method: "/test.Service/[", TTL: 1}}}, nil)
+	if err == nil {
+		t.Errorf("expect_invalid_pattern_error")
+	}
+}
+
+func newTestRouter(t *testing.T, counter *int) *gin.Engine {
+	c, err := New(&Config{
+		Methods: []MethodConfig{
+			{Method: "/v1/values/*", TTL: 60},
+		},
+	}, nil)
+	if err != nil {
+		t.Fatalf("new_response_cache_error: %v", err)
+	}
+	router := gin.New()
+	router.Use(c.GinHandler())
+	router.GET("/v1/values/:id", func(ctx *gin.Context) {
+		*counter++
+		if ctx.Param("id") == "missing" {
+			ctx.String(http.StatusNotFound, "not found")
+			return
+		}
+		ctx.Header("X-Value", ctx.Param("id"))
+		ctx.String(http.StatusOK, "value "+ctx.Param("id")+" "+ctx.Query("q"))
+	})
+	return router
+}
+
+func getTest(t *testing.T, router *gin.Engine, target string, status int, cacheStatus string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, target, nil))
+	if w.Code != status {
+		t.Errorf("unexpected_status: %s %d", target, w.Code)
+	}
+	if w.Header().Get(cacheStatusHeader) != cacheStatus {
+		t.Errorf("unexpected_cache_status: %s %q", target, w.Header().Get(cacheStatusHeader))
+	}
+	return w
+}
+
+func TestGinHandler(t *testing.T) {
+	counter := 0
+	router := newTestRouter(t, &counter)
+
+	getTest(t, router, "/v1/values/a?q=1", http.StatusOK, "MISS")
+	w := getTest(t, router, "/v1/values/a?q=1", http.StatusOK, "HIT")
+	if counter != 1 {
+		t.Errorf("expect_cache_hit: counter=%d", counter)
+	}
+	if w.Body.String() != "value a 1" || w.Header().Get("X-Value") != "a" {
+		t.Errorf("unexpected_cached_response: %q %v", w.Body.String(), w.Header())
+	}
+
+	getTest(t, router, "/v1/values/a?q=2", http.StatusOK, "MISS")
+	if counter != 2 {
+		t.Errorf("expect_cache_miss_for_new_query: counter=%d", counter)
+	}
+
+	getTest(t, router, "/v1/values/missing", http.StatusNotFound, "MISS")
+	getTest(t, router, "/v1/values/missing", http.StatusNotFound, "MISS")
+	if counter != 4 {
+		t.Errorf("expect_no_cache_for_non_200: counter=%d", counter)
+	}
+}
+
+// countingClient is in-memory cache client counting calls of Get
+type countingClient struct {
+	cache.Client
+	data map[string]any
+	gets int
+}
+
+func (c *countingClient) Get(ctx context.Context, key string, value any) error {
+	c.gets++
+	v, ok := c.data[key]
+	if !ok {
+		return cache.Nil
+	}
+	reflect.ValueOf(value).Elem().Set(reflect.ValueOf(v))
+	return nil
+}
+
+func (c *countingClient) Set(ctx context.Context, key string, value any, expiration time.Duration) error {
+	c.data[key] = value
+	return nil
+}
+
+func (c *countingClient) IncrBy(ctx context.Context, key string, value int64) (int64, error) {
+	gen, _ := c.data[key].(int64)
+	gen += value
+	c.data[key] = gen
+	return gen, nil
+}
+
+func TestRemoteStoreGeneration(t *testing.T) {
+	client := &countingClient{data: make(map[string]any)}
+	c, err := New(&Config{Methods: []MethodConfig{{Method: "/test.Service/Get*", TTL: 60}}}, client)
+	if err != nil {
+		t.Fatalf("new_response_cache_error: %v", err)
+	}
+	ctx := context.Background()
+	counter := 0
+	handler := newTestHandler(&counter)
+
+	callTest(t, c, ctx, testMethod, "a", handler)
+	callTest(t, c, ctx, testMethod, "a", handler)
+	if counter != 1 {
+		t.Errorf("expect_cache_hit: counter=%d", counter)
+	}
+	// one get of generation and two gets of response
+	if client.gets != 3 {
+		t.Errorf("expect_cached_generation: gets=%d", client.gets)
+	}
+
+	err = c.InvalidateMethod(ctx, testMethod)
+	if err != nil {
+		t.Fatalf("invalidate_method_error: %v", err)
+	}
+	callTest(t, c, ctx, testMethod, "a", handler)
+	if counter != 2 {
+		t.Errorf("expect_invalidated_method: counter=%d", counter)
+	}
+}
+
+func TestLocalStore(t *testing.T) {
+	r := &rule{pattern: testMethod, ttl: time.Hour}
+	expired := &rule{pattern: "/test.Service/List", ttl: -time.Second}
+	s := newLocalStore([]*rule{r, expired})
+	defer s.close()
+	ctx := context.Background()
+
+	s.set(ctx, r, "k1", []byte("v1"))
+	s.set(ctx, expired, "k2", []byte("v2"))
+	if value, ok := s.get(ctx, r, "k1"); !ok || string(value) != "v1" {
+		t.Errorf("unexpected_value: %s, %v", value, ok)
+	}
+	if _, ok := s.get(ctx, expired, "k2"); ok {
+		t.Errorf("expect_expired_response_missing")
+	}
+	s.sweep()
+	if len(s.entries[r]) != 1 || len(s.entries[expired]) != 0 {
+		t.Errorf("unexpected_entries_after_sweep: %v", s.entries)
+	}
+	_ = s.delete(ctx, r, "k1")
+	if _, ok := s.get(ctx, r, "k1"); ok {
+		t.Errorf("expect_deleted_response_missing")
+	}
+	s.close()
+}
@@ -0,0 +1,219 @@
+package respcache
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/frame-go/framego/client/cache"
+	"github.com/frame-go/framego/errors"
+	"github.com/frame-go/framego/log"
+)
+
+// localSweepInterval is the interval to remove expired responses from local store
+const localSweepInterval = time.Minute
+
+// remoteGenerationCacheDuration is the duration to cache generation of rule read from cache client,
+// method invalidation by other instances takes effect after at most this duration
+const remoteGenerationCacheDuration = time.Second
+
+type store interface {
+	get(ctx context.Context, r *rule, key string) ([]byte, bool)
+	set(ctx context.Context, r *rule, key string, value []byte)
+	delete(ctx context.Context, r *rule, key string) error
+	generation(ctx context.Context, r *rule) int64
+	nextGeneration(ctx context.Context, r *rule) error
+	close()
+}
+
+// localEntry is cached response in local store
+type localEntry struct {
+	value    []byte
+	expireAt int64 // unix timestamp in nanoseconds
+}
+
+// localStore stores responses of each rule in memory, expired responses are removed periodically
+type localStore struct {
+	mu          sync.Mutex
+	entries     map[*rule]map[string]localEntry
+	generations map[*rule]*int64
+	stop        chan struct{}
+	stopOnce    sync.Once
+}
+
+func newLocalStore(rules []*rule) *localStore {
+	s := &localStore{
+		entries:     make(map[*rule]map[string]localEntry),
+		generations: make(map[*rule]*int64),
+		stop:        make(chan struct{}),
+	}
+	for _, r := range rules {
+		s.entries[r] = make(map[string]localEntry)
+		s.generations[r] = new(int64)
+	}
+	go s.sweepLoop()
+	return s
+}
+
+func (s *localStore) sweepLoop() {
+	ticker := time.NewTicker(localSweepInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-s.stop:
+			return
+		case <-ticker.C:
+			s.sweep()
+		}
+	}
+}
+
+// sweep removes expired responses
+func (s *localStore) sweep() {
+	now := time.Now().UnixNano()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, entries := range s.entries {
+		for key, entry := range entries {
+			if now > entry.expireAt {
+				delete(entries, key)
+			}
+		}
+	}
+}
+
+func (s *localStore) get(ctx context.Context, r *rule, key string) ([]byte, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	entry, ok := s.entries[r][key]
+	if !ok || time.Now().UnixNano() > entry.expireAt {
+		return nil, false
+	}
+	return entry.value, true
+}
+
+func (s *localStore) set(ctx context.Context, r *rule, key string, value []byte) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.entries[r][key] = localEntry{
+		value:    value,
+		expireAt: time.Now().Add(r.ttl).UnixNano(),
+	}
+}
+
+func (s *localStore) delete(ctx context.Context, r *rule, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.entries[r], key)
+	return nil
+}
+
+func (s *localStore) generation(ctx context.Context, r *rule) int64 {
+	return atomic.LoadInt64(s.generations[r])
+}
+
+func (s *localStore) nextGeneration(ctx context.Context, r *rule) error {
+	atomic.AddInt64(s.generations[r], 1)
+	return nil
+}
+
+// close stops removing expired responses
+func (s *localStore) close() {
+	s.stopOnce.Do(func() {
+		close(s.stop)
+	})
+}
+
+// remoteGeneration is generation of rule cached in local
+type remoteGeneration struct {
+	value    int64
+	expireAt int64 // unix timestamp in nanoseconds
+}
+
+// remoteStore stores responses in cache client shared by all instances
+type remoteStore struct {
+	client    cache.Client
+	keyPrefix string
+
+	generationLock sync.Mutex
+	generations    map[*rule]remoteGeneration
+}
+
+func newRemoteStore(client cache.Client, keyPrefix string) *remoteStore {
+	return &remoteStore{
+		client:      client,
+		keyPrefix:   keyPrefix,
+		generations: make(map[*rule]remoteGeneration),
+	}
+}
+
+func (s *remoteStore) get(ctx context.Context, r *rule, key string) ([]byte, bool) {
+	var value []byte
+	err := s.client.Get(ctx, key, &value)
+	if err != nil {
+		if !errors.Is(err, cache.Nil) {
+			errors.LogError(log.FromContext(ctx).Warn(), err).Str("key", key).Msg("response_cache_get_error")
+		}
+		return nil, false
+	}
+	return value, true
+}
+
+func (s *remoteStore) set(ctx context.Context, r *rule, key string, value []byte) {
+	err := s.client.Set(ctx, key, value, r.ttl)
+	if err != nil {
+		errors.LogError(log.FromContext(ctx).Warn(), err).Str("key", key).Msg("response_cache_set_error")
+	}
+}
+
+func (s *remoteStore) delete(ctx context.Context, r *rule, key string) error {
+	_, err := s.client.Delete(ctx, key)
+	return err
+}
+
+func (s *remoteStore) generationKey(r *rule) string {
+	return s.keyPrefix + "generation:" + r.pattern
+}
+
+// generation gets generation of rule, which is cached in local briefly to save a round trip for each request
+func (s *remoteStore) generation(ctx context.Context, r *rule) int64 {
+	now := time.Now().UnixNano()
+	s.generationLock.Lock()
+	cached, ok := s.generations[r]
+	s.generationLock.Unlock()
+	if ok && now < cached.expireAt {
+		return cached.value
+	}
+	var gen int64
+	key := s.generationKey(r)
+	err := s.client.Get(ctx, key, &gen)
+	if err != nil && !errors.Is(err, cache.Nil) {
+		errors.LogError(log.FromContext(ctx).Warn(), err).Str("key", key).Msg("response_cache_get_generation_error")
+		return cached.value
+	}
+	s.setGeneration(r, gen)
+	return gen
+}
+
+func (s *remoteStore) setGeneration(r *rule, gen int64) {
+	s.generationLock.Lock()
+	defer s.generationLock.Unlock()
+	s.generations[r] = remoteGeneration{
+		value:    gen,
+		expireAt: time.Now().Add(remoteGenerationCacheDuration).UnixNano(),
+	}
+}
+
+func (s *remoteStore) nextGeneration(ctx context.Context, r *rule) error {
+	gen, err := s.client.IncrBy(ctx, s.generationKey(r), 1)
+	if err != nil {
+		return err
+	}
+	// invalidation takes effect immediately in this instance
+	s.setGeneration(r, gen)
+	return nil
+}
+
+func (s *remoteStore) close() {
+}
@@ -0,0 +1,91 @@
+// Package validation provides validation of proto messages by protoc-gen-validate, and the "invalid_argument" error
+// with field violations shared by gRPC and HTTP.
+package validation
+
+import (
+	"google.golang.org/grpc/codes"
+
+	"github.com/frame-go/framego/errors"
+)
+
+// The validate interface starting with protoc-gen-validate v0.6.0.
+// See https://github.com/envoyproxy/protoc-gen-validate/pull/455.
+type validator interface {
+	Validate(all bool) error
+}
+
+// The validate interface prior to protoc-gen-validate v0.6.0.
+type validatorLegacy interface {
+	Validate() error
+}
+
+// fieldValidationError is the field error generated by protoc-gen-validate
+type fieldValidationError interface {
+	error
+	Field() string
+	Reason() string
+	Cause() error
+}
+
+// multiValidationError is the error of all violations generated by protoc-gen-validate
+type multiValidationError interface {
+	error
+	AllErrors() []error
+}
+
+// Validate validates message by protoc-gen-validate, returns nil if message has no validation rules
+func Validate(req interface{}) error {
+	var err error
+	switch v := req.(type) {
+	case validatorLegacy:
+		err = v.Validate()
+	case validator:
+		err = v.Validate(false)
+	}
+	if err != nil {
+		return NewError(err, Violations(err))
+	}
+	return nil
+}
+
+// NewError creates "invalid_argument" error with field violations in detail
+func NewError(cause error, violations []interface{}) *errors.Error {
+	err := errors.Wrap(cause, "invalid_argument").WithGRPCCode(codes.InvalidArgument)
+	if len(violations) > 0 {
+		err = err.With("violations", violations)
+	}
+	return err
+}
+
+// Violations converts protoc-gen-validate error to field violations,
+// each violation is a map of "field" and "reason"
+func Violations(err error) []interface{} {
+	var violations []interface{}
+	if multiErr, ok := err.(multiValidationError); ok {
+		for _, e := range multiErr.AllErrors() {
+			violations = append(violations, Violations(e)...)
+		}
+		return violations
+	}
+	fieldErr, ok := err.(fieldValidationError)
+	if !ok {
+		return nil
+	}
+	field := fieldErr.Field()
+	reason := fieldErr.Reason()
+	// embedded message errors are nested in cause
+	for {
+		causeErr, ok := fieldErr.Cause().(fieldValidationError)
+		if !ok {
+			break
+		}
+		fieldErr = causeErr
+		field += "." + fieldErr.Field()
+		reason = fieldErr.Reason()
+	}
+	violation := map[string]interface{}{
+		"field":  field,
+		"reason": reason,
+	}
+	return append(violations, violation)
+}
@@ -0,0 +1,58 @@
+package validation
+
+import (
+	"testing"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type testFieldError struct {
+	field  string
+	reason string
+	cause  error
+}
+
+func (e testFieldError) Error() string  { return e.field + ": " + e.reason }
+func (e testFieldError) Field() string  { return e.field }
+func (e testFieldError) Reason() string { return e.reason }
+func (e testFieldError) Cause() error   { return e.cause }
+
+type testMultiError []error
+
+func (e testMultiError) Error() string      { return "multiple errors" }
+func (e testMultiError) AllErrors() []error { return e }
+
+type testMessage struct {
+	err error
+}
+
+func (m *testMessage) Validate(all bool) error { return m.err }
+
+func TestValidate(t *testing.T) {
+	if err := Validate(&testMessage{}); err != nil {
+		t.Errorf("unexpected error %v", err)
+	}
+	if err := Validate(struct{}{}); err != nil {
+		t.Errorf("unexpected error %v", err)
+	}
+
+	msg := &testMessage{err: testMultiError{
+		testFieldError{field: "Name", reason: "value is required"},
+		testFieldError{field: "Address", reason: "embedded message failed validation",
+			cause: testFieldError{field: "City", reason: "value length must be at least 1 runes"}},
+	}}
+	err := Validate(msg)
+	if status.Code(err) != codes.InvalidArgument {
+		t.Fatalf("unexpected code of error %v", err)
+	}
+	e, _ := err.(interface{ Detail() map[string]interface{} })
+	violations, _ := e.Detail()["violations"].([]interface{})
+	if len(violations) != 2 {
+		t.Fatalf("unexpected violations %v", violations)
+	}
+	nested := violations[1].(map[string]interface{})
+	if nested["field"] != "Address.City" || nested["reason"] != "value length must be at least 1 runes" {
+		t.Errorf("unexpected nested violation %v", nested)
+	}
+}